@@ -7,6 +7,8 @@ import (
         "net/http"
         "os"
         "os/signal"
+        "strconv"
+        "strings"
         "syscall"
         "time"
 
@@ -23,6 +25,9 @@ import (
 const (
         defaultPort          = "5001"
         defaultStolotoAPIURL = "http://localhost:8080"
+        defaultSnapshotPath  = "data/lottery-snapshot.json"
+        defaultDrawArchive   = "data/draws"
+        defaultWalletDir     = "data/wallets"
         shutdownTimeout      = 10 * time.Second
 )
 
@@ -37,15 +42,111 @@ func main() {
         }
         log.Printf("Using Stoloto API URL: %s", stolotoAPIBaseURL)
 
+        // Ограничение исходящих запросов к StolotoAPI (документация рекомендует не больше 60 в минуту)
+        rateLimit := repository.DefaultRateLimitConfig()
+        rateLimit.RequestsPerMinute = envInt("STOLOTO_RATE_LIMIT", rateLimit.RequestsPerMinute)
+        rateLimit.Burst = envInt("STOLOTO_RATE_BURST", rateLimit.Burst)
+        rateLimit.MaxWait = envDuration("STOLOTO_RATE_MAX_WAIT", rateLimit.MaxWait)
+        log.Printf("Stoloto API rate limit: %d req/min (burst %d, max wait %v)", rateLimit.RequestsPerMinute, rateLimit.Burst, rateLimit.MaxWait)
+
+        // Каталог игр: тип, расписание, тексты, правила и призы (встроенный или из файла)
+        gameCatalog, err := repository.LoadGameCatalog(os.Getenv("GAME_CATALOG_PATH"))
+        if err != nil {
+                log.Printf("Failed to load game catalog, using embedded one: %v", err)
+                gameCatalog = repository.DefaultGameCatalog()
+        }
+        log.Printf("Game catalog: %d games", len(gameCatalog.Games))
+
         // Инициализация HTTP клиента для StolotoAPI
-        stolotoClient := repository.NewStolotoClient(stolotoAPIBaseURL)
+        stolotoClient := repository.NewStolotoClient(stolotoAPIBaseURL,
+                repository.WithRateLimit(rateLimit),
+                repository.WithGameCatalog(gameCatalog),
+        )
+
+        // Источники каталога лотерей: StolotoAPI и (опционально) статический файл каталога
+        providers := service.DefaultProviders(stolotoClient)
+        if catalogPath := os.Getenv("LOTTERY_CATALOG_PATH"); catalogPath != "" {
+                log.Printf("Using static lottery catalog: %s", catalogPath)
+                providers = append(providers, service.NewStaticCatalogProvider(catalogPath))
+        }
+
+        // Каталог лотерей кэшируется: устаревший отдается сразу и обновляется в фоне
+        catalogTTL := envDuration("LOTTERY_CACHE_TTL", 5*time.Minute)
+        log.Printf("Lottery catalog cache TTL: %v", catalogTTL)
+
+        // Снимок последнего успешного каталога - резерв на случай недоступности StolotoAPI при запуске
+        snapshotPath := os.Getenv("LOTTERY_SNAPSHOT_PATH")
+        if snapshotPath == "" {
+                snapshotPath = defaultSnapshotPath
+        }
+        log.Printf("Lottery catalog snapshot: %s", snapshotPath)
 
         // Инициализация сервисов
-        stolotoService := service.NewStolotoService(stolotoClient)
+        stolotoService := service.NewStolotoService(
+                stolotoClient,
+                service.WithProviders(providers...),
+                service.WithCatalogTTL(catalogTTL),
+                service.WithSnapshotStore(repository.NewSnapshotStore(snapshotPath)),
+                service.WithGameCatalog(gameCatalog),
+        )
         recommendationService := service.NewRecommendationService()
 
+        // Архив результатов тиражей и его фоновое заполнение
+        backfillCtx, stopBackfill := context.WithCancel(context.Background())
+        defer stopBackfill()
+
+        drawArchiveDir := os.Getenv("DRAW_ARCHIVE_DIR")
+        if drawArchiveDir == "" {
+                drawArchiveDir = defaultDrawArchive
+        }
+        var drawArchive repository.DrawArchive
+        fileArchive, err := repository.NewFileDrawArchive(drawArchiveDir)
+        if err != nil {
+                log.Printf("Draw archive disabled: %v", err)
+        } else {
+                log.Printf("Draw archive: %s", drawArchiveDir)
+                drawArchive = fileArchive
+
+                backfill := service.DefaultBackfillConfig()
+                backfill.MaxDepth = envInt("DRAW_BACKFILL_DEPTH", backfill.MaxDepth)
+                backfill.Pause = envDuration("DRAW_BACKFILL_PAUSE", backfill.Pause)
+                backfill.Interval = envDuration("DRAW_BACKFILL_INTERVAL", backfill.Interval)
+                for _, game := range strings.Split(os.Getenv("DRAW_BACKFILL_GAMES"), ",") {
+                        if game = strings.TrimSpace(game); game != "" {
+                                backfill.Games = append(backfill.Games, game)
+                        }
+                }
+
+                if backfill.MaxDepth > 0 {
+                        log.Printf("Draw backfill: depth %d, pause %v, interval %v", backfill.MaxDepth, backfill.Pause, backfill.Interval)
+                        go service.NewBackfillCrawler(stolotoClient, drawArchive, backfill).Run(backfillCtx)
+                } else {
+                        log.Printf("Draw backfill disabled")
+                }
+        }
+
         // Инициализация HTTP handlers
-        handler := apphttp.NewHandler(stolotoService, recommendationService, validate)
+        drawService := service.NewDrawService(stolotoService, stolotoClient, drawArchive)
+        statsService := service.NewStatsService(stolotoService, drawArchive)
+
+        // Кошельки билетов пользователей и их фоновая проверка по результатам тиражей
+        walletDir := os.Getenv("WALLET_DIR")
+        if walletDir == "" {
+                walletDir = defaultWalletDir
+        }
+        walletStore, err := repository.NewFileWalletStore(walletDir)
+        if err != nil {
+                log.Fatalf("Failed to open wallet store: %v", err)
+        }
+        walletService := service.NewWalletService(walletStore, drawService)
+        walletCheckInterval := envDuration("WALLET_CHECK_INTERVAL", service.DefaultWalletCheckInterval)
+        log.Printf("Wallets: %s, check interval %v", walletDir, walletCheckInterval)
+        go walletService.Run(backfillCtx, walletCheckInterval)
+
+        // Моделирование планов игры: порции испытаний считаются на ограниченном числе воркеров
+        simulationService := service.NewSimulationService(stolotoService, envInt("SIMULATION_WORKERS", 0))
+
+        handler := apphttp.NewHandler(stolotoService, recommendationService, drawService, statsService, walletService, simulationService, validate)
 
         // Создание роутера
         r := chi.NewRouter()
@@ -61,8 +162,8 @@ func main() {
         r.Use(cors.Handler(cors.Options{
                 AllowedOrigins:   []string{"http://localhost:5000", "http://localhost:5001"},
                 AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-                AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-                ExposedHeaders:   []string{"Link"},
+                AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-User-ID"},
+                ExposedHeaders:   []string{"Link", "X-Data-Source", "X-Data-Fetched-At"},
                 AllowCredentials: true,
                 MaxAge:           300,
         }))
@@ -99,6 +200,7 @@ func main() {
         <-quit
 
         log.Println("🛑 Остановка сервера...")
+        stopBackfill()
 
         ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
         defer cancel()
@@ -109,3 +211,31 @@ func main() {
 
         log.Println("✅ Сервер остановлен корректно")
 }
+
+// envInt читает целое число из переменной окружения или возвращает значение по умолчанию
+func envInt(key string, fallback int) int {
+        value := os.Getenv(key)
+        if value == "" {
+                return fallback
+        }
+        parsed, err := strconv.Atoi(value)
+        if err != nil {
+                log.Printf("Некорректное значение %s=%q, используется %d", key, value, fallback)
+                return fallback
+        }
+        return parsed
+}
+
+// envDuration читает длительность ("2s", "500ms") из переменной окружения или возвращает значение по умолчанию
+func envDuration(key string, fallback time.Duration) time.Duration {
+        value := os.Getenv(key)
+        if value == "" {
+                return fallback
+        }
+        parsed, err := time.ParseDuration(value)
+        if err != nil {
+                log.Printf("Некорректное значение %s=%q, используется %v", key, value, fallback)
+                return fallback
+        }
+        return parsed
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "math"
+
+const (
+        // PrizeFundRate - доля выручки тиража, направляемая в призовой фонд (по закону о лотереях - не менее 50%)
+        PrizeFundRate = 0.5
+        // WinningsTaxRate - НДФЛ с выигрыша в лотерею
+        WinningsTaxRate = 0.13
+        // WinningsTaxFree - необлагаемая часть выигрыша в рублях
+        WinningsTaxFree = 4000.0
+)
+
+// ExpectedValue представляет ожидаемый выигрыш с одного билета
+type ExpectedValue struct {
+        Gross       float64 `json:"gross"`       // Ожидаемый выигрыш до НДФЛ в рублях
+        Net         float64 `json:"net"`         // Ожидаемый выигрыш после НДФЛ в рублях
+        GrossReturn float64 `json:"grossReturn"` // Gross на рубль цены билета
+        NetReturn   float64 `json:"netReturn"`   // Net на рубль цены билета: сколько в среднем возвращается с рубля
+}
+
+// AveragePrize возвращает средний приз на один выигрышный билет категории с вероятностью odds
+// Для доли призового фонда: фонд категории (Share * PrizeFundRate * цена билета на каждый проданный билет)
+// делится между победителями, которых в среднем odds на билет
+func (p Payout) AveragePrize(ticketPrice float64, odds Odds) float64 {
+        switch p.Kind {
+        case PayoutFixed, PayoutJackpot:
+                return p.Amount
+        case PayoutPoolShare:
+                probability := odds.Float64()
+                if probability <= 0 {
+                        return 0
+                }
+                return p.Share * PrizeFundRate * ticketPrice / probability
+        default:
+                return 0
+        }
+}
+
+// NetPrize возвращает выигрыш за вычетом НДФЛ
+func NetPrize(prize float64) float64 {
+        return prize - WinningsTaxRate*math.Max(0, prize-WinningsTaxFree)
+}
+
+// ComputeExpectedValue считает ожидаемый выигрыш билета по структуре призов
+// Учитываются категории с известными вероятностью и призом; если таких нет, возвращает nil
+func ComputeExpectedValue(ticketPrice float64, prizeStructure []PrizeCategory) *ExpectedValue {
+        if ticketPrice <= 0 {
+                return nil
+        }
+
+        var value ExpectedValue
+        known := 0
+        for _, category := range prizeStructure {
+                if category.Odds == nil || category.Payout == nil {
+                        continue
+                }
+                known++
+
+                probability := category.Odds.Float64()
+                prize := category.Payout.AveragePrize(ticketPrice, *category.Odds)
+                value.Gross += probability * prize
+                value.Net += probability * NetPrize(prize)
+        }
+        if known == 0 {
+                return nil
+        }
+
+        value.GrossReturn = value.Gross / ticketPrice
+        value.NetReturn = value.Net / ticketPrice
+        return &value
+}
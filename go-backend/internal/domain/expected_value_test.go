@@ -0,0 +1,41 @@
+package domain
+
+import (
+        "math"
+        "testing"
+)
+
+// TestComputeExpectedValue проверяет расчет ожидаемого выигрыша по категориям
+func TestComputeExpectedValue(t *testing.T) {
+        prizeStructure := []PrizeCategory{
+                // Джекпот 1 000 000 ₽ с вероятностью 1/100 000: 10 ₽ до налога
+                {Odds: &Odds{Numerator: 1, Denominator: 100000}, Payout: &Payout{Kind: PayoutJackpot, Amount: 1000000}},
+                // 10% призового фонда (50 ₽ с билета по 100 ₽) на категорию 1/1000: средний приз 5000 ₽
+                {Odds: &Odds{Numerator: 1, Denominator: 1000}, Payout: &Payout{Kind: PayoutPoolShare, Share: 0.1}},
+                // 100 ₽ с вероятностью 1/10: 10 ₽, налогом не облагается
+                {Odds: &Odds{Numerator: 1, Denominator: 10}, Payout: &Payout{Kind: PayoutFixed, Amount: 100}},
+                // Категория без вероятности не учитывается
+                {Payout: &Payout{Kind: PayoutFixed, Amount: 1000000}},
+        }
+
+        value := ComputeExpectedValue(100, prizeStructure)
+        if value == nil {
+                t.Fatal("Ожидается рассчитанный EV")
+        }
+
+        gross := 10.0 + 5.0 + 10.0
+        net := (1000000-0.13*996000)/100000 + (5000-0.13*1000)/1000 + 10.0
+        if math.Abs(value.Gross-gross) > 1e-9 || math.Abs(value.Net-net) > 1e-9 {
+                t.Errorf("Ожидается gross %v, net %v, получено %v, %v", gross, net, value.Gross, value.Net)
+        }
+        if math.Abs(value.GrossReturn-gross/100) > 1e-9 || math.Abs(value.NetReturn-net/100) > 1e-9 {
+                t.Errorf("Некорректный возврат с рубля: %+v", value)
+        }
+
+        if ComputeExpectedValue(100, []PrizeCategory{{Category: "Джекпот"}}) != nil {
+                t.Error("Без вероятностей EV не рассчитывается")
+        }
+        if ComputeExpectedValue(0, prizeStructure) != nil {
+                t.Error("Без цены билета EV не рассчитывается")
+        }
+}
@@ -0,0 +1,328 @@
+package domain
+
+import (
+        "fmt"
+        "math"
+        "math/big"
+        "strings"
+)
+
+// AnyMatches в условии категории означает "любое количество совпадений в поле"
+const AnyMatches = -1
+
+// NumberField представляет игровое поле: игрок выбирает Pick чисел из Of, выпадает Drawn чисел
+type NumberField struct {
+        Pick  int `json:"pick"`            // Сколько чисел выбирает игрок
+        Of    int `json:"of"`              // Сколько чисел в поле
+        Drawn int `json:"drawn,omitempty"` // Сколько чисел выпадает в тираже (0 - столько же, сколько выбирает игрок)
+}
+
+// drawn возвращает количество выпадающих чисел
+func (f NumberField) drawn() int {
+        if f.Drawn > 0 {
+                return f.Drawn
+        }
+        return f.Pick
+}
+
+// MatchTier представляет выигрышную категорию как набор условий на совпадения по полям
+// Например, для "5 из 36 + 1 из 4": "5+1" - [[5, 1]], "4" - [[4, -1]];
+// для "4 из 20, два поля": "4+3" - [[4, 3], [3, 4]]
+type MatchTier struct {
+        Name    string  `json:"name"`          // Название категории ("6 из 6", "5+1")
+        Key     string  `json:"key,omitempty"` // Ключ категории в победителях тиража StolotoAPI ("6", "5+1")
+        Matches [][]int `json:"matches"`       // Варианты совпадений по полям; AnyMatches - любое количество
+}
+
+// GameRules описывает правила числовой лотереи: игровые поля и выигрышные категории
+type GameRules struct {
+        Fields []NumberField `json:"fields"` // Игровые поля
+        Tiers  []MatchTier   `json:"tiers"`  // Выигрышные категории, от главной к младшей
+}
+
+// String форматирует правила: "6 из 45", "5 из 36 + 1 из 4"
+func (r GameRules) String() string {
+        parts := make([]string, 0, len(r.Fields))
+        for _, field := range r.Fields {
+                parts = append(parts, fmt.Sprintf("%d из %d", field.Pick, field.Of))
+        }
+        return strings.Join(parts, " + ")
+}
+
+// Validate проверяет корректность правил
+func (r GameRules) Validate() error {
+        if len(r.Fields) == 0 {
+                return fmt.Errorf("нет игровых полей")
+        }
+        for i, field := range r.Fields {
+                if field.Of <= 0 || field.Pick <= 0 || field.Pick > field.Of || field.drawn() > field.Of {
+                        return fmt.Errorf("поле %d: некорректные параметры %d из %d (выпадает %d)", i+1, field.Pick, field.Of, field.drawn())
+                }
+        }
+        for _, tier := range r.Tiers {
+                if len(tier.Matches) == 0 {
+                        return fmt.Errorf("категория %q: нет условий", tier.Name)
+                }
+                for _, condition := range tier.Matches {
+                        if len(condition) != len(r.Fields) {
+                                return fmt.Errorf("категория %q: условие %v не совпадает с числом полей", tier.Name, condition)
+                        }
+                        for i, matches := range condition {
+                                if matches != AnyMatches && (matches < 0 || matches > r.Fields[i].Pick) {
+                                        return fmt.Errorf("категория %q: недопустимое число совпадений %d в поле %d", tier.Name, matches, i+1)
+                                }
+                        }
+                }
+        }
+        return nil
+}
+
+// TierOdds возвращает точную вероятность каждой категории (в порядке Tiers)
+// Каждый исход (вектор совпадений по полям) относится к первой подходящей категории,
+// поэтому категории не пересекаются и их вероятности можно складывать
+func (r GameRules) TierOdds() []*big.Rat {
+        odds := make([]*big.Rat, len(r.Tiers))
+        for i := range odds {
+                odds[i] = new(big.Rat)
+        }
+
+        fieldOdds := make([][]*big.Rat, len(r.Fields))
+        for i, field := range r.Fields {
+                fieldOdds[i] = make([]*big.Rat, field.Pick+1)
+                for k := 0; k <= field.Pick; k++ {
+                        fieldOdds[i][k] = field.MatchProbability(k)
+                }
+        }
+
+        outcome := make([]int, len(r.Fields))
+        var visit func(field int, probability *big.Rat)
+        visit = func(field int, probability *big.Rat) {
+                if probability.Sign() == 0 {
+                        return
+                }
+                if field == len(r.Fields) {
+                        if tier := r.tierFor(outcome); tier >= 0 {
+                                odds[tier].Add(odds[tier], probability)
+                        }
+                        return
+                }
+                for k := 0; k <= r.Fields[field].Pick; k++ {
+                        outcome[field] = k
+                        visit(field+1, new(big.Rat).Mul(probability, fieldOdds[field][k]))
+                }
+        }
+        visit(0, big.NewRat(1, 1))
+
+        return odds
+}
+
+// WinOdds возвращает точную вероятность выиграть в любой категории
+func (r GameRules) WinOdds() *big.Rat {
+        total := new(big.Rat)
+        for _, odds := range r.TierOdds() {
+                total.Add(total, odds)
+        }
+        return total
+}
+
+// SingleWinner проверяет, что в каждом тираже категорию tier выигрывает ровно одна комбинация:
+// условие одно и требует угадать все выпавшие числа каждого поля (например, "6 из 6", "5+1")
+// Тогда события "комбинация выиграла" для разных комбинаций одного тиража несовместны
+func (r GameRules) SingleWinner(tier int) bool {
+        if tier < 0 || tier >= len(r.Tiers) || len(r.Tiers[tier].Matches) != 1 {
+                return false
+        }
+        for i, matches := range r.Tiers[tier].Matches[0] {
+                field := r.Fields[i]
+                if field.drawn() != field.Pick || matches != field.Pick {
+                        return false
+                }
+        }
+        return true
+}
+
+// tierFor возвращает индекс первой категории, условию которой удовлетворяет исход (-1 - без выигрыша)
+func (r GameRules) tierFor(outcome []int) int {
+        for i, tier := range r.Tiers {
+                for _, condition := range tier.Matches {
+                        if conditionMatches(condition, outcome) {
+                                return i
+                        }
+                }
+        }
+        return -1
+}
+
+// conditionMatches проверяет исход на соответствие условию категории
+func conditionMatches(condition, outcome []int) bool {
+        for i, matches := range condition {
+                if matches != AnyMatches && matches != outcome[i] {
+                        return false
+                }
+        }
+        return true
+}
+
+// MatchProbability возвращает точную (гипергеометрическую) вероятность угадать ровно k чисел в поле:
+// C(drawn, k) * C(of - drawn, pick - k) / C(of, pick)
+func (f NumberField) MatchProbability(k int) *big.Rat {
+        drawn := f.drawn()
+        if k < 0 || k > f.Pick || k > drawn || f.Pick-k > f.Of-drawn {
+                return new(big.Rat)
+        }
+
+        numerator := new(big.Int).Mul(binomial(drawn, k), binomial(f.Of-drawn, f.Pick-k))
+        return new(big.Rat).SetFrac(numerator, binomial(f.Of, f.Pick))
+}
+
+// Combinations возвращает количество разных комбинаций билета: произведение C(Of, Pick) по полям
+func (r GameRules) Combinations() *big.Int {
+        total := big.NewInt(1)
+        for _, field := range r.Fields {
+                total.Mul(total, binomial(field.Of, field.Pick))
+        }
+        return total
+}
+
+// binomial возвращает биномиальный коэффициент C(n, k)
+func binomial(n, k int) *big.Int {
+        if k < 0 || k > n {
+                return new(big.Int)
+        }
+        return new(big.Int).Binomial(int64(n), int64(k))
+}
+
+// Odds представляет вероятность в виде несократимой дроби Numerator/Denominator
+type Odds struct {
+        Numerator   int64 `json:"numerator"`
+        Denominator int64 `json:"denominator"`
+}
+
+// NewOdds создает Odds из точной вероятности
+// Если дробь не помещается в int64, она округляется до вида 1/N
+func NewOdds(probability *big.Rat) Odds {
+        if probability.Sign() <= 0 {
+                return Odds{Numerator: 0, Denominator: 1}
+        }
+        if probability.Num().IsInt64() && probability.Denom().IsInt64() {
+                return Odds{Numerator: probability.Num().Int64(), Denominator: probability.Denom().Int64()}
+        }
+
+        inverse, _ := new(big.Rat).Inv(probability).Float64()
+        return Odds{Numerator: 1, Denominator: int64(math.Round(inverse))}
+}
+
+// Rat возвращает вероятность как big.Rat
+func (o Odds) Rat() *big.Rat {
+        if o.Denominator == 0 {
+                return new(big.Rat)
+        }
+        return big.NewRat(o.Numerator, o.Denominator)
+}
+
+// Float64 возвращает вероятность как число (0-1)
+func (o Odds) Float64() float64 {
+        if o.Denominator == 0 {
+                return 0
+        }
+        return float64(o.Numerator) / float64(o.Denominator)
+}
+
+// String форматирует вероятность как "1:N"
+func (o Odds) String() string {
+        return FormatOdds(o.Rat())
+}
+
+// String форматирует приз для отображения: "10000 ₽", "Доля призового фонда", "Джекпот (320.0 млн ₽)"
+func (p Payout) String() string {
+        switch p.Kind {
+        case PayoutFixed:
+                return fmt.Sprintf("%.0f ₽", p.Amount)
+        case PayoutPoolShare:
+                return "Доля призового фонда"
+        case PayoutJackpot:
+                if p.Amount > 0 {
+                        return fmt.Sprintf("Джекпот (%.1f млн ₽)", p.Amount/1000000.0)
+                }
+                return "Джекпот"
+        default:
+                return "н/д"
+        }
+}
+
+// FormatOdds форматирует вероятность как "1:N" (N < 10 - с одним знаком после запятой)
+func FormatOdds(probability *big.Rat) string {
+        if probability == nil || probability.Sign() <= 0 {
+                return "н/д"
+        }
+
+        inverse, _ := new(big.Rat).Inv(probability).Float64()
+        if inverse < 10 {
+                return strings.TrimSuffix(fmt.Sprintf("1:%.1f", inverse), ".0")
+        }
+        return fmt.Sprintf("1:%.0f", inverse)
+}
+
+// SplitDrawn раскладывает выигрышную комбинацию тиража по игровым полям:
+// в комбинации подряд идут числа, выпавшие в первом поле, затем во втором и т.д.
+func (r GameRules) SplitDrawn(winningNumbers []int) ([][]int, error) {
+        total := 0
+        for _, field := range r.Fields {
+                total += field.drawn()
+        }
+        if len(winningNumbers) != total {
+                return nil, fmt.Errorf("в выигрышной комбинации %d чисел, ожидается %d", len(winningNumbers), total)
+        }
+
+        drawn := make([][]int, len(r.Fields))
+        offset := 0
+        for i, field := range r.Fields {
+                drawn[i] = winningNumbers[offset : offset+field.drawn()]
+                offset += field.drawn()
+        }
+        return drawn, nil
+}
+
+// ValidatePicks проверяет числа игрока: в каждом поле ровно Pick разных чисел от 1 до Of
+func (r GameRules) ValidatePicks(picks [][]int) error {
+        if len(picks) != len(r.Fields) {
+                return fmt.Errorf("ожидается полей: %d, передано: %d", len(r.Fields), len(picks))
+        }
+        for i, field := range r.Fields {
+                if len(picks[i]) != field.Pick {
+                        return fmt.Errorf("поле %d: ожидается чисел: %d, передано: %d", i+1, field.Pick, len(picks[i]))
+                }
+                seen := make(map[int]bool, len(picks[i]))
+                for _, number := range picks[i] {
+                        if number < 1 || number > field.Of {
+                                return fmt.Errorf("поле %d: число %d вне диапазона от 1 до %d", i+1, number, field.Of)
+                        }
+                        if seen[number] {
+                                return fmt.Errorf("поле %d: число %d выбрано дважды", i+1, number)
+                        }
+                        seen[number] = true
+                }
+        }
+        return nil
+}
+
+// MatchPicks сравнивает числа игрока с выпавшими по полям (см. SplitDrawn)
+// Возвращает совпавшие числа по полям и индекс выигранной категории (-1 - без выигрыша)
+func (r GameRules) MatchPicks(picks, drawn [][]int) ([][]int, int) {
+        matched := make([][]int, len(picks))
+        outcome := make([]int, len(picks))
+        for i := range picks {
+                winning := make(map[int]bool, len(drawn[i]))
+                for _, number := range drawn[i] {
+                        winning[number] = true
+                }
+                matched[i] = []int{}
+                for _, number := range picks[i] {
+                        if winning[number] {
+                                matched[i] = append(matched[i], number)
+                        }
+                }
+                outcome[i] = len(matched[i])
+        }
+        return matched, r.tierFor(outcome)
+}
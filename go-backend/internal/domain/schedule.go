@@ -0,0 +1,142 @@
+package domain
+
+import (
+        "fmt"
+        "sort"
+        "time"
+)
+
+// MoscowTime - часовой пояс расписаний тиражей (МСК, UTC+3 без перехода на летнее время)
+var MoscowTime = time.FixedZone("MSK", 3*60*60)
+
+// DrawSchedule описывает расписание тиражей по московскому времени
+// Тиражи проходят в дни Weekdays в моменты Times и (или) каждые EveryMinutes минут с From до To
+type DrawSchedule struct {
+        Weekdays     []int    `json:"weekdays,omitempty"`     // Дни недели: 1 - понедельник, ..., 7 - воскресенье; пусто - каждый день
+        Times        []string `json:"times,omitempty"`        // Время тиражей "ЧЧ:ММ"
+        EveryMinutes int      `json:"everyMinutes,omitempty"` // Интервал между тиражами в минутах
+        From         string   `json:"from,omitempty"`         // Первый тираж дня при интервальном расписании "ЧЧ:ММ"
+        To           string   `json:"to,omitempty"`           // Последний тираж дня не позже "ЧЧ:ММ"
+}
+
+// Validate проверяет корректность расписания
+func (s DrawSchedule) Validate() error {
+        for _, weekday := range s.Weekdays {
+                if weekday < 1 || weekday > 7 {
+                        return fmt.Errorf("некорректный день недели %d (ожидается от 1 до 7)", weekday)
+                }
+        }
+        for _, clock := range s.Times {
+                if _, err := parseClock(clock); err != nil {
+                        return err
+                }
+        }
+
+        if s.EveryMinutes < 0 {
+                return fmt.Errorf("некорректный интервал %d минут", s.EveryMinutes)
+        }
+        if s.EveryMinutes > 0 {
+                from, err := parseClock(s.From)
+                if err != nil {
+                        return fmt.Errorf("начало интервала: %w", err)
+                }
+                to, err := parseClock(s.To)
+                if err != nil {
+                        return fmt.Errorf("конец интервала: %w", err)
+                }
+                if from > to {
+                        return fmt.Errorf("интервал %s-%s заканчивается раньше, чем начинается", s.From, s.To)
+                }
+        }
+
+        if len(s.Times) == 0 && s.EveryMinutes == 0 {
+                return fmt.Errorf("не задано время тиражей")
+        }
+        return nil
+}
+
+// NextDraws возвращает до n ближайших тиражей строго после after (время МСК)
+// Для некорректного расписания возвращает nil
+func (s DrawSchedule) NextDraws(after time.Time, n int) []time.Time {
+        if n <= 0 || s.Validate() != nil {
+                return nil
+        }
+
+        clocks := s.clocks()
+        after = after.In(MoscowTime)
+        year, month, day := after.Date()
+
+        // Тиражи есть хотя бы раз в неделю, поэтому n тиражей найдутся не дальше чем за n+1 неделю
+        draws := make([]time.Time, 0, n)
+        for offset := 0; offset <= 7*(n+1) && len(draws) < n; offset++ {
+                date := time.Date(year, month, day+offset, 0, 0, 0, 0, MoscowTime)
+                if !s.drawsOn(date.Weekday()) {
+                        continue
+                }
+                for _, minutes := range clocks {
+                        draw := date.Add(time.Duration(minutes) * time.Minute)
+                        if !draw.After(after) {
+                                continue
+                        }
+                        draws = append(draws, draw)
+                        if len(draws) == n {
+                                break
+                        }
+                }
+        }
+        return draws
+}
+
+// drawsOn проверяет, проходят ли тиражи в этот день недели
+func (s DrawSchedule) drawsOn(weekday time.Weekday) bool {
+        if len(s.Weekdays) == 0 {
+                return true
+        }
+        iso := int(weekday)
+        if weekday == time.Sunday {
+                iso = 7
+        }
+        for _, day := range s.Weekdays {
+                if day == iso {
+                        return true
+                }
+        }
+        return false
+}
+
+// clocks возвращает время тиражей дня в минутах от полуночи, по возрастанию и без повторов
+// (расписание должно быть проверено Validate)
+func (s DrawSchedule) clocks() []int {
+        seen := make(map[int]bool)
+        var clocks []int
+        add := func(minutes int) {
+                if !seen[minutes] {
+                        seen[minutes] = true
+                        clocks = append(clocks, minutes)
+                }
+        }
+
+        for _, clock := range s.Times {
+                minutes, _ := parseClock(clock)
+                add(minutes)
+        }
+        if s.EveryMinutes > 0 {
+                from, _ := parseClock(s.From)
+                to, _ := parseClock(s.To)
+                for minutes := from; minutes <= to; minutes += s.EveryMinutes {
+                        add(minutes)
+                }
+        }
+
+        sort.Ints(clocks)
+        return clocks
+}
+
+// parseClock разбирает время "ЧЧ:ММ" в минуты от полуночи
+func parseClock(clock string) (int, error) {
+        parsed, err := time.Parse("15:04", clock)
+        if err != nil {
+                return 0, fmt.Errorf("некорректное время %q (ожидается ЧЧ:ММ)", clock)
+        }
+        return parsed.Hour()*60 + parsed.Minute(), nil
+}
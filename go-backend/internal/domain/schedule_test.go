@@ -0,0 +1,81 @@
+package domain
+
+import (
+        "testing"
+        "time"
+)
+
+// TestNextDrawsWeekly проверяет расписание по дням недели
+func TestNextDrawsWeekly(t *testing.T) {
+        schedule := DrawSchedule{Weekdays: []int{3, 6}, Times: []string{"21:00"}}
+
+        // Среда, 14 октября 2026, 21:00 МСК - тираж как раз проходит, он не считается будущим
+        after := time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC)
+        draws := schedule.NextDraws(after, 3)
+
+        expected := []time.Time{
+                time.Date(2026, 10, 17, 21, 0, 0, 0, MoscowTime),
+                time.Date(2026, 10, 21, 21, 0, 0, 0, MoscowTime),
+                time.Date(2026, 10, 24, 21, 0, 0, 0, MoscowTime),
+        }
+        if len(draws) != len(expected) {
+                t.Fatalf("Ожидается %d тиражей, получено %v", len(expected), draws)
+        }
+        for i := range expected {
+                if !draws[i].Equal(expected[i]) {
+                        t.Errorf("Тираж %d: ожидается %v, получено %v", i+1, expected[i], draws[i])
+                }
+                if draws[i].Location() != MoscowTime {
+                        t.Errorf("Тираж %d: время должно быть в МСК, получено %v", i+1, draws[i].Location())
+                }
+        }
+}
+
+// TestNextDrawsInterval проверяет интервальное расписание с переходом на следующий день
+func TestNextDrawsInterval(t *testing.T) {
+        schedule := DrawSchedule{EveryMinutes: 15, From: "09:00", To: "23:00"}
+
+        // 22:40 МСК: остаются тиражи 22:45 и 23:00, дальше - 09:00 следующего дня
+        after := time.Date(2026, 10, 16, 22, 40, 0, 0, MoscowTime)
+        draws := schedule.NextDraws(after, 4)
+
+        expected := []string{"2026-10-16 22:45", "2026-10-16 23:00", "2026-10-17 09:00", "2026-10-17 09:15"}
+        if len(draws) != len(expected) {
+                t.Fatalf("Ожидается %d тиражей, получено %v", len(expected), draws)
+        }
+        for i := range expected {
+                if got := draws[i].Format("2006-01-02 15:04"); got != expected[i] {
+                        t.Errorf("Тираж %d: ожидается %s, получено %s", i+1, expected[i], got)
+                }
+        }
+
+        // 14 часов по 4 тиража плюс последний в 23:00
+        day := schedule.NextDraws(time.Date(2026, 10, 16, 0, 0, 0, 0, MoscowTime), 100)
+        if perDay := len(schedule.clocks()); perDay != 57 || day[perDay].Format("15:04") != "09:00" {
+                t.Errorf("Ожидается 57 тиражей в день, получено %d", perDay)
+        }
+}
+
+// TestDrawScheduleValidate проверяет отклонение некорректных расписаний
+func TestDrawScheduleValidate(t *testing.T) {
+        invalid := map[string]DrawSchedule{
+                "пустое":         {},
+                "день недели":    {Weekdays: []int{0}, Times: []string{"10:00"}},
+                "время":          {Times: []string{"25:00"}},
+                "формат времени": {Times: []string{"9 утра"}},
+                "интервал":       {EveryMinutes: 15, From: "23:00", To: "09:00"},
+                "границы":        {EveryMinutes: 15},
+        }
+        for name, schedule := range invalid {
+                if err := schedule.Validate(); err == nil {
+                        t.Errorf("%s: ожидается ошибка", name)
+                }
+                if draws := schedule.NextDraws(time.Now(), 1); draws != nil {
+                        t.Errorf("%s: для некорректного расписания тиражей нет, получено %v", name, draws)
+                }
+        }
+
+        if err := (DrawSchedule{Times: []string{"20:00", "08:00"}}).Validate(); err != nil {
+                t.Errorf("Корректное расписание отклонено: %v", err)
+        }
+}
@@ -0,0 +1,49 @@
+package domain
+
+// SimulationPlan представляет план игры в одной лотерее
+type SimulationPlan struct {
+        LotteryID      string `json:"lotteryId" validate:"required"`                    // ID лотереи
+        TicketsPerDraw int    `json:"ticketsPerDraw" validate:"required,min=1,max=100"` // Билетов в каждом тираже
+        Draws          int    `json:"draws" validate:"required,min=1,max=1000"`         // Сколько тиражей играть
+}
+
+// SimulationRequest представляет запрос на моделирование результатов плана игры
+type SimulationRequest struct {
+        Plans  []SimulationPlan `json:"plans" validate:"required,min=1,max=10,dive"`    // Планы по лотереям (играются одновременно)
+        Trials int              `json:"trials" validate:"omitempty,min=100,max=100000"` // Количество испытаний (по умолчанию 10000)
+        Seed   *int64           `json:"seed,omitempty"`                                 // Зерно генератора для воспроизводимого результата
+}
+
+// SimulationPercentile представляет процентиль распределения результата
+type SimulationPercentile struct {
+        Percentile int     `json:"percentile"` // Процентиль (1-99)
+        Net        float64 `json:"net"`        // Результат (выигрыш - затраты) в рублях
+}
+
+// SimulationTier представляет категорию приза в моделировании
+type SimulationTier struct {
+        LotteryID   string  `json:"lotteryId"`   // ID лотереи
+        Category    string  `json:"category"`    // Категория приза
+        Probability float64 `json:"probability"` // Вероятность категории на один билет
+        Prize       float64 `json:"prize"`       // Приз категории в рублях (доля фонда - средний приз)
+        AtLeastOne  float64 `json:"atLeastOne"`  // Доля испытаний, в которых выигран хотя бы один приз категории
+        // Расчетная вероятность выиграть хотя бы один приз категории за весь план (как в шансах за несколько тиражей)
+        AtLeastOneCalculated float64 `json:"atLeastOneCalculated"`
+        Exact                bool    `json:"exact"` // Расчет точный; false - оценка в предположении независимых билетов тиража
+}
+
+// SimulationResult представляет распределение результата плана игры по испытаниям
+type SimulationResult struct {
+        Trials           int                    `json:"trials"`           // Количество испытаний
+        Seed             int64                  `json:"seed"`             // Зерно генератора: с ним запрос вернет тот же результат
+        Tickets          int                    `json:"tickets"`          // Билетов за весь план
+        Cost             float64                `json:"cost"`             // Затраты на весь план в рублях
+        ExpectedNet      float64                `json:"expectedNet"`      // Математическое ожидание результата (по EV билетов)
+        ExpectedLoss     float64                `json:"expectedLoss"`     // Ожидаемый проигрыш: -ExpectedNet
+        MeanNet          float64                `json:"meanNet"`          // Средний результат по испытаниям
+        MinNet           float64                `json:"minNet"`           // Худший результат
+        MaxNet           float64                `json:"maxNet"`           // Лучший результат
+        ProbabilityAhead float64                `json:"probabilityAhead"` // Доля испытаний, закончившихся в плюсе
+        Percentiles      []SimulationPercentile `json:"percentiles"`      // Процентили результата
+        Tiers            []SimulationTier       `json:"tiers"`            // Категории приза по лотереям плана
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+        "encoding/json"
+        "fmt"
+        "time"
+)
+
 // LotteryType представляет тип лотереи
 type LotteryType string
 
@@ -20,11 +26,50 @@ const (
         DrawFrequencyMonthly        DrawFrequency = "раз в месяц"            // Раз в месяц
 )
 
+// DataSource показывает, откуда получены данные о лотереях
+type DataSource string
+
+const (
+        DataSourceLive     DataSource = "live"     // Данные получены из источников (StolotoAPI, каталог)
+        DataSourceStale    DataSource = "stale"    // Данные источников устарели, а обновить их не удалось
+        DataSourceSnapshot DataSource = "snapshot" // Последний успешный снимок каталога с диска
+        DataSourceMock     DataSource = "mock"     // Встроенные демонстрационные данные
+        DataSourceArchive  DataSource = "archive"  // Локальный архив результатов тиражей
+)
+
+// DataProvenance описывает происхождение данных в ответе API
+// Позволяет клиенту показать предупреждение, если данные могут быть устаревшими
+type DataProvenance struct {
+        Source    DataSource `json:"source"`              // Источник данных
+        FetchedAt *time.Time `json:"fetchedAt,omitempty"` // Когда данные были получены (нет для моковых данных)
+}
+
+// PayoutKind определяет, как считается приз категории
+type PayoutKind string
+
+const (
+        PayoutFixed     PayoutKind = "fixed"     // Фиксированная сумма
+        PayoutPoolShare PayoutKind = "poolShare" // Доля призового фонда тиража, делится между победителями категории
+        PayoutJackpot   PayoutKind = "jackpot"   // Джекпот (суперприз)
+)
+
+// Payout представляет приз категории в виде, пригодном для расчетов
+type Payout struct {
+        Kind   PayoutKind `json:"kind"`             // Вид приза
+        Amount float64    `json:"amount,omitempty"` // Сумма в рублях: для fixed - приз, для jackpot - текущий джекпот
+        Share  float64    `json:"share,omitempty"`  // Для poolShare - доля призового фонда тиража (0-1)
+}
+
 // PrizeCategory представляет категорию приза в структуре призов
+// Строковые поля сохранены для совместимости с фронтендом; Match, Odds и Payout
+// заполняются, если известны правила игры
 type PrizeCategory struct {
-        Category    string `json:"category" validate:"required"`    // Категория приза
-        Prize       string `json:"prize" validate:"required"`       // Размер приза
-        Probability string `json:"probability" validate:"required"` // Вероятность выигрыша
+        Category    string  `json:"category" validate:"required"`    // Категория приза
+        Prize       string  `json:"prize" validate:"required"`       // Размер приза
+        Probability string  `json:"probability" validate:"required"` // Вероятность выигрыша
+        Match       [][]int `json:"match,omitempty"`                 // Условие категории: варианты совпадений по полям (см. MatchTier)
+        Odds        *Odds   `json:"odds,omitempty"`                  // Точная вероятность категории
+        Payout      *Payout `json:"payout,omitempty"`                // Приз категории
 }
 
 // Lottery представляет лотерею
@@ -43,6 +88,250 @@ type Lottery struct {
         PrizeStructure []PrizeCategory `json:"prizeStructure" validate:"required,dive"`  // Структура призов
         ImageURL       *string         `json:"imageUrl,omitempty"`                       // URL изображения (опционально)
         IsActive       bool            `json:"isActive"`                                 // Активна ли лотерея
+        // Доля оставшихся в продаже билетов тиража (0-1), только для моментальных лотерей
+        TicketsRemaining *float64 `json:"ticketsRemaining,omitempty"`
+        // Правила числовой игры (поля и выигрышные категории), если они известны
+        GameRules *GameRules `json:"gameRules,omitempty"`
+        // Ожидаемый выигрыш с билета, если известны вероятности и призы категорий
+        ExpectedValue *ExpectedValue `json:"expectedValue,omitempty"`
+        // Расписание тиражей по московскому времени, если оно известно
+        Schedule *DrawSchedule `json:"schedule,omitempty"`
+        // Ближайшие тиражи по расписанию (рассчитываются на момент запроса)
+        NextDraws []time.Time `json:"nextDraws,omitempty"`
+}
+
+// DrawPrizeTier представляет призовую категорию разыгранного тиража
+type DrawPrizeTier struct {
+        Tier    string  `json:"tier"`    // Категория как в StolotoAPI ("6", "5+1", "4")
+        Winners int     `json:"winners"` // Количество выигрышных билетов
+        Prize   float64 `json:"prize"`   // Выплата на один выигрышный билет в рублях
+}
+
+// DrawResult представляет результат тиража лотереи
+type DrawResult struct {
+        LotteryID      string          `json:"lotteryId"`          // ID лотереи
+        Number         int             `json:"number"`             // Номер тиража
+        DrawDate       *time.Time      `json:"drawDate,omitempty"` // Дата розыгрыша (если известна)
+        Status         string          `json:"status,omitempty"`   // Статус тиража в StolotoAPI
+        WinningNumbers []int           `json:"winningNumbers"`     // Выигрышная комбинация
+        Jackpot        float64         `json:"jackpot"`            // Суперприз тиража в рублях
+        PrizePool      float64         `json:"prizePool"`          // Призовой фонд тиража в рублях
+        Winners        []DrawPrizeTier `json:"winners"`            // Победители по категориям, от главной к младшей
+        TotalWinners   int             `json:"totalWinners"`       // Всего выигрышных билетов
+}
+
+// DrawResultsPage представляет страницу истории тиражей
+type DrawResultsPage struct {
+        Draws  []DrawResult `json:"draws"`  // Тиражи от новых к старым
+        Total  int          `json:"total"`  // Всего тиражей в истории
+        Offset int          `json:"offset"` // Сколько тиражей пропущено
+        Limit  int          `json:"limit"`  // Размер страницы
+}
+
+// NumberFrequency представляет частоту выпадения числа
+type NumberFrequency struct {
+        Number    int     `json:"number"`    // Число
+        Count     int     `json:"count"`     // Сколько раз выпало
+        Frequency float64 `json:"frequency"` // Доля тиражей, в которых выпало (0-1)
+}
+
+// NumberGap представляет текущую паузу числа
+type NumberGap struct {
+        Number   int `json:"number"`             // Число
+        Gap      int `json:"gap"`                // Сколько тиражей прошло с последнего выпадения (0 - выпало в последнем)
+        LastDraw int `json:"lastDraw,omitempty"` // Номер тиража, в котором число выпало последний раз (нет - не выпадало)
+}
+
+// NumberCombination представляет пару или тройку чисел, выпадавших вместе
+type NumberCombination struct {
+        Numbers []int `json:"numbers"` // Числа по возрастанию
+        Count   int   `json:"count"`   // В скольких тиражах выпали вместе
+}
+
+// DistributionBucket представляет долю тиражей с определенным составом комбинации
+type DistributionBucket struct {
+        Label string  `json:"label"` // Состав, например "4/2" - 4 нечетных и 2 четных
+        Count int     `json:"count"` // Количество тиражей
+        Share float64 `json:"share"` // Доля тиражей (0-1)
+}
+
+// HotColdWindow представляет горячие и холодные числа за последние тиражи
+type HotColdWindow struct {
+        Window int               `json:"window"` // Запрошенное окно (тиражей)
+        Draws  int               `json:"draws"`  // Сколько тиражей фактически проанализировано
+        Hot    []NumberFrequency `json:"hot"`    // Чаще всего выпадавшие числа
+        Cold   []NumberFrequency `json:"cold"`   // Реже всего выпадавшие числа
+}
+
+// FieldStats представляет статистику выпадения чисел одного игрового поля
+type FieldStats struct {
+        Field       int                  `json:"field"`       // Номер игрового поля (с 1)
+        MaxNumber   int                  `json:"maxNumber"`   // Наибольшее число игрового поля
+        Windows     []HotColdWindow      `json:"windows"`     // Горячие и холодные числа по окнам
+        Frequencies []NumberFrequency    `json:"frequencies"` // Частоты всех чисел по всем тиражам
+        Gaps        []NumberGap          `json:"gaps"`        // Текущие паузы, от самой длинной
+        Pairs       []NumberCombination  `json:"pairs"`       // Самые частые пары
+        Triplets    []NumberCombination  `json:"triplets"`    // Самые частые тройки
+        OddEven     []DistributionBucket `json:"oddEven"`     // Распределение "нечетные/четные"
+        LowHigh     []DistributionBucket `json:"lowHigh"`     // Распределение "малые/большие" (малые - до половины поля)
+}
+
+// NumberStats представляет статистику выпадения чисел лотереи по архиву тиражей
+type NumberStats struct {
+        LotteryID  string       `json:"lotteryId"`  // ID лотереи
+        TotalDraws int          `json:"totalDraws"` // Всего тиражей в анализе
+        FirstDraw  int          `json:"firstDraw"`  // Номер самого раннего тиража
+        LastDraw   int          `json:"lastDraw"`   // Номер последнего тиража
+        Fields     []FieldStats `json:"fields"`     // Статистика по игровым полям (без правил игры - одно поле из всех чисел)
+}
+
+// TierValue представляет вклад призовой категории в ожидаемый выигрыш
+type TierValue struct {
+        Category       string     `json:"category"`                 // Категория приза
+        Kind           PayoutKind `json:"kind"`                     // Вид приза
+        Odds           Odds       `json:"odds"`                     // Вероятность категории
+        AverageWinners *float64   `json:"averageWinners,omitempty"` // Среднее число победителей категории за тираж (если есть история)
+        PlainPrize     float64    `json:"plainPrize"`               // Приз на выигрышный билет в простом EV в рублях
+        TypicalPrize   float64    `json:"typicalPrize"`             // Типичная выплата на выигрышный билет с учетом дележа в рублях
+}
+
+// ExpectedValueReport сравнивает простой EV и EV с учетом дележа призов между победителями
+type ExpectedValueReport struct {
+        LotteryID     string         `json:"lotteryId"`          // ID лотереи
+        TicketPrice   float64        `json:"ticketPrice"`        // Цена билета в рублях
+        DrawsAnalyzed int            `json:"drawsAnalyzed"`      // Сколько последних тиражей с данными о победителях учтено
+        Plain         ExpectedValue  `json:"plain"`              // Простой EV: джекпот целиком, доли фонда по 50% выручки
+        Adjusted      *ExpectedValue `json:"adjusted,omitempty"` // EV с учетом дележа (нет истории тиражей - не передается)
+        Tiers         []TierValue    `json:"tiers"`              // Вклад категорий
+}
+
+// TierCumulativeOdds представляет шансы выиграть в категории приза за несколько тиражей
+type TierCumulativeOdds struct {
+        Category      string   `json:"category"`                // Категория приза ("Любой выигрыш" - все категории вместе)
+        Probability   float64  `json:"probability"`             // Вероятность на один билет
+        PerDraw       float64  `json:"perDraw"`                 // Вероятность хотя бы одного приза в одном тираже
+        Exact         bool     `json:"exact"`                   // Точный расчет; false - оценка в предположении независимых билетов тиража
+        AtLeastOne    float64  `json:"atLeastOne"`              // Вероятность хотя бы одного приза за все тиражи
+        ExpectedDraws float64  `json:"expectedDraws"`           // Ожидаемое число тиражей до первого приза
+        ExpectedSpend *float64 `json:"expectedSpend,omitempty"` // Ожидаемые затраты до первого приза в рублях (цена билета неизвестна - не передается)
+}
+
+// CumulativeOdds представляет шансы выиграть при игре несколькими билетами в нескольких тиражах
+type CumulativeOdds struct {
+        LotteryID      string               `json:"lotteryId"`      // ID лотереи
+        TicketPrice    float64              `json:"ticketPrice"`    // Цена билета в рублях
+        TicketsPerDraw int                  `json:"ticketsPerDraw"` // Билетов в каждом тираже
+        Draws          int                  `json:"draws"`          // Количество тиражей
+        Cost           float64              `json:"cost"`           // Затраты на все билеты в рублях
+        AnyPrize       TierCumulativeOdds   `json:"anyPrize"`       // Любой выигрыш
+        Tiers          []TierCumulativeOdds `json:"tiers"`          // Категории приза с известной вероятностью
+}
+
+// TicketCombination - числа игрока по игровым полям: [[5, 12, 18, 27, 33], [2]]
+// Для игр с одним полем можно передать плоский массив: [5, 12, 18, 27, 33, 41]
+type TicketCombination [][]int
+
+// UnmarshalJSON разбирает комбинацию по полям или плоский массив чисел одного поля
+func (c *TicketCombination) UnmarshalJSON(data []byte) error {
+        var fields [][]int
+        if err := json.Unmarshal(data, &fields); err == nil {
+                *c = fields
+                return nil
+        }
+
+        var numbers []int
+        if err := json.Unmarshal(data, &numbers); err != nil {
+                return fmt.Errorf("комбинация должна быть массивом чисел или массивом полей: %w", err)
+        }
+        *c = TicketCombination{numbers}
+        return nil
+}
+
+// TicketCheckRequest представляет запрос проверки билета
+type TicketCheckRequest struct {
+        LotteryID    string              `json:"lotteryId" validate:"required"`                  // ID лотереи
+        DrawNumber   int                 `json:"drawNumber" validate:"required,min=1"`           // Номер тиража
+        Combinations []TicketCombination `json:"combinations" validate:"required,min=1,max=100"` // Комбинации билета
+}
+
+// CombinationCheck представляет результат проверки одной комбинации
+type CombinationCheck struct {
+        Numbers  TicketCombination `json:"numbers"`            // Числа игрока по полям
+        Matched  [][]int           `json:"matched"`            // Совпавшие числа по полям
+        Won      bool              `json:"won"`                // Выиграла ли комбинация
+        Category string            `json:"category,omitempty"` // Выигранная категория ("5 из 6")
+        Prize    *float64          `json:"prize,omitempty"`    // Выигрыш в рублях (не передается, если размер неизвестен)
+}
+
+// TicketCheckResult представляет результат проверки билета
+type TicketCheckResult struct {
+        LotteryID      string             `json:"lotteryId"`          // ID лотереи
+        DrawNumber     int                `json:"drawNumber"`         // Номер тиража
+        DrawDate       *time.Time         `json:"drawDate,omitempty"` // Дата розыгрыша (если известна)
+        WinningNumbers [][]int            `json:"winningNumbers"`     // Выигрышная комбинация по полям
+        Combinations   []CombinationCheck `json:"combinations"`       // Результаты в порядке комбинаций запроса
+        TotalPrize     float64            `json:"totalPrize"`         // Сумма выигрышей с известным размером в рублях
+}
+
+// BacktestRequest представляет запрос на проверку комбинаций по истории тиражей
+// Диапазон задается номерами и/или датами тиражей (ГГГГ-ММ-ДД по МСК, включительно); пустые границы - без ограничения
+type BacktestRequest struct {
+        Combinations []TicketCombination `json:"combinations" validate:"required,min=1,max=100"` // Комбинации, сыгранные в каждом тираже
+        FromNumber   int                 `json:"fromNumber" validate:"min=0"`                    // Первый тираж
+        ToNumber     int                 `json:"toNumber" validate:"min=0"`                      // Последний тираж
+        From         string              `json:"from" validate:"omitempty,datetime=2006-01-02"`  // Тиражи не раньше даты
+        To           string              `json:"to" validate:"omitempty,datetime=2006-01-02"`    // Тиражи не позже даты
+}
+
+// BacktestTier представляет выигрыши категории за период
+type BacktestTier struct {
+        Category      string  `json:"category"`      // Категория приза
+        Hits          int     `json:"hits"`          // Сколько раз комбинации выиграли в категории
+        Prize         float64 `json:"prize"`         // Сумма выигрышей с известным размером в рублях
+        UnknownPrizes int     `json:"unknownPrizes"` // Выигрыши, размер которых неизвестен (доля фонда без данных тиража)
+}
+
+// BacktestResult представляет результат проверки комбинаций по истории тиражей
+type BacktestResult struct {
+        LotteryID    string             `json:"lotteryId"`          // ID лотереи
+        Draws        int                `json:"draws"`              // Сколько тиражей проверено
+        FirstDraw    int                `json:"firstDraw"`          // Номер первого проверенного тиража
+        LastDraw     int                `json:"lastDraw"`           // Номер последнего проверенного тиража
+        Plays        int                `json:"plays"`              // Сыграно комбинаций (комбинации в каждом тираже)
+        WinningPlays int                `json:"winningPlays"`       // Из них выигрышных
+        Cost         float64            `json:"cost"`               // Стоимость по текущей цене билета в рублях
+        TotalPrize   float64            `json:"totalPrize"`         // Сумма выигрышей с известным размером в рублях
+        Net          float64            `json:"net"`                // TotalPrize - Cost
+        Tiers        []BacktestTier     `json:"tiers"`              // Категории приза от главной к младшей
+        BestDraw     *TicketCheckResult `json:"bestDraw,omitempty"` // Тираж с наибольшим выигрышем (нет выигрышей - не передается)
+}
+
+// PickStrategy представляет стратегию генерации комбинаций
+type PickStrategy string
+
+const (
+        PickStrategyRandom       PickStrategy = "random"       // Равновероятный выбор
+        PickStrategyAvoidPopular PickStrategy = "avoidPopular" // Без популярных шаблонов: даты, прогрессии, прошлые выигрышные комбинации
+        PickStrategyBalanced     PickStrategy = "balanced"     // Поровну четных и нечетных, малых и больших чисел
+        PickStrategyHot          PickStrategy = "hot"          // Чаще выбираются числа, которые чаще выпадали
+        PickStrategyCold         PickStrategy = "cold"         // Чаще выбираются числа, которые реже выпадали
+)
+
+// PicksRequest представляет запрос на генерацию комбинаций
+type PicksRequest struct {
+        Count    int          `json:"count" validate:"omitempty,min=1,max=100"`                                  // Сколько комбинаций (по умолчанию 1)
+        Strategy PickStrategy `json:"strategy" validate:"omitempty,oneof=random avoidPopular balanced hot cold"` // Стратегия (по умолчанию random)
+        Seed     *int64       `json:"seed,omitempty"`                                                            // Зерно генератора для воспроизводимого результата
+        Window   int          `json:"window" validate:"omitempty,min=1,max=1000"`                                // Последние тиражи для hot и cold (по умолчанию 100)
+}
+
+// PicksResult представляет сгенерированные комбинации
+type PicksResult struct {
+        LotteryID    string              `json:"lotteryId"`       // ID лотереи
+        Strategy     PickStrategy        `json:"strategy"`        // Использованная стратегия
+        Seed         int64               `json:"seed"`            // Зерно генератора: с ним запрос вернет те же комбинации
+        Draws        int                 `json:"draws,omitempty"` // Сколько тиражей архива учтено стратегией
+        Combinations []TicketCombination `json:"combinations"`    // Комбинации по игровым полям
 }
 
 // PriceRange представляет диапазон цен
@@ -72,6 +361,7 @@ type UserPreferences struct {
         LotteryType    *LotteryType     `json:"lotteryType,omitempty"`              // Предпочитаемый тип лотереи (опционально)
         MaxJackpot     JackpotRange     `json:"maxJackpot" validate:"required"`     // Диапазон джекпота
         WinProbability ProbabilityRange `json:"winProbability" validate:"required"` // Диапазон вероятности выигрыша
+        PreferValue    bool             `json:"preferValue,omitempty"`              // Учитывать ожидаемый возврат с рубля (опционально)
 }
 
 // Recommendation представляет рекомендацию лотереи
@@ -94,6 +384,7 @@ type RecommendationResponse struct {
         Recommendations   []Recommendation `json:"recommendations" validate:"required,dive"`   // Список рекомендаций
         TotalMatches      int              `json:"totalMatches" validate:"min=0"`              // Общее количество совпадений
         AverageMatchScore float64          `json:"averageMatchScore" validate:"min=0,max=100"` // Средняя оценка совпадения
+        Provenance        *DataProvenance  `json:"provenance,omitempty"`                       // Происхождение данных о лотереях (опционально)
 }
 
 // FilterCriteria представляет критерии фильтрации лотерей
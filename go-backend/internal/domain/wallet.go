@@ -0,0 +1,148 @@
+package domain
+
+import "time"
+
+// TicketStatus представляет статус билета в кошельке
+type TicketStatus string
+
+const (
+        TicketStatusPending TicketStatus = "pending" // Не все тиражи билета проверены, выигрыша пока нет
+        TicketStatusWon     TicketStatus = "won"     // Билет выиграл хотя бы в одном тираже (остальные могут быть еще не проверены)
+        TicketStatusLost    TicketStatus = "lost"    // Все тиражи проверены (или отсутствуют в архиве), выигрыша нет
+)
+
+// WalletTicket представляет билет пользователя в кошельке
+type WalletTicket struct {
+        ID           string              `json:"id"`                     // Идентификатор билета
+        LotteryID    string              `json:"lotteryId"`              // ID лотереи
+        DrawNumbers  []int               `json:"drawNumbers"`            // Тиражи, в которых участвует билет
+        Combinations []TicketCombination `json:"combinations"`           // Комбинации билета
+        AmountPaid   float64             `json:"amountPaid"`             // Сумма, уплаченная за билет, в рублях
+        PurchasedAt  time.Time           `json:"purchasedAt"`            // Дата покупки
+        Status       TicketStatus        `json:"status"`                 // Статус проверки
+        Prize        float64             `json:"prize"`                  // Сумма выигрышей с известным размером в рублях
+        Results      []TicketCheckResult `json:"results,omitempty"`      // Результаты проверенных тиражей
+        MissingDraws []int               `json:"missingDraws,omitempty"` // Тиражи, которых нет в архиве, хотя более поздние разыграны
+        CheckedAt    *time.Time          `json:"checkedAt,omitempty"`    // Когда билет проверялся последний раз
+}
+
+// Checked проверяет, есть ли результат тиража drawNumber или тираж отмечен отсутствующим
+func (t WalletTicket) Checked(drawNumber int) bool {
+        for _, result := range t.Results {
+                if result.DrawNumber == drawNumber {
+                        return true
+                }
+        }
+        for _, missing := range t.MissingDraws {
+                if missing == drawNumber {
+                        return true
+                }
+        }
+        return false
+}
+
+// Complete проверяет, что все тиражи билета проверены или отмечены отсутствующими
+func (t WalletTicket) Complete() bool {
+        return len(t.Results)+len(t.MissingDraws) >= len(t.DrawNumbers)
+}
+
+// UpdateStatus пересчитывает статус и сумму выигрыша по результатам тиражей
+func (t *WalletTicket) UpdateStatus() {
+        t.Prize = 0
+        won := false
+        for _, result := range t.Results {
+                t.Prize += result.TotalPrize
+                for _, combination := range result.Combinations {
+                        won = won || combination.Won
+                }
+        }
+
+        switch {
+        case won:
+                t.Status = TicketStatusWon
+        case !t.Complete():
+                t.Status = TicketStatusPending
+        default:
+                t.Status = TicketStatusLost
+        }
+}
+
+// WalletTicketRequest представляет запрос на добавление билета в кошелек
+type WalletTicketRequest struct {
+        LotteryID    string              `json:"lotteryId" validate:"required"`                            // ID лотереи
+        DrawNumbers  []int               `json:"drawNumbers" validate:"required,min=1,max=100,dive,min=1"` // Тиражи билета
+        Combinations []TicketCombination `json:"combinations" validate:"required,min=1,max=100"`           // Комбинации билета
+        AmountPaid   float64             `json:"amountPaid" validate:"min=0"`                              // Сумма, уплаченная за билет, в рублях
+        PurchasedAt  *time.Time          `json:"purchasedAt,omitempty"`                                    // Дата покупки (по умолчанию - сейчас)
+}
+
+// WalletFilter задает выборку билетов кошелька
+// Нулевые значения означают "без ограничения"
+type WalletFilter struct {
+        LotteryID string
+        Status    TicketStatus
+        From      time.Time // Куплены не раньше (включительно)
+        To        time.Time // Куплены раньше (не включительно)
+        Offset    int
+        Limit     int
+}
+
+// WalletPage представляет страницу билетов кошелька
+type WalletPage struct {
+        Tickets []WalletTicket `json:"tickets"` // Билеты от новых к старым
+        Total   int            `json:"total"`   // Всего билетов, подходящих под фильтр
+        Offset  int            `json:"offset"`  // Сколько билетов пропущено
+        Limit   int            `json:"limit"`   // Размер страницы
+}
+
+// WalletTotals представляет итоги по группе билетов кошелька
+type WalletTotals struct {
+        Tickets int      `json:"tickets"`       // Количество билетов
+        Pending int      `json:"pending"`       // Из них ожидают тиражей
+        Spent   float64  `json:"spent"`         // Потрачено на билеты в рублях
+        Won     float64  `json:"won"`           // Выиграно в рублях (выигрыши с известным размером)
+        Net     float64  `json:"net"`           // Won - Spent
+        ROI     *float64 `json:"roi,omitempty"` // Net / Spent (нет затрат - не передается)
+}
+
+// WalletTierStats представляет частоту выигрышей в категории приза
+type WalletTierStats struct {
+        Category        string   `json:"category"`                  // Категория приза ("6 из 6", "5+1")
+        Hits            int      `json:"hits"`                      // Сколько раз комбинации выиграли в категории
+        HitRate         float64  `json:"hitRate"`                   // Hits на одну проверенную комбинацию в тираже
+        ExpectedHitRate *float64 `json:"expectedHitRate,omitempty"` // Вероятность категории по правилам игры
+        Won             float64  `json:"won"`                       // Выиграно в категории в рублях
+        ROI             float64  `json:"roi"`                       // Won на рубль затрат на проверенные тиражи
+}
+
+// WalletExpectation сравнивает фактический выигрыш по проверенным тиражам с ожидаемым
+type WalletExpectation struct {
+        Stake          float64 `json:"stake"`          // Затраты на проверенные тиражи в рублях
+        Won            float64 `json:"won"`            // Фактический выигрыш в этих тиражах
+        ExpectedWon    float64 `json:"expectedWon"`    // Ожидаемый выигрыш (EV до НДФЛ на каждую комбинацию в тираже)
+        Return         float64 `json:"return"`         // Won / Stake
+        ExpectedReturn float64 `json:"expectedReturn"` // ExpectedWon / Stake
+}
+
+// WalletLotterySummary представляет итоги кошелька по лотерее
+type WalletLotterySummary struct {
+        LotteryID   string             `json:"lotteryId"`
+        Totals      WalletTotals       `json:"totals"`
+        Plays       int                `json:"plays"`                 // Проверенных комбинаций (комбинация в одном тираже)
+        Tiers       []WalletTierStats  `json:"tiers,omitempty"`       // Категории приза от главной к младшей (если известны правила игры)
+        Expectation *WalletExpectation `json:"expectation,omitempty"` // Нет проверенных тиражей или EV лотереи - не передается
+}
+
+// WalletMonthSummary представляет итоги кошелька по месяцу покупки
+type WalletMonthSummary struct {
+        Month  string       `json:"month"` // Месяц покупки по МСК (ГГГГ-ММ)
+        Totals WalletTotals `json:"totals"`
+}
+
+// WalletSummary представляет статистику выигрышей и проигрышей пользователя
+type WalletSummary struct {
+        Totals      WalletTotals           `json:"totals"`
+        Expectation *WalletExpectation     `json:"expectation,omitempty"` // По лотереям с известным EV
+        ByLottery   []WalletLotterySummary `json:"byLottery"`             // По убыванию затрат
+        ByMonth     []WalletMonthSummary   `json:"byMonth"`               // От старых месяцев к новым
+}
@@ -0,0 +1,266 @@
+package domain
+
+import (
+        "fmt"
+        "math/bits"
+        "sort"
+)
+
+const (
+        // MaxWheelLines - наибольшее число комбинаций в системе
+        MaxWheelLines = 10000
+
+        maxWheelPool = 30         // Наибольший пул чисел одного поля
+        maxWheelWork = 20_000_000 // Наибольшее произведение комбинаций пула на варианты выпадения для сокращенной системы
+)
+
+// WheelType представляет вид системы
+type WheelType string
+
+const (
+        WheelTypeFull        WheelType = "full"        // Полная система: все комбинации пула
+        WheelTypeAbbreviated WheelType = "abbreviated" // Сокращенная система с гарантией
+)
+
+// WheelGuarantee представляет гарантию системы "Match если If":
+// если среди выпавших чисел первого поля If чисел есть в пуле, хотя бы одна комбинация угадает не меньше Match из них
+type WheelGuarantee struct {
+        Match int `json:"match" validate:"min=1"` // Сколько чисел угадает хотя бы одна комбинация
+        If    int `json:"if" validate:"min=1"`    // Сколько выпавших чисел должно быть в пуле
+}
+
+// WheelRequest представляет запрос на построение системы
+type WheelRequest struct {
+        Pool      TicketCombination `json:"pool" validate:"required,min=1"` // Пул чисел по игровым полям
+        Guarantee *WheelGuarantee   `json:"guarantee,omitempty"`            // Гарантия сокращенной системы (нет - полная система)
+}
+
+// WheelProof представляет доказательство гарантии системы
+type WheelProof struct {
+        Method     string `json:"method"`     // construction - следует из построения, exhaustive - проверены все варианты
+        Cases      int    `json:"cases"`      // Сколько вариантов выпадения If чисел пула покрывает гарантия
+        WorstMatch int    `json:"worstMatch"` // Лучшее совпадение комбинации в самом неудачном варианте
+        Statement  string `json:"statement"`  // Формулировка гарантии
+}
+
+// Wheel представляет систему ставок: набор комбинаций из пула чисел
+type Wheel struct {
+        LotteryID    string              `json:"lotteryId"`    // ID лотереи
+        Type         WheelType           `json:"type"`         // Вид системы
+        Pool         TicketCombination   `json:"pool"`         // Пул чисел по полям (по возрастанию)
+        Guarantee    WheelGuarantee      `json:"guarantee"`    // Гарантия по первому полю
+        Proof        WheelProof          `json:"proof"`        // Доказательство гарантии
+        Lines        int                 `json:"lines"`        // Количество комбинаций
+        FullLines    int                 `json:"fullLines"`    // Количество комбинаций полной системы из того же пула
+        TicketPrice  float64             `json:"ticketPrice"`  // Цена одной комбинации в рублях
+        Cost         float64             `json:"cost"`         // Стоимость системы в рублях
+        FullCost     float64             `json:"fullCost"`     // Стоимость полной системы в рублях
+        Combinations []TicketCombination `json:"combinations"` // Комбинации системы
+}
+
+// BuildWheel строит систему из пула чисел по полям
+// Без гарантии строится полная система; с гарантией - сокращенная по первому полю (жадное покрытие
+// всех вариантов выпадения с последующей полной проверкой). Числа остальных полей перебираются полностью.
+func (r GameRules) BuildWheel(pool [][]int, guarantee *WheelGuarantee) (*Wheel, error) {
+        sorted, err := r.wheelPool(pool)
+        if err != nil {
+                return nil, err
+        }
+
+        wheel := &Wheel{Type: WheelTypeFull, Pool: sorted, FullLines: 1}
+        for i, field := range r.Fields {
+                wheel.FullLines *= int(binomial(len(sorted[i]), field.Pick).Int64())
+        }
+
+        main := r.Fields[0]
+        size := len(sorted[0])
+        var lines []uint64
+        if guarantee == nil {
+                if wheel.FullLines > MaxWheelLines {
+                        return nil, fmt.Errorf("в полной системе %d комбинаций, допускается не больше %d", wheel.FullLines, MaxWheelLines)
+                }
+                lines = subsetMasks(size, main.Pick)
+                wheel.Guarantee = WheelGuarantee{Match: main.Pick, If: main.Pick}
+                wheel.Proof = WheelProof{
+                        Method:     "construction",
+                        Cases:      len(lines),
+                        WorstMatch: main.Pick,
+                        Statement: fmt.Sprintf("Полная система содержит все комбинации пула (%d): если среди выпавших чисел есть %d из пула, одна из комбинаций совпадет с ними полностью.",
+                                len(lines), main.Pick),
+                }
+        } else {
+                if err := r.validateGuarantee(*guarantee, size); err != nil {
+                        return nil, err
+                }
+                candidates := binomial(size, main.Pick).Int64()
+                cases := binomial(size, guarantee.If).Int64()
+                if candidates*cases > maxWheelWork {
+                        return nil, fmt.Errorf("пул из %d чисел слишком велик для гарантии %d из %d: уменьшите пул", size, guarantee.Match, guarantee.If)
+                }
+
+                variants := subsetMasks(size, guarantee.If)
+                lines = coverWheel(subsetMasks(size, main.Pick), variants, guarantee.Match)
+                worst := worstMatch(lines, variants)
+                if worst < guarantee.Match {
+                        return nil, fmt.Errorf("гарантия %d из %d не выполняется: в худшем случае угадано %d", guarantee.Match, guarantee.If, worst)
+                }
+                wheel.Type = WheelTypeAbbreviated
+                wheel.Guarantee = *guarantee
+                wheel.Proof = WheelProof{
+                        Method:     "exhaustive",
+                        Cases:      len(variants),
+                        WorstMatch: worst,
+                        Statement: fmt.Sprintf("Выпавших чисел в пуле: %d из %d. Проверены все варианты (%d): в каждом хотя бы одна комбинация угадывает не меньше %d из них (в худшем случае %d).",
+                                guarantee.If, size, len(variants), guarantee.Match, worst),
+                }
+        }
+        if len(r.Fields) > 1 {
+                wheel.Proof.Statement += " Числа остальных полей перебираются полностью."
+        }
+
+        // Комбинации первого поля со всеми комбинациями остальных полей
+        wheel.Lines = len(lines) * wheel.FullLines / int(binomial(size, main.Pick).Int64())
+        if wheel.Lines > MaxWheelLines {
+                return nil, fmt.Errorf("в системе %d комбинаций, допускается не больше %d", wheel.Lines, MaxWheelLines)
+        }
+        combinations := make([]TicketCombination, 0, wheel.Lines)
+        for _, line := range lines {
+                combinations = append(combinations, TicketCombination{maskNumbers(line, sorted[0])})
+        }
+        for i := 1; i < len(r.Fields); i++ {
+                extended := make([]TicketCombination, 0, wheel.Lines)
+                for _, combination := range combinations {
+                        for _, mask := range subsetMasks(len(sorted[i]), r.Fields[i].Pick) {
+                                next := append(append(TicketCombination{}, combination...), maskNumbers(mask, sorted[i]))
+                                extended = append(extended, next)
+                        }
+                }
+                combinations = extended
+        }
+        wheel.Combinations = combinations
+        return wheel, nil
+}
+
+// wheelPool проверяет пул и возвращает его копию с числами полей по возрастанию
+func (r GameRules) wheelPool(pool [][]int) (TicketCombination, error) {
+        if len(pool) != len(r.Fields) {
+                return nil, fmt.Errorf("ожидается полей: %d, передано: %d", len(r.Fields), len(pool))
+        }
+        sorted := make(TicketCombination, len(pool))
+        for i, field := range r.Fields {
+                limit := field.Of
+                if limit > maxWheelPool {
+                        limit = maxWheelPool
+                }
+                if len(pool[i]) < field.Pick || len(pool[i]) > limit {
+                        return nil, fmt.Errorf("поле %d: в пуле должно быть от %d до %d чисел, передано: %d", i+1, field.Pick, limit, len(pool[i]))
+                }
+                seen := make(map[int]bool, len(pool[i]))
+                for _, number := range pool[i] {
+                        if number < 1 || number > field.Of {
+                                return nil, fmt.Errorf("поле %d: число %d вне диапазона от 1 до %d", i+1, number, field.Of)
+                        }
+                        if seen[number] {
+                                return nil, fmt.Errorf("поле %d: число %d выбрано дважды", i+1, number)
+                        }
+                        seen[number] = true
+                }
+                sorted[i] = append([]int(nil), pool[i]...)
+                sort.Ints(sorted[i])
+        }
+        return sorted, nil
+}
+
+// validateGuarantee проверяет, что гарантия достижима для пула первого поля из size чисел
+func (r GameRules) validateGuarantee(guarantee WheelGuarantee, size int) error {
+        main := r.Fields[0]
+        switch {
+        case guarantee.Match < 1 || guarantee.Match > guarantee.If:
+                return fmt.Errorf("гарантия %d из %d: угадать можно от 1 до %d чисел", guarantee.Match, guarantee.If, guarantee.If)
+        case guarantee.Match > main.Pick:
+                return fmt.Errorf("гарантия %d из %d: в комбинации только %d чисел", guarantee.Match, guarantee.If, main.Pick)
+        case guarantee.If > size || guarantee.If > main.drawn():
+                return fmt.Errorf("гарантия %d из %d: в пуле %d чисел, в тираже выпадает %d", guarantee.Match, guarantee.If, size, main.drawn())
+        }
+        return nil
+}
+
+// coverWheel жадно выбирает комбинации (битовые маски пула), пока каждый вариант выпадения
+// не совпадет хотя бы с одной из них в match числах
+// На каждом шаге рассматриваются комбинации, покрывающие первый непокрытый вариант, и берется покрывающая больше всех
+func coverWheel(candidates, variants []uint64, match int) []uint64 {
+        uncovered := append([]uint64(nil), variants...)
+        var lines []uint64
+        for len(uncovered) > 0 {
+                target := uncovered[0]
+                best, bestCount := uint64(0), -1
+                for _, line := range candidates {
+                        if bits.OnesCount64(line&target) < match {
+                                continue
+                        }
+                        count := 0
+                        for _, variant := range uncovered {
+                                if bits.OnesCount64(line&variant) >= match {
+                                        count++
+                                }
+                        }
+                        if count > bestCount {
+                                best, bestCount = line, count
+                        }
+                }
+
+                lines = append(lines, best)
+                remaining := uncovered[:0]
+                for _, variant := range uncovered {
+                        if bits.OnesCount64(best&variant) < match {
+                                remaining = append(remaining, variant)
+                        }
+                }
+                uncovered = remaining
+        }
+        return lines
+}
+
+// worstMatch возвращает наименьшее по вариантам выпадения наибольшее совпадение с комбинациями
+func worstMatch(lines, variants []uint64) int {
+        worst := -1
+        for _, variant := range variants {
+                best := 0
+                for _, line := range lines {
+                        if matched := bits.OnesCount64(line & variant); matched > best {
+                                best = matched
+                        }
+                }
+                if worst < 0 || best < worst {
+                        worst = best
+                }
+        }
+        return worst
+}
+
+// subsetMasks возвращает все подмножества из k элементов множества {0..n-1} в виде битовых масок по возрастанию
+func subsetMasks(n, k int) []uint64 {
+        if k == 0 {
+                return []uint64{0}
+        }
+        masks := make([]uint64, 0, binomial(n, k).Int64())
+        for mask := uint64(1)<<k - 1; mask < 1<<n; {
+                masks = append(masks, mask)
+                // Следующая маска с тем же числом единиц (Gosper's hack)
+                lowest := mask & -mask
+                ripple := mask + lowest
+                mask = (((ripple ^ mask) >> 2) / lowest) | ripple
+        }
+        return masks
+}
+
+// maskNumbers возвращает числа пула, отмеченные в маске
+func maskNumbers(mask uint64, pool []int) []int {
+        numbers := make([]int, 0, bits.OnesCount64(mask))
+        for i, number := range pool {
+                if mask&(1<<i) != 0 {
+                        numbers = append(numbers, number)
+                }
+        }
+        return numbers
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+        "fmt"
+        "testing"
+)
+
+// TestBuildFullWheel проверяет полную систему для одного и двух игровых полей
+func TestBuildFullWheel(t *testing.T) {
+        rules := GameRules{Fields: []NumberField{{Pick: 6, Of: 45}}}
+        wheel, err := rules.BuildWheel([][]int{{8, 1, 5, 12, 20, 33, 41, 44}}, nil)
+        if err != nil {
+                t.Fatalf("BuildWheel: %v", err)
+        }
+        if wheel.Type != WheelTypeFull || wheel.Lines != 28 || wheel.FullLines != 28 || len(wheel.Combinations) != 28 {
+                t.Fatalf("Полная система 8 из 45: ожидается 28 комбинаций, получено %d (%d)", wheel.Lines, len(wheel.Combinations))
+        }
+        if wheel.Guarantee != (WheelGuarantee{Match: 6, If: 6}) || wheel.Proof.Method != "construction" {
+                t.Errorf("Некорректная гарантия полной системы: %+v, %+v", wheel.Guarantee, wheel.Proof)
+        }
+        seen := make(map[string]bool)
+        for _, combination := range wheel.Combinations {
+                if err := rules.ValidatePicks(combination); err != nil {
+                        t.Fatalf("Комбинация %v не по правилам: %v", combination, err)
+                }
+                seen[fmt.Sprint(combination)] = true
+        }
+        if len(seen) != 28 {
+                t.Errorf("Комбинации полной системы должны быть разными, уникальных: %d", len(seen))
+        }
+
+        twoFields := GameRules{Fields: []NumberField{{Pick: 5, Of: 36}, {Pick: 1, Of: 4}}}
+        wheel, err = twoFields.BuildWheel([][]int{{1, 2, 3, 4, 5, 6, 7}, {4, 2}}, nil)
+        if err != nil {
+                t.Fatalf("BuildWheel: %v", err)
+        }
+        if wheel.Lines != 42 || len(wheel.Combinations) != 42 {
+                t.Errorf("5 из 36 + 1 из 4: ожидается 21*2 = 42 комбинации, получено %d", wheel.Lines)
+        }
+        if last := wheel.Combinations[len(wheel.Combinations)-1]; len(last) != 2 || last[1][0] != 4 {
+                t.Errorf("Второе поле должно перебираться полностью, последняя комбинация %v", last)
+        }
+}
+
+// TestBuildAbbreviatedWheel проверяет, что сокращенная система короче полной и выполняет гарантию
+func TestBuildAbbreviatedWheel(t *testing.T) {
+        rules := GameRules{Fields: []NumberField{{Pick: 6, Of: 45}}}
+        pool := []int{3, 7, 11, 15, 19, 23, 27, 31, 35, 39}
+
+        wheel, err := rules.BuildWheel([][]int{pool}, &WheelGuarantee{Match: 3, If: 4})
+        if err != nil {
+                t.Fatalf("BuildWheel: %v", err)
+        }
+        if wheel.Type != WheelTypeAbbreviated || wheel.FullLines != 210 || wheel.Lines >= 50 || wheel.Lines != len(wheel.Combinations) {
+                t.Fatalf("Сокращенная система 3 из 4 на 10 чисел должна быть намного короче полной (210), получено %d", wheel.Lines)
+        }
+        if wheel.Proof.Method != "exhaustive" || wheel.Proof.Cases != 210 || wheel.Proof.WorstMatch < 3 {
+                t.Errorf("Некорректное доказательство: %+v", wheel.Proof)
+        }
+
+        // Независимая проверка: любые 4 числа пула совпадают хотя бы с одной комбинацией в 3 числах
+        for _, mask := range subsetMasks(len(pool), 4) {
+                drawn := make(map[int]bool)
+                for _, number := range maskNumbers(mask, pool) {
+                        drawn[number] = true
+                }
+                best := 0
+                for _, combination := range wheel.Combinations {
+                        matched := 0
+                        for _, number := range combination[0] {
+                                if drawn[number] {
+                                        matched++
+                                }
+                        }
+                        if matched > best {
+                                best = matched
+                        }
+                }
+                if best < 3 {
+                        t.Fatalf("Гарантия не выполняется для %v: лучшее совпадение %d", maskNumbers(mask, pool), best)
+                }
+        }
+}
+
+// TestBuildWheelErrors проверяет отказ для некорректных пулов и недостижимых гарантий
+func TestBuildWheelErrors(t *testing.T) {
+        rules := GameRules{Fields: []NumberField{{Pick: 6, Of: 45}}}
+        large := make([]int, 0, 20)
+        for n := 1; n <= 20; n++ {
+                large = append(large, n)
+        }
+
+        tests := []struct {
+                name      string
+                pool      [][]int
+                guarantee *WheelGuarantee
+        }{
+                {"мало чисел", [][]int{{1, 2, 3, 4, 5}}, nil},
+                {"повтор", [][]int{{1, 2, 3, 4, 5, 5, 7}}, nil},
+                {"вне поля", [][]int{{1, 2, 3, 4, 5, 46}}, nil},
+                {"лишнее поле", [][]int{{1, 2, 3, 4, 5, 6}, {1}}, nil},
+                {"полная система слишком велика", [][]int{large}, nil},
+                {"угадать больше, чем выпало", [][]int{{1, 2, 3, 4, 5, 6, 7, 8}}, &WheelGuarantee{Match: 4, If: 3}},
+                {"выпало больше, чем в пуле", [][]int{{1, 2, 3, 4, 5, 6, 7}}, &WheelGuarantee{Match: 3, If: 8}},
+                {"пул слишком велик для гарантии", [][]int{large}, &WheelGuarantee{Match: 5, If: 6}},
+        }
+        for _, tt := range tests {
+                if _, err := rules.BuildWheel(tt.pool, tt.guarantee); err == nil {
+                        t.Errorf("%s: ожидается ошибка", tt.name)
+                }
+        }
+}
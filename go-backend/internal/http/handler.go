@@ -1,14 +1,20 @@
 package http
 
 import (
+        "context"
         "encoding/json"
+        "errors"
         "fmt"
         "net/http"
+        "strconv"
+        "strings"
+        "time"
 
         "github.com/go-chi/chi/v5"
         "github.com/go-playground/validator/v10"
 
         "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
         "github.com/stoloto-recommendations/backend/internal/service"
 )
 
@@ -16,6 +22,10 @@ import (
 type Handler struct {
         stolotoService        *service.StolotoService
         recommendationService *service.RecommendationService
+        drawService           *service.DrawService
+        statsService          *service.StatsService
+        walletService         *service.WalletService
+        simulationService     *service.SimulationService
         validate              *validator.Validate
 }
 
@@ -23,15 +33,34 @@ type Handler struct {
 func NewHandler(
         stolotoService *service.StolotoService,
         recommendationService *service.RecommendationService,
+        drawService *service.DrawService,
+        statsService *service.StatsService,
+        walletService *service.WalletService,
+        simulationService *service.SimulationService,
         validate *validator.Validate,
 ) *Handler {
         return &Handler{
                 stolotoService:        stolotoService,
                 recommendationService: recommendationService,
+                drawService:           drawService,
+                statsService:          statsService,
+                walletService:         walletService,
+                simulationService:     simulationService,
                 validate:              validate,
         }
 }
 
+const (
+        maxStatsWindows  = 5     // Максимум окон горячих/холодных чисел в одном запросе
+        maxStatsTop      = 50    // Максимум чисел, пар и троек в ответе статистики
+        maxValueDraws    = 1000  // Максимум тиражей для оценки числа победителей в EV
+        maxCalendarDraws = 500   // Максимум тиражей каждой лотереи в календаре
+        maxOddsTickets   = 1000  // Максимум билетов в тираже в расчете шансов
+        maxOddsDraws     = 10000 // Максимум тиражей в расчете шансов
+
+        userIDHeader = "X-User-ID" // Заголовок с идентификатором пользователя (stoloto_user_id клиента)
+)
+
 // ErrorResponse представляет ответ с ошибкой
 type ErrorResponse struct {
         Error   string `json:"error"`
@@ -55,24 +84,44 @@ func RespondWithError(w http.ResponseWriter, code int, message string) {
         })
 }
 
+// setProvenanceHeaders сообщает клиенту, откуда получены данные о лотереях
+// X-Data-Source: live, stale, snapshot или mock; X-Data-Fetched-At: время получения (RFC 3339)
+func setProvenanceHeaders(w http.ResponseWriter, provenance domain.DataProvenance) {
+        w.Header().Set("X-Data-Source", string(provenance.Source))
+        if provenance.FetchedAt != nil {
+                w.Header().Set("X-Data-Fetched-At", provenance.FetchedAt.UTC().Format(time.RFC3339))
+        }
+}
+
 // HealthCheck проверяет статус сервера
+// Если circuit breaker StolotoAPI разомкнут, статус "degraded": сервер работает на резервных данных
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-        RespondWithJSON(w, http.StatusOK, map[string]string{
+        response := map[string]interface{}{
                 "status":  "ok",
                 "service": "stoloto-recommendations-backend",
-        })
+        }
+
+        if upstream := h.stolotoService.UpstreamStatus(); upstream != nil {
+                response["stolotoApi"] = upstream
+                if upstream.State != repository.BreakerClosed {
+                        response["status"] = "degraded"
+                }
+        }
+
+        RespondWithJSON(w, http.StatusOK, response)
 }
 
 // GetAllLotteries возвращает все доступные лотереи
 func (h *Handler) GetAllLotteries(w http.ResponseWriter, r *http.Request) {
         ctx := r.Context()
 
-        lotteries, err := h.stolotoService.GetAllLotteries(ctx)
+        lotteries, provenance, err := h.stolotoService.GetAllLotteriesWithProvenance(ctx)
         if err != nil {
                 RespondWithError(w, http.StatusInternalServerError, "Ошибка получения списка лотерей")
                 return
         }
 
+        setProvenanceHeaders(w, provenance)
         RespondWithJSON(w, http.StatusOK, lotteries)
 }
 
@@ -113,7 +162,7 @@ func (h *Handler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
         }
 
         // Получаем все активные лотереи
-        allLotteries, err := h.stolotoService.GetActiveLotteries(ctx)
+        allLotteries, provenance, err := h.stolotoService.GetActiveLotteriesWithProvenance(ctx)
         if err != nil {
                 RespondWithError(w, http.StatusInternalServerError, "Ошибка получения данных о лотереях")
                 return
@@ -125,6 +174,9 @@ func (h *Handler) GetRecommendations(w http.ResponseWriter, r *http.Request) {
                 RespondWithError(w, http.StatusInternalServerError, "Ошибка генерации рекомендаций")
                 return
         }
+        recommendations.Provenance = &provenance
+
+        setProvenanceHeaders(w, provenance)
 
         RespondWithJSON(w, http.StatusOK, recommendations)
 }
@@ -154,3 +206,576 @@ func (h *Handler) FilterLotteries(w http.ResponseWriter, r *http.Request) {
 
         RespondWithJSON(w, http.StatusOK, lotteries)
 }
+
+// GetLatestDraw возвращает результат последнего тиража лотереи
+func (h *Handler) GetLatestDraw(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        draw, provenance, err := h.drawService.GetLatestDraw(r.Context(), id)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        setProvenanceHeaders(w, provenance)
+        RespondWithJSON(w, http.StatusOK, draw)
+}
+
+// GetDrawByNumber возвращает результат тиража лотереи по номеру
+func (h *Handler) GetDrawByNumber(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        number, err := strconv.Atoi(chi.URLParam(r, "number"))
+        if err != nil || number <= 0 {
+                RespondWithError(w, http.StatusBadRequest, "Номер тиража должен быть положительным целым числом")
+                return
+        }
+
+        draw, provenance, err := h.drawService.GetDraw(r.Context(), id, number)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        setProvenanceHeaders(w, provenance)
+        RespondWithJSON(w, http.StatusOK, draw)
+}
+
+// ListDraws возвращает страницу истории тиражей лотереи (?offset=0&limit=20)
+func (h *Handler) ListDraws(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        offset, err := queryInt(r, "offset", 0)
+        if err != nil || offset < 0 {
+                RespondWithError(w, http.StatusBadRequest, "Параметр offset должен быть неотрицательным целым числом")
+                return
+        }
+        limit, err := queryInt(r, "limit", 0)
+        if err != nil || limit < 0 {
+                RespondWithError(w, http.StatusBadRequest, "Параметр limit должен быть неотрицательным целым числом")
+                return
+        }
+
+        page, provenance, err := h.drawService.ListDraws(r.Context(), id, offset, limit)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        setProvenanceHeaders(w, provenance)
+        RespondWithJSON(w, http.StatusOK, page)
+}
+
+// GetNumberStats возвращает статистику чисел лотереи по архиву тиражей (?windows=20,100&top=10)
+func (h *Handler) GetNumberStats(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        options := service.DefaultStatsOptions()
+        if value := r.URL.Query().Get("windows"); value != "" {
+                options.Windows = nil
+                for _, part := range strings.Split(value, ",") {
+                        window, err := strconv.Atoi(strings.TrimSpace(part))
+                        if err != nil || window <= 0 {
+                                RespondWithError(w, http.StatusBadRequest, "Параметр windows должен быть списком положительных чисел через запятую")
+                                return
+                        }
+                        options.Windows = append(options.Windows, window)
+                }
+                if len(options.Windows) > maxStatsWindows {
+                        RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Можно запросить не больше %d окон", maxStatsWindows))
+                        return
+                }
+        }
+
+        top, err := queryInt(r, "top", options.Top)
+        if err != nil || top <= 0 || top > maxStatsTop {
+                RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Параметр top должен быть от 1 до %d", maxStatsTop))
+                return
+        }
+        options.Top = top
+
+        stats, err := h.statsService.GetNumberStats(r.Context(), id, options)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, stats)
+}
+
+// GetExpectedValue возвращает простой EV лотереи и EV с учетом дележа призов
+// GET /api/lotteries/{id}/expected-value?draws=
+func (h *Handler) GetExpectedValue(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        draws, err := queryInt(r, "draws", service.DefaultValueWindow)
+        if err != nil || draws <= 0 || draws > maxValueDraws {
+                RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Параметр draws должен быть от 1 до %d", maxValueDraws))
+                return
+        }
+
+        report, err := h.statsService.GetExpectedValueReport(r.Context(), id, draws)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, report)
+}
+
+// GetCumulativeOdds возвращает шансы выиграть в каждой категории за несколько тиражей
+func (h *Handler) GetCumulativeOdds(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        tickets, err := queryInt(r, "tickets", service.DefaultOddsTickets)
+        if err != nil || tickets <= 0 || tickets > maxOddsTickets {
+                RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Параметр tickets должен быть от 1 до %d", maxOddsTickets))
+                return
+        }
+        draws, err := queryInt(r, "draws", service.DefaultOddsDraws)
+        if err != nil || draws <= 0 || draws > maxOddsDraws {
+                RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Параметр draws должен быть от 1 до %d", maxOddsDraws))
+                return
+        }
+
+        odds, err := h.stolotoService.GetCumulativeOdds(r.Context(), id, tickets, draws)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, odds)
+}
+
+// CheckTicket проверяет комбинации билета по результатам тиража
+func (h *Handler) CheckTicket(w http.ResponseWriter, r *http.Request) {
+        var request domain.TicketCheckRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+
+        result, provenance, err := h.drawService.CheckTicket(r.Context(), request)
+        if err != nil {
+                respondWithDrawError(w, request.LotteryID, err)
+                return
+        }
+
+        setProvenanceHeaders(w, provenance)
+        RespondWithJSON(w, http.StatusOK, result)
+}
+
+// GeneratePicks генерирует комбинации для лотереи выбранной стратегией
+func (h *Handler) GeneratePicks(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        var request domain.PicksRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+
+        result, err := h.statsService.GeneratePicks(r.Context(), id, request)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, result)
+}
+
+// BuildWheel строит полную или сокращенную систему из пула чисел
+func (h *Handler) BuildWheel(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        var request domain.WheelRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+
+        wheel, err := h.stolotoService.BuildWheel(r.Context(), id, request)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, wheel)
+}
+
+// Backtest проверяет комбинации по истории тиражей архива
+func (h *Handler) Backtest(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        var request domain.BacktestRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+        if request.ToNumber > 0 && request.FromNumber > request.ToNumber {
+                RespondWithError(w, http.StatusBadRequest, "fromNumber не может быть больше toNumber")
+                return
+        }
+        if request.From != "" && request.To != "" && request.From > request.To {
+                RespondWithError(w, http.StatusBadRequest, "Дата from не может быть позже даты to")
+                return
+        }
+
+        result, err := h.drawService.Backtest(r.Context(), id, request)
+        if err != nil {
+                respondWithDrawError(w, id, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, result)
+}
+
+// Simulate моделирует результаты плана игры методом Монте-Карло
+func (h *Handler) Simulate(w http.ResponseWriter, r *http.Request) {
+        var request domain.SimulationRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+
+        result, err := h.simulationService.Simulate(r.Context(), request)
+        switch {
+        case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+                RespondWithError(w, http.StatusServiceUnavailable, "Моделирование прервано")
+                return
+        case errors.Is(err, service.ErrLotteryNotFound), errors.Is(err, service.ErrOddsUnavailable):
+                // Текст ошибки содержит ID лотереи плана
+                RespondWithError(w, http.StatusNotFound, err.Error())
+                return
+        case err != nil:
+                respondWithDrawError(w, "", err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, result)
+}
+
+// ListWalletTickets возвращает билеты кошелька пользователя
+// (?lotteryId=&status=&from=&to=&offset=0&limit=20, даты в формате ГГГГ-ММ-ДД по МСК)
+func (h *Handler) ListWalletTickets(w http.ResponseWriter, r *http.Request) {
+        userID, ok := requireUserID(w, r)
+        if !ok {
+                return
+        }
+
+        query := r.URL.Query()
+        filter := domain.WalletFilter{
+                LotteryID: query.Get("lotteryId"),
+                Status:    domain.TicketStatus(query.Get("status")),
+        }
+        switch filter.Status {
+        case "", domain.TicketStatusPending, domain.TicketStatusWon, domain.TicketStatusLost:
+        default:
+                RespondWithError(w, http.StatusBadRequest, "Параметр status должен быть pending, won или lost")
+                return
+        }
+
+        var err error
+        if filter.From, err = queryDate(r, "from"); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Параметр from должен быть датой в формате ГГГГ-ММ-ДД")
+                return
+        }
+        if filter.To, err = queryDate(r, "to"); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Параметр to должен быть датой в формате ГГГГ-ММ-ДД")
+                return
+        }
+        if !filter.To.IsZero() {
+                filter.To = filter.To.AddDate(0, 0, 1) // Дата to включительно
+        }
+
+        filter.Offset, err = queryInt(r, "offset", 0)
+        if err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Параметр offset должен быть целым числом")
+                return
+        }
+        filter.Limit, err = queryInt(r, "limit", 0)
+        if err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Параметр limit должен быть целым числом")
+                return
+        }
+
+        page, err := h.walletService.ListTickets(r.Context(), userID, filter)
+        if err != nil {
+                respondWithWalletError(w, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, page)
+}
+
+// GetWalletSummary возвращает статистику выигрышей и проигрышей по кошельку пользователя
+func (h *Handler) GetWalletSummary(w http.ResponseWriter, r *http.Request) {
+        userID, ok := requireUserID(w, r)
+        if !ok {
+                return
+        }
+
+        summary, err := h.walletService.GetSummary(r.Context(), userID)
+        if err != nil {
+                respondWithWalletError(w, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, summary)
+}
+
+// AddWalletTicket сохраняет билет в кошелек пользователя
+func (h *Handler) AddWalletTicket(w http.ResponseWriter, r *http.Request) {
+        userID, ok := requireUserID(w, r)
+        if !ok {
+                return
+        }
+
+        var request domain.WalletTicketRequest
+        if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный формат запроса")
+                return
+        }
+
+        if err := h.validate.Struct(request); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Ошибка валидации: "+err.Error())
+                return
+        }
+
+        ticket, err := h.walletService.AddTicket(r.Context(), userID, request)
+        if err != nil {
+                respondWithWalletError(w, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusCreated, ticket)
+}
+
+// GetWalletTicket возвращает билет кошелька пользователя
+func (h *Handler) GetWalletTicket(w http.ResponseWriter, r *http.Request) {
+        userID, ok := requireUserID(w, r)
+        if !ok {
+                return
+        }
+
+        ticket, err := h.walletService.GetTicket(r.Context(), userID, chi.URLParam(r, "ticketId"))
+        if err != nil {
+                respondWithWalletError(w, err)
+                return
+        }
+
+        RespondWithJSON(w, http.StatusOK, ticket)
+}
+
+// DeleteWalletTicket удаляет билет из кошелька пользователя
+func (h *Handler) DeleteWalletTicket(w http.ResponseWriter, r *http.Request) {
+        userID, ok := requireUserID(w, r)
+        if !ok {
+                return
+        }
+
+        if err := h.walletService.DeleteTicket(r.Context(), userID, chi.URLParam(r, "ticketId")); err != nil {
+                respondWithWalletError(w, err)
+                return
+        }
+
+        w.WriteHeader(http.StatusNoContent)
+}
+
+// requireUserID читает идентификатор пользователя из заголовка X-User-ID
+func requireUserID(w http.ResponseWriter, r *http.Request) (string, bool) {
+        userID := r.Header.Get(userIDHeader)
+        if userID == "" {
+                RespondWithError(w, http.StatusBadRequest, "Не указан заголовок "+userIDHeader)
+                return "", false
+        }
+        if err := repository.ValidateUserID(userID); err != nil {
+                RespondWithError(w, http.StatusBadRequest, "Некорректный заголовок "+userIDHeader)
+                return "", false
+        }
+        return userID, true
+}
+
+// respondWithWalletError переводит ошибки WalletService в HTTP статусы
+func respondWithWalletError(w http.ResponseWriter, err error) {
+        switch {
+        case errors.Is(err, repository.ErrTicketNotFound):
+                RespondWithError(w, http.StatusNotFound, "Билет не найден")
+        case errors.Is(err, repository.ErrInvalidUserID):
+                RespondWithError(w, http.StatusBadRequest, "Некорректный заголовок "+userIDHeader)
+        case errors.Is(err, service.ErrLotteryNotFound):
+                RespondWithError(w, http.StatusNotFound, "Лотерея не найдена")
+        case errors.Is(err, service.ErrRulesUnavailable):
+                RespondWithError(w, http.StatusNotFound, "Правила игры лотереи неизвестны, билеты этой лотереи не поддерживаются")
+        case errors.Is(err, service.ErrInvalidTicket):
+                RespondWithError(w, http.StatusBadRequest, err.Error())
+        default:
+                RespondWithError(w, http.StatusInternalServerError, "Ошибка работы с кошельком")
+        }
+}
+
+// GetLotteryCalendar отдает календарь iCalendar с ближайшими тиражами лотереи
+func (h *Handler) GetLotteryCalendar(w http.ResponseWriter, r *http.Request) {
+        id := chi.URLParam(r, "id")
+        if id == "" {
+                RespondWithError(w, http.StatusBadRequest, "ID лотереи не указан")
+                return
+        }
+
+        draws, ok := calendarDraws(w, r)
+        if !ok {
+                return
+        }
+
+        calendar, err := h.stolotoService.GetLotteryCalendar(r.Context(), id, draws)
+        switch {
+        case errors.Is(err, service.ErrLotteryNotFound):
+                RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Лотерея с ID %s не найдена", id))
+                return
+        case errors.Is(err, service.ErrScheduleUnavailable):
+                RespondWithError(w, http.StatusNotFound, "Расписание тиражей лотереи неизвестно")
+                return
+        case err != nil:
+                RespondWithError(w, http.StatusInternalServerError, "Ошибка формирования календаря")
+                return
+        }
+
+        respondWithCalendar(w, id+".ics", calendar)
+}
+
+// GetCalendar отдает календарь iCalendar с ближайшими тиражами всех лотерей
+func (h *Handler) GetCalendar(w http.ResponseWriter, r *http.Request) {
+        draws, ok := calendarDraws(w, r)
+        if !ok {
+                return
+        }
+
+        calendar, err := h.stolotoService.GetCalendar(r.Context(), draws)
+        if err != nil {
+                RespondWithError(w, http.StatusInternalServerError, "Ошибка формирования календаря")
+                return
+        }
+
+        respondWithCalendar(w, "lotteries.ics", calendar)
+}
+
+// calendarDraws читает параметр draws - сколько ближайших тиражей каждой лотереи выгрузить
+func calendarDraws(w http.ResponseWriter, r *http.Request) (int, bool) {
+        draws, err := queryInt(r, "draws", service.DefaultCalendarDraws)
+        if err != nil || draws <= 0 || draws > maxCalendarDraws {
+                RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Параметр draws должен быть от 1 до %d", maxCalendarDraws))
+                return 0, false
+        }
+        return draws, true
+}
+
+// respondWithCalendar отправляет календарь iCalendar
+func respondWithCalendar(w http.ResponseWriter, filename string, calendar []byte) {
+        w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+        w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+        w.WriteHeader(http.StatusOK)
+        w.Write(calendar)
+}
+
+// respondWithDrawError переводит ошибки DrawService и StatsService в HTTP статусы
+func respondWithDrawError(w http.ResponseWriter, lotteryID string, err error) {
+        switch {
+        case errors.Is(err, service.ErrLotteryNotFound):
+                RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Лотерея с ID %s не найдена", lotteryID))
+        case errors.Is(err, service.ErrDrawNotFound):
+                RespondWithError(w, http.StatusNotFound, "Тираж не найден")
+        case errors.Is(err, service.ErrDrawsUnavailable):
+                RespondWithError(w, http.StatusServiceUnavailable, "Результаты тиражей временно недоступны, попробуйте позже")
+        case errors.Is(err, service.ErrOddsUnavailable):
+                RespondWithError(w, http.StatusNotFound, "Вероятности выигрыша для лотереи неизвестны")
+        case errors.Is(err, service.ErrRulesUnavailable):
+                RespondWithError(w, http.StatusNotFound, "Правила игры лотереи неизвестны, проверка билетов, генерация комбинаций и системы недоступны")
+        case errors.Is(err, service.ErrInvalidTicket), errors.Is(err, service.ErrInvalidWheel), errors.Is(err, service.ErrInvalidPicks):
+                RespondWithError(w, http.StatusBadRequest, err.Error())
+        case errors.Is(err, service.ErrDrawPending):
+                RespondWithError(w, http.StatusConflict, "Результаты тиража еще не известны")
+        case errors.Is(err, service.ErrNoDrawHistory):
+                RespondWithError(w, http.StatusServiceUnavailable, "История тиражей лотереи еще не собрана, попробуйте позже")
+        default:
+                RespondWithError(w, http.StatusInternalServerError, "Ошибка получения результатов тиража")
+        }
+}
+
+// queryInt читает целочисленный query-параметр или возвращает значение по умолчанию
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+        value := r.URL.Query().Get(key)
+        if value == "" {
+                return fallback, nil
+        }
+        return strconv.Atoi(value)
+}
+
+// queryDate читает query-параметр с датой ГГГГ-ММ-ДД (начало дня по МСК) или возвращает нулевое время
+func queryDate(r *http.Request, key string) (time.Time, error) {
+        value := r.URL.Query().Get(key)
+        if value == "" {
+                return time.Time{}, nil
+        }
+        return time.ParseInLocation("2006-01-02", value, domain.MoscowTime)
+}
@@ -15,6 +15,43 @@ func RegisterRoutes(r chi.Router, h *Handler) {
                 r.Route("/lotteries", func(r chi.Router) {
                         r.Get("/", h.GetAllLotteries)    // GET /api/lotteries - все лотереи
                         r.Get("/{id}", h.GetLotteryByID) // GET /api/lotteries/{id} - конкретная лотерея
+
+                        // Результаты тиражей
+                        r.Get("/{id}/draws", h.ListDraws)                // GET /api/lotteries/{id}/draws?offset=&limit= - история тиражей
+                        r.Get("/{id}/draws/latest", h.GetLatestDraw)     // GET /api/lotteries/{id}/draws/latest - последний тираж
+                        r.Get("/{id}/draws/{number}", h.GetDrawByNumber) // GET /api/lotteries/{id}/draws/{number} - тираж по номеру
+
+                        // Статистика чисел и проверка комбинаций по архиву тиражей
+                        r.Get("/{id}/stats", h.GetNumberStats) // GET /api/lotteries/{id}/stats?windows=&top= - частоты, паузы, пары
+                        r.Post("/{id}/backtest", h.Backtest)   // POST /api/lotteries/{id}/backtest - комбинации на истории тиражей
+
+                        // Генерация комбинаций и систем
+                        r.Post("/{id}/picks", h.GeneratePicks) // POST /api/lotteries/{id}/picks - комбинации по стратегии
+                        r.Post("/{id}/wheels", h.BuildWheel)   // POST /api/lotteries/{id}/wheels - полная или сокращенная система
+
+                        // Ожидаемый выигрыш с учетом дележа призов
+                        r.Get("/{id}/expected-value", h.GetExpectedValue) // GET /api/lotteries/{id}/expected-value?draws= - простой и скорректированный EV
+
+                        // Шансы выигрыша за несколько тиражей
+                        r.Get("/{id}/odds", h.GetCumulativeOdds) // GET /api/lotteries/{id}/odds?tickets=&draws= - шансы и ожидаемые затраты до первого приза
+
+                        // Расписание тиражей
+                        r.Get("/{id}/calendar.ics", h.GetLotteryCalendar) // GET /api/lotteries/{id}/calendar.ics?draws= - календарь ближайших тиражей
+                })
+
+                // Календарь ближайших тиражей всех лотерей
+                r.Get("/calendar.ics", h.GetCalendar) // GET /api/calendar.ics?draws=
+
+                // Проверка билетов
+                r.Post("/tickets/check", h.CheckTicket) // POST /api/tickets/check - проверка комбинаций по результатам тиража
+
+                // Кошелек билетов пользователя (заголовок X-User-ID)
+                r.Route("/wallet", func(r chi.Router) {
+                        r.Get("/", h.ListWalletTickets)               // GET /api/wallet?lotteryId=&status=&from=&to=&offset=&limit= - билеты
+                        r.Post("/", h.AddWalletTicket)                // POST /api/wallet - сохранить билет
+                        r.Get("/summary", h.GetWalletSummary)         // GET /api/wallet/summary - выигрыши и проигрыши
+                        r.Get("/{ticketId}", h.GetWalletTicket)       // GET /api/wallet/{ticketId} - билет с результатами
+                        r.Delete("/{ticketId}", h.DeleteWalletTicket) // DELETE /api/wallet/{ticketId} - удалить билет
                 })
 
                 // Рекомендации
@@ -22,6 +59,9 @@ func RegisterRoutes(r chi.Router, h *Handler) {
                         r.Post("/", h.GetRecommendations) // POST /api/recommendations - получить рекомендации
                 })
 
+                // Моделирование плана игры
+                r.Post("/simulations", h.Simulate) // POST /api/simulations - распределение результата методом Монте-Карло
+
                 // Фильтрация
                 r.Post("/filter", h.FilterLotteries) // POST /api/filter - фильтр лотерей
         })
@@ -0,0 +1,220 @@
+package repository
+
+import (
+        "errors"
+        "sync"
+        "time"
+)
+
+// ErrCircuitOpen возвращается, когда выключатель разомкнут и запросы к StolotoAPI не выполняются
+var ErrCircuitOpen = errors.New("circuit breaker разомкнут: StolotoAPI временно недоступен")
+
+// BreakerState представляет состояние circuit breaker
+type BreakerState string
+
+const (
+        BreakerClosed   BreakerState = "closed"    // Запросы проходят, ошибки считаются
+        BreakerOpen     BreakerState = "open"      // Запросы сразу отклоняются до истечения cool-down
+        BreakerHalfOpen BreakerState = "half-open" // Пропускается пробный запрос
+)
+
+// CircuitBreakerConfig задает пороги срабатывания circuit breaker
+type CircuitBreakerConfig struct {
+        WindowSize          int           // Сколько последних запросов учитывать
+        MinRequests         int           // Минимум запросов в окне, прежде чем выключатель может разомкнуться
+        FailureRatio        float64       // Доля ошибок в окне, при которой выключатель размыкается
+        CoolDown            time.Duration // Сколько ждать перед пробным запросом
+        HalfOpenMaxRequests int           // Сколько пробных запросов одновременно пропускать в half-open
+}
+
+// DefaultCircuitBreakerConfig возвращает настройки по умолчанию
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+        return CircuitBreakerConfig{
+                WindowSize:          20,
+                MinRequests:         5,
+                FailureRatio:        0.5,
+                CoolDown:            30 * time.Second,
+                HalfOpenMaxRequests: 1,
+        }
+}
+
+// BreakerSnapshot - состояние circuit breaker для health endpoint
+type BreakerSnapshot struct {
+        State        BreakerState `json:"state"`
+        Requests     int          `json:"requests"`     // Запросов в текущем окне
+        Failures     int          `json:"failures"`     // Ошибок в текущем окне
+        FailureRatio float64      `json:"failureRatio"` // Доля ошибок в текущем окне
+        OpenedAt     *time.Time   `json:"openedAt,omitempty"`
+        RetryAt      *time.Time   `json:"retryAt,omitempty"` // Когда будет пропущен пробный запрос
+}
+
+// CircuitBreaker защищает StolotoAPI (и наших пользователей) от запросов к недоступному upstream
+// closed -> open: доля ошибок в окне достигла FailureRatio
+// open -> half-open: прошел CoolDown
+// half-open -> closed: пробный запрос успешен; half-open -> open: пробный запрос неудачен
+type CircuitBreaker struct {
+        mu               sync.Mutex
+        config           CircuitBreakerConfig
+        state            BreakerState
+        window           []bool // true - ошибка; кольцевой буфер последних результатов
+        next             int
+        filled           int
+        openedAt         time.Time
+        halfOpenInFlight int
+        now              func() time.Time
+}
+
+// NewCircuitBreaker создает новый экземпляр CircuitBreaker
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+        defaults := DefaultCircuitBreakerConfig()
+        if config.WindowSize <= 0 {
+                config.WindowSize = defaults.WindowSize
+        }
+        if config.MinRequests <= 0 {
+                config.MinRequests = defaults.MinRequests
+        }
+        if config.MinRequests > config.WindowSize {
+                config.MinRequests = config.WindowSize
+        }
+        if config.FailureRatio <= 0 || config.FailureRatio > 1 {
+                config.FailureRatio = defaults.FailureRatio
+        }
+        if config.CoolDown <= 0 {
+                config.CoolDown = defaults.CoolDown
+        }
+        if config.HalfOpenMaxRequests <= 0 {
+                config.HalfOpenMaxRequests = defaults.HalfOpenMaxRequests
+        }
+
+        return &CircuitBreaker{
+                config: config,
+                state:  BreakerClosed,
+                window: make([]bool, config.WindowSize),
+                now:    time.Now,
+        }
+}
+
+// Allow проверяет, можно ли выполнить запрос
+// Каждый разрешенный запрос должен завершиться вызовом Record или Release
+func (b *CircuitBreaker) Allow() error {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        if b.state == BreakerOpen {
+                if b.now().Sub(b.openedAt) < b.config.CoolDown {
+                        return ErrCircuitOpen
+                }
+                b.state = BreakerHalfOpen
+                b.halfOpenInFlight = 0
+        }
+
+        if b.state == BreakerHalfOpen {
+                if b.halfOpenInFlight >= b.config.HalfOpenMaxRequests {
+                        return ErrCircuitOpen
+                }
+                b.halfOpenInFlight++
+        }
+
+        return nil
+}
+
+// Record учитывает результат разрешенного запроса
+func (b *CircuitBreaker) Record(failed bool) {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        if b.state == BreakerHalfOpen {
+                b.halfOpenInFlight--
+                if failed {
+                        b.open()
+                } else {
+                        b.reset()
+                }
+                return
+        }
+
+        if b.state == BreakerOpen {
+                // Результат запроса, начатого до размыкания - окно уже не важно
+                return
+        }
+
+        b.window[b.next] = failed
+        b.next = (b.next + 1) % len(b.window)
+        if b.filled < len(b.window) {
+                b.filled++
+        }
+
+        if b.filled >= b.config.MinRequests && b.failureRatio() >= b.config.FailureRatio {
+                b.open()
+        }
+}
+
+// Release освобождает слот запроса, результат которого не говорит о здоровье upstream
+// (например, запрос отменен клиентом)
+func (b *CircuitBreaker) Release() {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        if b.state == BreakerHalfOpen && b.halfOpenInFlight > 0 {
+                b.halfOpenInFlight--
+        }
+}
+
+// Snapshot возвращает текущее состояние выключателя
+func (b *CircuitBreaker) Snapshot() BreakerSnapshot {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        snapshot := BreakerSnapshot{
+                State:        b.state,
+                Requests:     b.filled,
+                Failures:     b.failures(),
+                FailureRatio: b.failureRatio(),
+        }
+
+        if b.state != BreakerClosed {
+                openedAt := b.openedAt
+                retryAt := b.openedAt.Add(b.config.CoolDown)
+                snapshot.OpenedAt = &openedAt
+                snapshot.RetryAt = &retryAt
+        }
+
+        return snapshot
+}
+
+// open размыкает выключатель (вызывается под mu)
+func (b *CircuitBreaker) open() {
+        b.state = BreakerOpen
+        b.openedAt = b.now()
+        b.halfOpenInFlight = 0
+}
+
+// reset замыкает выключатель и очищает окно (вызывается под mu)
+func (b *CircuitBreaker) reset() {
+        b.state = BreakerClosed
+        b.next = 0
+        b.filled = 0
+        b.halfOpenInFlight = 0
+        for i := range b.window {
+                b.window[i] = false
+        }
+}
+
+// failures считает ошибки в окне (вызывается под mu)
+func (b *CircuitBreaker) failures() int {
+        count := 0
+        for i := 0; i < b.filled; i++ {
+                if b.window[i] {
+                        count++
+                }
+        }
+        return count
+}
+
+// failureRatio вычисляет долю ошибок в окне (вызывается под mu)
+func (b *CircuitBreaker) failureRatio() float64 {
+        if b.filled == 0 {
+                return 0
+        }
+        return float64(b.failures()) / float64(b.filled)
+}
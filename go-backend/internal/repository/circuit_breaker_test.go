@@ -0,0 +1,105 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "net/http"
+        "net/http/httptest"
+        "sync/atomic"
+        "testing"
+        "time"
+)
+
+// TestCircuitBreakerTransitions проверяет переходы closed -> open -> half-open -> closed/open
+func TestCircuitBreakerTransitions(t *testing.T) {
+        now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+        breaker := NewCircuitBreaker(CircuitBreakerConfig{
+                WindowSize:   4,
+                MinRequests:  4,
+                FailureRatio: 0.5,
+                CoolDown:     time.Minute,
+        })
+        breaker.now = func() time.Time { return now }
+
+        // 1 ошибка из 3 запросов - окно еще не заполнено до MinRequests
+        for _, failed := range []bool{false, true, false} {
+                if err := breaker.Allow(); err != nil {
+                        t.Fatalf("Замкнутый выключатель должен пропускать запросы: %v", err)
+                }
+                breaker.Record(failed)
+        }
+        if state := breaker.Snapshot().State; state != BreakerClosed {
+                t.Fatalf("Ожидается closed, получено %s", state)
+        }
+
+        // Вторая ошибка: 2 из 4 = 50% - выключатель размыкается
+        breaker.Allow()
+        breaker.Record(true)
+        if state := breaker.Snapshot().State; state != BreakerOpen {
+                t.Fatalf("Ожидается open, получено %s", state)
+        }
+        if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+                t.Fatalf("Разомкнутый выключатель должен отклонять запросы, получено %v", err)
+        }
+
+        // После cool-down пропускается ровно один пробный запрос
+        now = now.Add(time.Minute)
+        if err := breaker.Allow(); err != nil {
+                t.Fatalf("После cool-down должен пройти пробный запрос: %v", err)
+        }
+        if err := breaker.Allow(); !errors.Is(err, ErrCircuitOpen) {
+                t.Fatal("В half-open должен проходить только один пробный запрос")
+        }
+
+        // Неудачный пробный запрос снова размыкает выключатель
+        breaker.Record(true)
+        if state := breaker.Snapshot().State; state != BreakerOpen {
+                t.Fatalf("После неудачной пробы ожидается open, получено %s", state)
+        }
+
+        // Успешный пробный запрос замыкает выключатель и очищает окно
+        now = now.Add(time.Minute)
+        breaker.Allow()
+        breaker.Record(false)
+        snapshot := breaker.Snapshot()
+        if snapshot.State != BreakerClosed || snapshot.Requests != 0 {
+                t.Fatalf("После успешной пробы ожидается чистый closed, получено %+v", snapshot)
+        }
+}
+
+// TestClientSkipsUpstreamWhenBreakerOpen проверяет что при разомкнутом выключателе запросы не уходят в upstream
+func TestClientSkipsUpstreamWhenBreakerOpen(t *testing.T) {
+        var calls int32
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                atomic.AddInt32(&calls, 1)
+                w.WriteHeader(http.StatusServiceUnavailable)
+        }))
+        defer server.Close()
+
+        client := NewStolotoClient(server.URL, WithCircuitBreaker(CircuitBreakerConfig{
+                WindowSize:   2,
+                MinRequests:  2,
+                FailureRatio: 1,
+                CoolDown:     time.Hour,
+        }))
+
+        ctx := context.Background()
+        for i := 0; i < 2; i++ {
+                client.GetLatestDraw(ctx, "6x45")
+        }
+
+        start := time.Now()
+        _, err := client.GetAllDraws(ctx)
+        if !errors.Is(err, ErrCircuitOpen) {
+                t.Fatalf("Ожидается ErrCircuitOpen, получено %v", err)
+        }
+        if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+                t.Errorf("При разомкнутом выключателе запрос не должен ждать повторов, прошло %v", elapsed)
+        }
+        if atomic.LoadInt32(&calls) != 2 {
+                t.Errorf("В upstream должно уйти 2 запроса, ушло %d", calls)
+        }
+        if client.BreakerSnapshot().State != BreakerOpen {
+                t.Errorf("Состояние выключателя должно быть open, получено %s", client.BreakerSnapshot().State)
+        }
+}
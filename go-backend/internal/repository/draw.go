@@ -0,0 +1,243 @@
+package repository
+
+import (
+        "bytes"
+        "encoding/json"
+        "fmt"
+        "log"
+        "sort"
+        "strconv"
+        "strings"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// moscowLocation - часовой пояс, в котором StolotoAPI отдает даты тиражей без смещения
+var moscowLocation = time.FixedZone("MSK", 3*60*60)
+
+// drawDateLayouts - известные форматы поля drawDate в ответах StolotoAPI
+var drawDateLayouts = []string{
+        time.RFC3339,
+        "2006-01-02T15:04:05",
+        "2006-01-02 15:04:05",
+        "2006-01-02T15:04",
+        "02.01.2006 15:04:05",
+        "02.01.2006 15:04",
+        "2006-01-02",
+        "02.01.2006",
+}
+
+// Money представляет денежную сумму в копейках (в таком виде ее отдает StolotoAPI)
+type Money int64
+
+// Kopecks возвращает сумму в копейках
+func (m Money) Kopecks() int64 {
+        return int64(m)
+}
+
+// Roubles возвращает сумму в рублях без округления
+func (m Money) Roubles() float64 {
+        return float64(m) / 100.0
+}
+
+// String форматирует сумму для логов
+func (m Money) String() string {
+        return fmt.Sprintf("%.2f ₽", m.Roubles())
+}
+
+// UnmarshalJSON принимает сумму как числом, так и строкой ("150000")
+func (m *Money) UnmarshalJSON(data []byte) error {
+        data = bytes.TrimSpace(data)
+        if len(data) == 0 || string(data) == "null" {
+                *m = 0
+                return nil
+        }
+
+        raw := string(data)
+        if raw[0] == '"' {
+                unquoted, err := strconv.Unquote(raw)
+                if err != nil {
+                        return fmt.Errorf("некорректная сумма %s: %w", raw, err)
+                }
+                raw = strings.TrimSpace(unquoted)
+                if raw == "" {
+                        *m = 0
+                        return nil
+                }
+        }
+
+        value, err := strconv.ParseFloat(raw, 64)
+        if err != nil {
+                return fmt.Errorf("некорректная сумма %s: %w", raw, err)
+        }
+        *m = Money(value)
+        return nil
+}
+
+// WinnerTier представляет одну призовую категорию тиража
+type WinnerTier struct {
+        Tier    string `json:"tier"`    // Ключ категории как в StolotoAPI ("6", "5+1", "4")
+        Winners int    `json:"winners"` // Количество выигрышных билетов
+        Prize   Money  `json:"prize"`   // Выплата на один выигрышный билет (в копейках)
+}
+
+// WinnerTiers - призовые категории тиража. Набор категорий зависит от игры,
+// поэтому StolotoAPI отдает их объектом: {"6": {"count": 1, "prize": 30000000000}, ...}
+type WinnerTiers []WinnerTier
+
+// rawWinnerTier - значение категории в объекте winners
+type rawWinnerTier struct {
+        Count int   `json:"count"`
+        Prize Money `json:"prize"`
+}
+
+// UnmarshalJSON разбирает объект winners в упорядоченный список категорий
+func (w *WinnerTiers) UnmarshalJSON(data []byte) error {
+        if string(bytes.TrimSpace(data)) == "null" {
+                *w = nil
+                return nil
+        }
+
+        var raw map[string]rawWinnerTier
+        if err := json.Unmarshal(data, &raw); err != nil {
+                return fmt.Errorf("некорректный объект winners: %w", err)
+        }
+
+        tiers := make(WinnerTiers, 0, len(raw))
+        for tier, value := range raw {
+                tiers = append(tiers, WinnerTier{
+                        Tier:    tier,
+                        Winners: value.Count,
+                        Prize:   value.Prize,
+                })
+        }
+        tiers.sort()
+
+        *w = tiers
+        return nil
+}
+
+// MarshalJSON сериализует категории обратно в формат StolotoAPI
+func (w WinnerTiers) MarshalJSON() ([]byte, error) {
+        raw := make(map[string]rawWinnerTier, len(w))
+        for _, tier := range w {
+                raw[tier.Tier] = rawWinnerTier{Count: tier.Winners, Prize: tier.Prize}
+        }
+        return json.Marshal(raw)
+}
+
+// Find возвращает категорию по ключу
+func (w WinnerTiers) Find(tier string) (WinnerTier, bool) {
+        for _, t := range w {
+                if t.Tier == tier {
+                        return t, true
+                }
+        }
+        return WinnerTier{}, false
+}
+
+// TotalWinners возвращает суммарное количество выигрышных билетов
+func (w WinnerTiers) TotalWinners() int {
+        total := 0
+        for _, t := range w {
+                total += t.Winners
+        }
+        return total
+}
+
+// sort упорядочивает категории от главной к младшей: "6", "5+1", "5", "4"...
+func (w WinnerTiers) sort() {
+        sort.Slice(w, func(i, j int) bool {
+                li, lj := tierLeadingNumber(w[i].Tier), tierLeadingNumber(w[j].Tier)
+                if li != lj {
+                        return li > lj
+                }
+                return w[i].Tier > w[j].Tier
+        })
+}
+
+// tierLeadingNumber извлекает число совпадений из начала ключа категории
+func tierLeadingNumber(tier string) int {
+        end := 0
+        for end < len(tier) && tier[end] >= '0' && tier[end] <= '9' {
+                end++
+        }
+        n, err := strconv.Atoi(tier[:end])
+        if err != nil {
+                return -1
+        }
+        return n
+}
+
+// Draw представляет результат розыгрыша из StolotoAPI
+type Draw struct {
+        Number         int         `json:"number"`
+        GameName       string      `json:"gameName,omitempty"`
+        DrawDate       string      `json:"drawDate"`
+        Date           time.Time   `json:"-"` // DrawDate, разобранная в time.Time (нулевая, если формат неизвестен)
+        Status         string      `json:"status"`
+        WinningNumbers []int       `json:"winningNumbers,omitempty"`
+        Jackpot        Money       `json:"jackpot"`           // Суперприз тиража (в копейках)
+        PrizePool      Money       `json:"prizePool"`         // Призовой фонд тиража (в копейках)
+        Winners        WinnerTiers `json:"winners,omitempty"` // Победители по категориям
+}
+
+// UnmarshalJSON декодирует тираж и разбирает дату розыгрыша
+func (d *Draw) UnmarshalJSON(data []byte) error {
+        type drawAlias Draw
+        var alias drawAlias
+        if err := json.Unmarshal(data, &alias); err != nil {
+                return err
+        }
+        *d = Draw(alias)
+
+        if d.DrawDate != "" {
+                date, err := parseDrawDate(d.DrawDate)
+                if err != nil {
+                        log.Printf("[StolotoClient] Draw %d: %v", d.Number, err)
+                }
+                d.Date = date
+        }
+        return nil
+}
+
+// parseDrawDate разбирает дату тиража; даты без смещения считаются московскими
+func parseDrawDate(value string) (time.Time, error) {
+        value = strings.TrimSpace(value)
+        for _, layout := range drawDateLayouts {
+                if date, err := time.ParseInLocation(layout, value, moscowLocation); err == nil {
+                        return date, nil
+                }
+        }
+        return time.Time{}, fmt.Errorf("неизвестный формат даты тиража: %q", value)
+}
+
+// ConvertDrawToResult конвертирует тираж из StolotoAPI в domain.DrawResult
+func ConvertDrawToResult(draw Draw) domain.DrawResult {
+        result := domain.DrawResult{
+                LotteryID:      draw.GameName,
+                Number:         draw.Number,
+                Status:         draw.Status,
+                WinningNumbers: draw.WinningNumbers,
+                Jackpot:        draw.Jackpot.Roubles(),
+                PrizePool:      draw.PrizePool.Roubles(),
+                Winners:        make([]domain.DrawPrizeTier, 0, len(draw.Winners)),
+                TotalWinners:   draw.Winners.TotalWinners(),
+        }
+        if result.WinningNumbers == nil {
+                result.WinningNumbers = []int{}
+        }
+        if !draw.Date.IsZero() {
+                date := draw.Date
+                result.DrawDate = &date
+        }
+        for _, tier := range draw.Winners {
+                result.Winners = append(result.Winners, domain.DrawPrizeTier{
+                        Tier:    tier.Tier,
+                        Winners: tier.Winners,
+                        Prize:   tier.Prize.Roubles(),
+                })
+        }
+        return result
+}
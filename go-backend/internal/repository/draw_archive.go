@@ -0,0 +1,287 @@
+package repository
+
+import (
+        "bufio"
+        "context"
+        "encoding/json"
+        "errors"
+        "fmt"
+        "log"
+        "os"
+        "path/filepath"
+        "regexp"
+        "sort"
+        "sync"
+        "time"
+)
+
+// ErrDrawNotArchived возвращается, когда тиража нет в архиве
+var ErrDrawNotArchived = errors.New("тираж отсутствует в архиве")
+
+// DrawQuery задает выборку тиражей одной игры из архива
+// Нулевые значения границ означают "без ограничения"
+type DrawQuery struct {
+        GameName   string
+        FromNumber int       // Минимальный номер тиража (включительно)
+        ToNumber   int       // Максимальный номер тиража (включительно)
+        From       time.Time // Тиражи, разыгранные не раньше (включительно)
+        To         time.Time // Тиражи, разыгранные раньше (не включительно)
+        Offset     int       // Сколько тиражей пропустить (для пагинации)
+        Limit      int       // Максимум тиражей в ответе (0 - все)
+}
+
+// DrawArchive - локальный архив результатов тиражей
+// Тиражи одной игры уникальны по номеру; повторное сохранение заменяет запись
+type DrawArchive interface {
+        // Save сохраняет тиражи (GameName обязателен)
+        Save(ctx context.Context, draws ...Draw) error
+        // Has проверяет, есть ли тираж в архиве
+        Has(ctx context.Context, gameName string, number int) (bool, error)
+        // Get возвращает тираж по номеру или ErrDrawNotArchived
+        Get(ctx context.Context, gameName string, number int) (*Draw, error)
+        // Query возвращает тиражи игры от новых к старым и общее число подходящих тиражей (без учета Offset/Limit)
+        Query(ctx context.Context, query DrawQuery) ([]Draw, int, error)
+        // Games возвращает игры, по которым в архиве есть тиражи
+        Games(ctx context.Context) ([]string, error)
+}
+
+// gameFileNamePattern - допустимые имена игр для имени файла архива
+var gameFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// FileDrawArchive хранит тиражи в JSONL файлах: по одному файлу на игру (<dir>/<gameName>.jsonl)
+// Файлы только дописываются; при чтении более поздняя запись тиража заменяет более раннюю.
+// Игра целиком загружается в память при первом обращении.
+type FileDrawArchive struct {
+        dir   string
+        mu    sync.RWMutex
+        games map[string]map[int]Draw // Загруженные игры: номер тиража -> тираж
+}
+
+// NewFileDrawArchive создает новый экземпляр FileDrawArchive
+func NewFileDrawArchive(dir string) (*FileDrawArchive, error) {
+        if err := os.MkdirAll(dir, 0o755); err != nil {
+                return nil, fmt.Errorf("ошибка создания каталога архива %s: %w", dir, err)
+        }
+        return &FileDrawArchive{
+                dir:   dir,
+                games: make(map[string]map[int]Draw),
+        }, nil
+}
+
+// Save дописывает тиражи в файлы игр
+func (a *FileDrawArchive) Save(ctx context.Context, draws ...Draw) error {
+        byGame := make(map[string][]Draw)
+        for _, draw := range draws {
+                if err := validateGameName(draw.GameName); err != nil {
+                        return err
+                }
+                byGame[draw.GameName] = append(byGame[draw.GameName], draw)
+        }
+
+        a.mu.Lock()
+        defer a.mu.Unlock()
+
+        for gameName, gameDraws := range byGame {
+                loaded, err := a.loadGame(gameName)
+                if err != nil {
+                        return err
+                }
+                if err := a.appendDraws(gameName, gameDraws); err != nil {
+                        return err
+                }
+                for _, draw := range gameDraws {
+                        loaded[draw.Number] = draw
+                }
+        }
+        return nil
+}
+
+// Has проверяет, есть ли тираж в архиве
+func (a *FileDrawArchive) Has(ctx context.Context, gameName string, number int) (bool, error) {
+        var ok bool
+        err := a.readGame(gameName, func(draws map[int]Draw) {
+                _, ok = draws[number]
+        })
+        return ok, err
+}
+
+// Get возвращает тираж по номеру
+func (a *FileDrawArchive) Get(ctx context.Context, gameName string, number int) (*Draw, error) {
+        var draw Draw
+        var ok bool
+        err := a.readGame(gameName, func(draws map[int]Draw) {
+                draw, ok = draws[number]
+        })
+        if err != nil {
+                return nil, err
+        }
+        if !ok {
+                return nil, ErrDrawNotArchived
+        }
+        return &draw, nil
+}
+
+// Query возвращает тиражи игры от новых к старым
+func (a *FileDrawArchive) Query(ctx context.Context, query DrawQuery) ([]Draw, int, error) {
+        var matched []Draw
+        err := a.readGame(query.GameName, func(draws map[int]Draw) {
+                matched = make([]Draw, 0, len(draws))
+                for _, draw := range draws {
+                        if query.matches(draw) {
+                                matched = append(matched, draw)
+                        }
+                }
+        })
+        if err != nil {
+                return nil, 0, err
+        }
+        sort.Slice(matched, func(i, j int) bool {
+                return matched[i].Number > matched[j].Number
+        })
+
+        total := len(matched)
+        if query.Offset > 0 {
+                if query.Offset >= len(matched) {
+                        return []Draw{}, total, nil
+                }
+                matched = matched[query.Offset:]
+        }
+        if query.Limit > 0 && len(matched) > query.Limit {
+                matched = matched[:query.Limit]
+        }
+        return matched, total, nil
+}
+
+// Games возвращает игры, по которым в архиве есть файлы
+func (a *FileDrawArchive) Games(ctx context.Context) ([]string, error) {
+        files, err := filepath.Glob(filepath.Join(a.dir, "*.jsonl"))
+        if err != nil {
+                return nil, err
+        }
+
+        games := make([]string, 0, len(files))
+        for _, file := range files {
+                name := filepath.Base(file)
+                games = append(games, name[:len(name)-len(".jsonl")])
+        }
+        sort.Strings(games)
+        return games, nil
+}
+
+// matches проверяет, попадает ли тираж в границы выборки
+func (q DrawQuery) matches(draw Draw) bool {
+        if q.FromNumber > 0 && draw.Number < q.FromNumber {
+                return false
+        }
+        if q.ToNumber > 0 && draw.Number > q.ToNumber {
+                return false
+        }
+        if !q.From.IsZero() && (draw.Date.IsZero() || draw.Date.Before(q.From)) {
+                return false
+        }
+        if !q.To.IsZero() && (draw.Date.IsZero() || !draw.Date.Before(q.To)) {
+                return false
+        }
+        return true
+}
+
+// readGame вызывает read с тиражами игры под блокировкой, при необходимости загружая их с диска
+func (a *FileDrawArchive) readGame(gameName string, read func(draws map[int]Draw)) error {
+        if err := validateGameName(gameName); err != nil {
+                return err
+        }
+
+        a.mu.RLock()
+        if draws, ok := a.games[gameName]; ok {
+                read(draws)
+                a.mu.RUnlock()
+                return nil
+        }
+        a.mu.RUnlock()
+
+        a.mu.Lock()
+        defer a.mu.Unlock()
+        draws, err := a.loadGame(gameName)
+        if err != nil {
+                return err
+        }
+        read(draws)
+        return nil
+}
+
+// loadGame читает файл игры в память (вызывается под mu)
+func (a *FileDrawArchive) loadGame(gameName string) (map[int]Draw, error) {
+        if draws, ok := a.games[gameName]; ok {
+                return draws, nil
+        }
+
+        draws := make(map[int]Draw)
+        file, err := os.Open(a.gamePath(gameName))
+        if errors.Is(err, os.ErrNotExist) {
+                a.games[gameName] = draws
+                return draws, nil
+        }
+        if err != nil {
+                return nil, fmt.Errorf("ошибка открытия архива %s: %w", gameName, err)
+        }
+        defer file.Close()
+
+        scanner := bufio.NewScanner(file)
+        scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+        line := 0
+        for scanner.Scan() {
+                line++
+                if len(scanner.Bytes()) == 0 {
+                        continue
+                }
+                var draw Draw
+                if err := json.Unmarshal(scanner.Bytes(), &draw); err != nil {
+                        // Обрезанная последняя строка (например, после падения) не должна ломать весь архив
+                        log.Printf("[DrawArchive] Skipping corrupted line %d in %s: %v", line, gameName, err)
+                        continue
+                }
+                draw.GameName = gameName
+                draws[draw.Number] = draw
+        }
+        if err := scanner.Err(); err != nil {
+                return nil, fmt.Errorf("ошибка чтения архива %s: %w", gameName, err)
+        }
+
+        a.games[gameName] = draws
+        return draws, nil
+}
+
+// appendDraws дописывает тиражи в файл игры (вызывается под mu)
+func (a *FileDrawArchive) appendDraws(gameName string, draws []Draw) error {
+        file, err := os.OpenFile(a.gamePath(gameName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+        if err != nil {
+                return fmt.Errorf("ошибка открытия архива %s: %w", gameName, err)
+        }
+
+        writer := bufio.NewWriter(file)
+        encoder := json.NewEncoder(writer)
+        for _, draw := range draws {
+                if err := encoder.Encode(draw); err != nil {
+                        file.Close()
+                        return fmt.Errorf("ошибка записи тиража %d в архив %s: %w", draw.Number, gameName, err)
+                }
+        }
+        if err := writer.Flush(); err != nil {
+                file.Close()
+                return fmt.Errorf("ошибка записи архива %s: %w", gameName, err)
+        }
+        return file.Close()
+}
+
+// gamePath возвращает путь к файлу игры
+func (a *FileDrawArchive) gamePath(gameName string) string {
+        return filepath.Join(a.dir, gameName+".jsonl")
+}
+
+// validateGameName проверяет, что имя игры можно использовать как имя файла
+func validateGameName(gameName string) error {
+        if !gameFileNamePattern.MatchString(gameName) {
+                return fmt.Errorf("некорректное имя игры для архива: %q", gameName)
+        }
+        return nil
+}
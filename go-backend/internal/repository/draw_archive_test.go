@@ -0,0 +1,90 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "os"
+        "path/filepath"
+        "testing"
+        "time"
+)
+
+// TestFileDrawArchiveQuery проверяет сохранение, выборки и повторное чтение архива с диска
+func TestFileDrawArchiveQuery(t *testing.T) {
+        ctx := context.Background()
+        dir := t.TempDir()
+        archive, err := NewFileDrawArchive(dir)
+        if err != nil {
+                t.Fatalf("Не удалось создать архив: %v", err)
+        }
+
+        base := time.Date(2025, 11, 1, 21, 0, 0, 0, moscowLocation)
+        for number := 1; number <= 5; number++ {
+                draw := Draw{
+                        Number:         number,
+                        GameName:       "6x45",
+                        DrawDate:       base.AddDate(0, 0, number).Format("2006-01-02 15:04:05"),
+                        WinningNumbers: []int{number, 10, 20, 30, 40, 45},
+                }
+                if err := archive.Save(ctx, draw); err != nil {
+                        t.Fatalf("Не удалось сохранить тираж %d: %v", number, err)
+                }
+        }
+
+        // Повторное сохранение заменяет запись
+        if err := archive.Save(ctx, Draw{Number: 3, GameName: "6x45", Status: "COMPLETED", WinningNumbers: []int{1, 2, 3, 4, 5, 6}}); err != nil {
+                t.Fatalf("Не удалось перезаписать тираж: %v", err)
+        }
+
+        // Обрезанная строка в конце файла (например, после падения) пропускается
+        file, err := os.OpenFile(filepath.Join(dir, "6x45.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+        if err != nil {
+                t.Fatalf("Не удалось открыть файл архива: %v", err)
+        }
+        file.WriteString(`{"number": 6, "winningNum`)
+        file.Close()
+
+        // Новый экземпляр читает архив с диска
+        archive, err = NewFileDrawArchive(dir)
+        if err != nil {
+                t.Fatalf("Не удалось открыть архив: %v", err)
+        }
+
+        draws, total, err := archive.Query(ctx, DrawQuery{GameName: "6x45"})
+        if err != nil {
+                t.Fatalf("Query: %v", err)
+        }
+        if total != 5 || len(draws) != 5 || draws[0].Number != 5 || draws[4].Number != 1 {
+                t.Fatalf("Ожидается 5 тиражей от новых к старым, получено %d (total %d)", len(draws), total)
+        }
+
+        draw, err := archive.Get(ctx, "6x45", 3)
+        if err != nil || draw.Status != "COMPLETED" {
+                t.Errorf("Ожидается перезаписанный тираж 3, получено %+v, ошибка %v", draw, err)
+        }
+        if _, err := archive.Get(ctx, "6x45", 42); !errors.Is(err, ErrDrawNotArchived) {
+                t.Errorf("Ожидается ErrDrawNotArchived, получено %v", err)
+        }
+
+        // Диапазон номеров и пагинация
+        draws, total, _ = archive.Query(ctx, DrawQuery{GameName: "6x45", FromNumber: 2, ToNumber: 4, Offset: 1, Limit: 1})
+        if total != 3 || len(draws) != 1 || draws[0].Number != 3 {
+                t.Errorf("Ожидается тираж 3 из 3 подходящих, получено %+v (total %d)", draws, total)
+        }
+
+        // Диапазон дат: [From, To)
+        from := base.AddDate(0, 0, 4)
+        draws, _, _ = archive.Query(ctx, DrawQuery{GameName: "6x45", From: from, To: from.AddDate(0, 0, 1)})
+        if len(draws) != 1 || draws[0].Number != 4 {
+                t.Errorf("Ожидается тираж 4 по диапазону дат, получено %+v", draws)
+        }
+
+        games, err := archive.Games(ctx)
+        if err != nil || len(games) != 1 || games[0] != "6x45" {
+                t.Errorf("Ожидается одна игра 6x45, получено %v, ошибка %v", games, err)
+        }
+
+        if _, err := archive.Has(ctx, "../etc/passwd", 1); err == nil {
+                t.Error("Имя игры с путем должно отклоняться")
+        }
+}
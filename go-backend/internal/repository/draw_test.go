@@ -0,0 +1,116 @@
+package repository
+
+import (
+        "context"
+        "encoding/json"
+        "net/http"
+        "net/http/httptest"
+        "testing"
+        "time"
+)
+
+const sampleDrawJSON = `{
+        "number": 1234,
+        "gameName": "5x36plus",
+        "drawDate": "2025-11-20 21:00:00",
+        "status": "COMPLETED",
+        "winningNumbers": [3, 11, 17, 25, 31, 2],
+        "jackpot": 15600000000,
+        "prizePool": "2500000050",
+        "winners": {
+                "4": {"count": 40, "prize": 150000},
+                "5+1": {"count": 0, "prize": 0},
+                "5": {"count": 2, "prize": 50000000}
+        }
+}`
+
+// TestDrawUnmarshal проверяет разбор полного результата тиража
+func TestDrawUnmarshal(t *testing.T) {
+        var draw Draw
+        if err := json.Unmarshal([]byte(sampleDrawJSON), &draw); err != nil {
+                t.Fatalf("Не удалось разобрать тираж: %v", err)
+        }
+
+        expectedDate := time.Date(2025, 11, 20, 18, 0, 0, 0, time.UTC)
+        if !draw.Date.Equal(expectedDate) {
+                t.Errorf("Дата тиража: ожидается %v, получено %v", expectedDate, draw.Date)
+        }
+
+        if draw.Jackpot.Roubles() != 156000000 {
+                t.Errorf("Джекпот: ожидается 156000000 ₽, получено %v", draw.Jackpot)
+        }
+
+        if draw.PrizePool.Kopecks() != 2500000050 {
+                t.Errorf("Призовой фонд в строке должен разбираться: получено %d", draw.PrizePool.Kopecks())
+        }
+
+        // Категории упорядочены от главной к младшей
+        expectedTiers := []string{"5+1", "5", "4"}
+        if len(draw.Winners) != len(expectedTiers) {
+                t.Fatalf("Ожидается %d категорий, получено %d", len(expectedTiers), len(draw.Winners))
+        }
+        for i, tier := range expectedTiers {
+                if draw.Winners[i].Tier != tier {
+                        t.Errorf("Категория %d: ожидается %s, получено %s", i, tier, draw.Winners[i].Tier)
+                }
+        }
+
+        tier, ok := draw.Winners.Find("4")
+        if !ok || tier.Winners != 40 || tier.Prize.Roubles() != 1500 {
+                t.Errorf("Категория 4 разобрана неверно: %+v", tier)
+        }
+
+        if draw.Winners.TotalWinners() != 42 {
+                t.Errorf("Всего победителей: ожидается 42, получено %d", draw.Winners.TotalWinners())
+        }
+}
+
+// TestDrawRoundTrip проверяет что тираж переживает сериализацию без потерь
+func TestDrawRoundTrip(t *testing.T) {
+        var original Draw
+        if err := json.Unmarshal([]byte(sampleDrawJSON), &original); err != nil {
+                t.Fatalf("Не удалось разобрать тираж: %v", err)
+        }
+
+        data, err := json.Marshal(original)
+        if err != nil {
+                t.Fatalf("Не удалось сериализовать тираж: %v", err)
+        }
+
+        var restored Draw
+        if err := json.Unmarshal(data, &restored); err != nil {
+                t.Fatalf("Не удалось разобрать сериализованный тираж: %v", err)
+        }
+
+        if !restored.Date.Equal(original.Date) || len(restored.Winners) != len(original.Winners) ||
+                restored.PrizePool != original.PrizePool {
+                t.Errorf("Тираж изменился после сериализации: %+v -> %+v", original, restored)
+        }
+}
+
+// TestGetPrelatestDraw проверяет запрос к /api/draw/prelatest
+func TestGetPrelatestDraw(t *testing.T) {
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                if r.URL.Path != "/api/draw/prelatest" || r.URL.Query().Get("name") != "6x45" {
+                        http.NotFound(w, r)
+                        return
+                }
+                w.Write([]byte(`{"requestStatus": "success", "draw": {"number": 99, "drawDate": "2025-11-19T20:00:00+03:00", "status": "COMPLETED"}}`))
+        }))
+        defer server.Close()
+
+        client := NewStolotoClient(server.URL)
+        draw, err := client.GetPrelatestDraw(context.Background(), "6x45")
+        if err != nil {
+                t.Fatalf("GetPrelatestDraw вернул ошибку: %v", err)
+        }
+
+        if draw.Number != 99 {
+                t.Errorf("Номер тиража: ожидается 99, получено %d", draw.Number)
+        }
+
+        // gameName подставляется из запроса, если его нет в ответе
+        if draw.GameName != "6x45" {
+                t.Errorf("GameName: ожидается 6x45, получено %s", draw.GameName)
+        }
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+        _ "embed"
+        "encoding/json"
+        "fmt"
+        "log"
+        "os"
+        "strings"
+        "sync"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// GameCatalogVersion - поддерживаемая версия формата каталога игр
+const GameCatalogVersion = 1
+
+//go:embed game_catalog.json
+var embeddedGameCatalog []byte
+
+// GameCatalog - декларативное описание игр StolotoAPI: тип, расписание, тексты, правила и призы
+// Встроенный каталог (game_catalog.json) можно заменить своим файлом того же формата
+type GameCatalog struct {
+        Version int                  `json:"version"`
+        Games   map[string]GameEntry `json:"games"` // По имени игры в StolotoAPI
+}
+
+// GameEntry описывает одну игру каталога
+type GameEntry struct {
+        DisplayName   string               `json:"displayName"`
+        Type          domain.LotteryType   `json:"type"`
+        DrawFrequency domain.DrawFrequency `json:"drawFrequency"`
+        Description   string               `json:"description"`
+        Rules         string               `json:"rules"`
+        ImageURL      string               `json:"imageUrl,omitempty"`
+        Schedule      *domain.DrawSchedule `json:"schedule,omitempty"` // Расписание тиражей (МСК)
+        Fields        []domain.NumberField `json:"fields,omitempty"`   // Игровые поля (только для числовых игр)
+        Tiers         []CatalogTier        `json:"tiers,omitempty"`    // Призовые категории, от главной к младшей
+}
+
+// CatalogTier описывает призовую категорию игры
+type CatalogTier struct {
+        Name    string        `json:"name"`
+        Key     string        `json:"key,omitempty"` // Ключ в победителях тиража; по умолчанию - первое слово Name
+        Matches [][]int       `json:"matches"`
+        Payout  domain.Payout `json:"payout"`
+}
+
+var (
+        defaultGameCatalogOnce sync.Once
+        defaultGameCatalog     *GameCatalog
+)
+
+// DefaultGameCatalog возвращает встроенный каталог игр
+func DefaultGameCatalog() *GameCatalog {
+        defaultGameCatalogOnce.Do(func() {
+                catalog, err := ParseGameCatalog(embeddedGameCatalog)
+                if err != nil {
+                        // Встроенный каталог проверяется тестами, поэтому ошибка здесь - ошибка сборки
+                        panic(fmt.Sprintf("встроенный каталог игр некорректен: %v", err))
+                }
+                defaultGameCatalog = catalog
+        })
+        return defaultGameCatalog
+}
+
+// LoadGameCatalog читает каталог игр из файла (пустой путь - встроенный каталог)
+func LoadGameCatalog(path string) (*GameCatalog, error) {
+        if path == "" {
+                return DefaultGameCatalog(), nil
+        }
+
+        data, err := os.ReadFile(path)
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения каталога игр %s: %w", path, err)
+        }
+        catalog, err := ParseGameCatalog(data)
+        if err != nil {
+                return nil, fmt.Errorf("каталог игр %s: %w", path, err)
+        }
+        return catalog, nil
+}
+
+// ParseGameCatalog разбирает и проверяет каталог игр
+func ParseGameCatalog(data []byte) (*GameCatalog, error) {
+        var catalog GameCatalog
+        if err := json.Unmarshal(data, &catalog); err != nil {
+                return nil, fmt.Errorf("некорректный JSON: %w", err)
+        }
+        if catalog.Version != GameCatalogVersion {
+                return nil, fmt.Errorf("неподдерживаемая версия %d (ожидается %d)", catalog.Version, GameCatalogVersion)
+        }
+
+        games := make(map[string]GameEntry, len(catalog.Games))
+        for name, entry := range catalog.Games {
+                if err := entry.validate(); err != nil {
+                        return nil, fmt.Errorf("игра %s: %w", name, err)
+                }
+                for i := range entry.Tiers {
+                        if entry.Tiers[i].Key == "" {
+                                entry.Tiers[i].Key = winnersTierKey(entry.Tiers[i].Name)
+                        }
+                }
+                games[strings.ToLower(name)] = entry
+        }
+        catalog.Games = games
+        return &catalog, nil
+}
+
+// Lookup возвращает описание игры по имени в StolotoAPI
+func (c *GameCatalog) Lookup(gameName string) (GameEntry, bool) {
+        entry, ok := c.Games[strings.ToLower(gameName)]
+        return entry, ok
+}
+
+// GameRules возвращает правила числовой игры (false - у игры нет игровых полей)
+func (e GameEntry) GameRules() (domain.GameRules, bool) {
+        if len(e.Fields) == 0 {
+                return domain.GameRules{}, false
+        }
+
+        rules := domain.GameRules{Fields: e.Fields}
+        for _, tier := range e.Tiers {
+                rules.Tiers = append(rules.Tiers, domain.MatchTier{Name: tier.Name, Key: tier.Key, Matches: tier.Matches})
+        }
+        return rules, true
+}
+
+// validate проверяет описание игры
+func (e GameEntry) validate() error {
+        switch e.Type {
+        case domain.LotteryTypeNumbered, domain.LotteryTypeInstant, domain.LotteryTypeDrawBased, domain.LotteryTypeSportloto:
+        default:
+                return fmt.Errorf("неизвестный тип лотереи %q", e.Type)
+        }
+        switch e.DrawFrequency {
+        case domain.DrawFrequencyDaily, domain.DrawFrequencySeveralPerWeek, domain.DrawFrequencyWeekly, domain.DrawFrequencyMonthly:
+        default:
+                return fmt.Errorf("неизвестная частота розыгрышей %q", e.DrawFrequency)
+        }
+        if e.Schedule != nil {
+                if err := e.Schedule.Validate(); err != nil {
+                        return fmt.Errorf("расписание: %w", err)
+                }
+        }
+        if len(e.Tiers) > 0 && len(e.Fields) == 0 {
+                return fmt.Errorf("призовые категории заданы без игровых полей")
+        }
+
+        for _, tier := range e.Tiers {
+                switch tier.Payout.Kind {
+                case domain.PayoutFixed, domain.PayoutJackpot:
+                case domain.PayoutPoolShare:
+                        if tier.Payout.Share <= 0 || tier.Payout.Share > 1 {
+                                return fmt.Errorf("категория %q: доля призового фонда должна быть от 0 до 1", tier.Name)
+                        }
+                default:
+                        return fmt.Errorf("категория %q: неизвестный вид приза %q", tier.Name, tier.Payout.Kind)
+                }
+        }
+
+        if rules, ok := e.GameRules(); ok {
+                return rules.Validate()
+        }
+        return nil
+}
+
+// ApplyGameRules заполняет правила, вероятность выигрыша (в процентах), структуру призов
+// и ожидаемый выигрыш лотереи по правилам игры (PrizeStructure[i] соответствует GameRules.Tiers[i]).
+// Если игры нет в каталоге или у нее нет игровых полей, возвращает false и лотерею не меняет.
+func (c *GameCatalog) ApplyGameRules(lottery *domain.Lottery) bool {
+        entry, ok := c.Lookup(lottery.ID)
+        if !ok {
+                return false
+        }
+        rules, ok := entry.GameRules()
+        if !ok {
+                return false
+        }
+
+        odds := rules.TierOdds()
+        prizeStructure := make([]domain.PrizeCategory, 0, len(rules.Tiers))
+        for i, tier := range entry.Tiers {
+                tierOdds := domain.NewOdds(odds[i])
+                payout := tier.Payout
+                if payout.Kind == domain.PayoutJackpot {
+                        payout.Amount = lottery.CurrentJackpot
+                }
+                prizeStructure = append(prizeStructure, domain.PrizeCategory{
+                        Category:    tier.Name,
+                        Prize:       payout.String(),
+                        Probability: tierOdds.String(),
+                        Match:       tier.Matches,
+                        Odds:        &tierOdds,
+                        Payout:      &payout,
+                })
+        }
+
+        winProbability, _ := rules.WinOdds().Float64()
+        lottery.GameRules = &rules
+        lottery.WinProbability = winProbability * 100 // WinProbability хранится в процентах
+        lottery.PrizeStructure = prizeStructure
+        lottery.ExpectedValue = domain.ComputeExpectedValue(lottery.TicketPrice, prizeStructure)
+        return true
+}
+
+// Merge накладывает данные StolotoAPI (цены, джекпот) на описание игры из каталога
+// Для игр без описания используются общие тексты и предупреждение в логе
+func (c *GameCatalog) Merge(game Game) domain.Lottery {
+        ticketPrice := kopecksToRoubles(game.TicketPrice)
+        currentJackpot := kopecksToRoubles64(game.Jackpot)
+
+        lottery := domain.Lottery{
+                ID:             game.Name,
+                Name:           game.DisplayName,
+                TicketPrice:    ticketPrice,
+                MaxJackpot:     currentJackpot, // Используем текущий джекпот как максимальный
+                CurrentJackpot: currentJackpot,
+                IsActive:       true,
+        }
+
+        entry, ok := c.Lookup(game.Name)
+        if !ok {
+                log.Printf("[GameCatalog] WARNING: game %q has no catalog entry, using generic description", game.Name)
+                if lottery.Name == "" {
+                        lottery.Name = game.Name
+                }
+                lottery.Type = domain.LotteryTypeDrawBased
+                lottery.DrawFrequency = convertDrawFrequency(game.DrawFrequency)
+                lottery.Description = generateDescription(lottery.Name, lottery.Type)
+                lottery.Rules = generateRules(lottery.Name, lottery.Type)
+                lottery.PrizeStructure = unknownGamePrizeStructure(currentJackpot)
+                return lottery
+        }
+
+        if lottery.Name == "" {
+                lottery.Name = entry.DisplayName
+        }
+        lottery.Type = entry.Type
+        lottery.DrawFrequency = entry.DrawFrequency
+        lottery.Description = entry.Description
+        lottery.Rules = entry.Rules
+        if entry.ImageURL != "" {
+                imageURL := entry.ImageURL
+                lottery.ImageURL = &imageURL
+        }
+        lottery.Schedule = entry.Schedule
+
+        // Вероятности и структура призов считаются точно по правилам игры
+        if !c.ApplyGameRules(&lottery) {
+                // Правила игры не описаны - вероятности не выдумываем
+                lottery.PrizeStructure = unknownGamePrizeStructure(currentJackpot)
+        }
+        return lottery
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestEmbeddedGameCatalog проверяет встроенный каталог игр
+func TestEmbeddedGameCatalog(t *testing.T) {
+        catalog, err := ParseGameCatalog(embeddedGameCatalog)
+        if err != nil {
+                t.Fatalf("Встроенный каталог некорректен: %v", err)
+        }
+
+        for _, name := range []string{"6x45", "5x36plus", "4x20", "7x49", "rapido", "12x24"} {
+                entry, ok := catalog.Lookup(name)
+                if !ok {
+                        t.Errorf("%s: нет в каталоге", name)
+                        continue
+                }
+                if _, ok := entry.GameRules(); !ok {
+                        t.Errorf("%s: нет правил игры", name)
+                }
+        }
+
+        // У всех тиражных игр каталога есть расписание: без него нет ближайших тиражей и календаря
+        for name, entry := range catalog.Games {
+                if entry.Schedule == nil {
+                        t.Errorf("%s: нет расписания тиражей", name)
+                }
+        }
+        if entry, _ := catalog.Lookup("7x49"); entry.Schedule == nil || len(entry.Schedule.Weekdays) != 2 {
+                t.Errorf("7x49: ожидается расписание по средам и субботам, получено %+v", entry.Schedule)
+        }
+
+        entry, _ := catalog.Lookup("5X36PLUS")
+        if entry.Tiers[0].Key != "5+1" {
+                t.Errorf("Ключ категории по умолчанию - первое слово названия, получено %q", entry.Tiers[0].Key)
+        }
+        if entry, _ := catalog.Lookup("6x45"); entry.Tiers[0].Key != "6" {
+                t.Errorf("Ключ категории \"6 из 6\" - \"6\", получено %q", entry.Tiers[0].Key)
+        }
+}
+
+// TestParseGameCatalogErrors проверяет отклонение некорректных каталогов
+func TestParseGameCatalogErrors(t *testing.T) {
+        cases := map[string]string{
+                "версия":            `{"version": 2, "games": {}}`,
+                "тип":               `{"version": 1, "games": {"x": {"type": "лото", "drawFrequency": "ежедневно"}}}`,
+                "частота":           `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "иногда"}}}`,
+                "категории":         `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "ежедневно", "tiers": [{"name": "1", "matches": [[1]], "payout": {"kind": "jackpot"}}]}}}`,
+                "доля фонда":        `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "ежедневно", "fields": [{"pick": 1, "of": 2}], "tiers": [{"name": "1", "matches": [[1]], "payout": {"kind": "poolShare", "share": 2}}]}}}`,
+                "вид приза":         `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "ежедневно", "fields": [{"pick": 1, "of": 2}], "tiers": [{"name": "1", "matches": [[1]], "payout": {"kind": "bonus"}}]}}}`,
+                "расписание":        `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "ежедневно", "schedule": {"times": ["25:00"]}}}}`,
+                "условие":           `{"version": 1, "games": {"x": {"type": "числовая", "drawFrequency": "ежедневно", "fields": [{"pick": 1, "of": 2}], "tiers": [{"name": "1", "matches": [[1, 1]], "payout": {"kind": "jackpot"}}]}}}`,
+                "некорректный JSON": `{"version": 1,`,
+        }
+        for name, data := range cases {
+                if _, err := ParseGameCatalog([]byte(data)); err == nil {
+                        t.Errorf("%s: ожидается ошибка", name)
+                }
+        }
+}
+
+// TestLoadGameCatalogOverride проверяет загрузку каталога из файла
+func TestLoadGameCatalogOverride(t *testing.T) {
+        path := filepath.Join(t.TempDir(), "games.json")
+        data := `{"version": 1, "games": {"Keno": {
+                "displayName": "Кено", "type": "тиражная", "drawFrequency": "ежедневно",
+                "description": "Описание", "rules": "Правила", "imageUrl": "/images/keno.png",
+                "fields": [{"pick": 2, "of": 10, "drawn": 5}],
+                "tiers": [{"name": "2 из 2", "matches": [[2]], "payout": {"kind": "fixed", "amount": 1000}}]
+        }}}`
+        if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+                t.Fatal(err)
+        }
+
+        catalog, err := LoadGameCatalog(path)
+        if err != nil {
+                t.Fatalf("Не удалось загрузить каталог: %v", err)
+        }
+        if _, ok := catalog.Lookup("6x45"); ok {
+                t.Error("Каталог из файла заменяет встроенный целиком")
+        }
+
+        client := NewStolotoClient("http://localhost", WithGameCatalog(catalog))
+        lottery := client.ConvertGameToLottery(Game{Name: "keno", TicketPrice: 5000, Jackpot: 100000000})
+        if lottery.Name != "Кено" || lottery.Type != domain.LotteryTypeDrawBased || lottery.Rules != "Правила" {
+                t.Errorf("Описание не взято из каталога: %+v", lottery)
+        }
+        if lottery.ImageURL == nil || *lottery.ImageURL != "/images/keno.png" {
+                t.Errorf("Изображение не взято из каталога: %v", lottery.ImageURL)
+        }
+        // 2 из 10, выпадает 5: C(5,2) / C(10,2) = 10/45
+        if len(lottery.PrizeStructure) != 1 || *lottery.PrizeStructure[0].Odds != (domain.Odds{Numerator: 2, Denominator: 9}) {
+                t.Errorf("Вероятность категории посчитана неверно: %+v", lottery.PrizeStructure)
+        }
+        if lottery.TicketPrice != 50 || lottery.CurrentJackpot != 1000000 {
+                t.Errorf("Цены должны браться из StolotoAPI: %+v", lottery)
+        }
+
+        if _, err := LoadGameCatalog(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+                t.Error("Ожидается ошибка для отсутствующего файла")
+        }
+}
+
+// TestConvertGameWithoutCatalogEntry проверяет игру, которой нет в каталоге
+func TestConvertGameWithoutCatalogEntry(t *testing.T) {
+        client := NewStolotoClient("http://localhost")
+        lottery := client.ConvertGameToLottery(Game{Name: "newgame", DisplayName: "Новая игра", TicketPrice: 10000, Jackpot: 500000000, DrawFrequency: "weekly"})
+
+        if lottery.Type != domain.LotteryTypeDrawBased || lottery.DrawFrequency != domain.DrawFrequencyWeekly {
+                t.Errorf("Неожиданные тип и частота: %s, %s", lottery.Type, lottery.DrawFrequency)
+        }
+        if lottery.GameRules != nil || lottery.WinProbability != 0 || lottery.ExpectedValue != nil {
+                t.Errorf("Без правил вероятности не выдумываются: %+v", lottery)
+        }
+        if len(lottery.PrizeStructure) != 1 || lottery.PrizeStructure[0].Probability != "н/д" {
+                t.Errorf("Ожидается только джекпот без вероятности: %+v", lottery.PrizeStructure)
+        }
+        if !strings.Contains(lottery.Description, "Новая игра") {
+                t.Errorf("Описание должно содержать название игры: %s", lottery.Description)
+        }
+}
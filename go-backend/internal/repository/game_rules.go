@@ -0,0 +1,44 @@
+package repository
+
+import (
+        "strings"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// LookupGameRules возвращает правила числовой игры из встроенного каталога по имени игры в StolotoAPI
+// Каталог из GAME_CATALOG_PATH передается в клиент и сервисы явно (WithGameCatalog)
+func LookupGameRules(gameName string) (domain.GameRules, bool) {
+        entry, ok := DefaultGameCatalog().Lookup(gameName)
+        if !ok {
+                return domain.GameRules{}, false
+        }
+        return entry.GameRules()
+}
+
+// ApplyGameRules заполняет вероятности и структуру призов лотереи по встроенному каталогу игр
+// (см. GameCatalog.ApplyGameRules; для каталога из файла вызывается у него)
+func ApplyGameRules(lottery *domain.Lottery) bool {
+        return DefaultGameCatalog().ApplyGameRules(lottery)
+}
+
+// winnersTierKey возвращает ключ категории в победителях тиража: "6 из 6" -> "6", "12 или 0" -> "12", "5+1" -> "5+1"
+func winnersTierKey(name string) string {
+        if fields := strings.Fields(name); len(fields) > 0 {
+                return fields[0]
+        }
+        return name
+}
+
+// unknownGamePrizeStructure - структура призов игры без известных правил: только джекпот, без вероятностей
+func unknownGamePrizeStructure(amount float64) []domain.PrizeCategory {
+        payout := domain.Payout{Kind: domain.PayoutJackpot, Amount: amount}
+        return []domain.PrizeCategory{
+                {
+                        Category:    "Джекпот",
+                        Prize:       payout.String(),
+                        Probability: "н/д",
+                        Payout:      &payout,
+                },
+        }
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+        "math"
+        "math/big"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestGameFieldOddsSum проверяет, что вероятности всех исходов каждого поля игр каталога в сумме дают 1
+func TestGameFieldOddsSum(t *testing.T) {
+        for name, entry := range DefaultGameCatalog().Games {
+                rules, ok := entry.GameRules()
+                if !ok {
+                        continue
+                }
+                for i, field := range rules.Fields {
+                        total := new(big.Rat)
+                        for k := 0; k <= field.Pick; k++ {
+                                total.Add(total, field.MatchProbability(k))
+                        }
+                        if total.Cmp(big.NewRat(1, 1)) != 0 {
+                                t.Errorf("%s: сумма вероятностей поля %d равна %s", name, i+1, total.RatString())
+                        }
+                }
+        }
+}
+
+// TestGameRulesExactOdds сверяет вероятности категорий с точными значениями
+func TestGameRulesExactOdds(t *testing.T) {
+        cases := []struct {
+                game     string
+                tier     int
+                expected *big.Rat
+        }{
+                {"6x45", 0, big.NewRat(1, 8145060)},
+                {"6x45", 1, big.NewRat(6*39, 8145060)},
+                {"6x45", 3, big.NewRat(20*9139, 8145060)},
+                {"7x49", 1, big.NewRat(7*42, 85900584)},
+                {"5x36plus", 0, big.NewRat(1, 376992*4)},
+                {"5x36plus", 1, big.NewRat(3, 376992*4)},
+                {"5x36plus", 2, big.NewRat(5*31, 376992)},
+                {"4x20", 0, big.NewRat(1, 4845*4845)},
+                {"4x20", 1, big.NewRat(2*64, 4845*4845)},
+                {"12x24", 0, big.NewRat(2, 2704156)},
+        }
+
+        for _, c := range cases {
+                rules, ok := LookupGameRules(c.game)
+                if !ok {
+                        t.Fatalf("%s: правила не найдены", c.game)
+                }
+                odds := rules.TierOdds()[c.tier]
+                if odds.Cmp(c.expected) != 0 {
+                        t.Errorf("%s, категория %q: ожидается %s, получено %s",
+                                c.game, rules.Tiers[c.tier].Name, c.expected.RatString(), odds.RatString())
+                }
+        }
+}
+
+// TestApplyGameRules проверяет заполнение вероятностей и структуры призов лотереи
+func TestApplyGameRules(t *testing.T) {
+        lottery := domain.Lottery{ID: "6x45", CurrentJackpot: 320000000}
+        if !ApplyGameRules(&lottery) {
+                t.Fatal("Правила 6x45 должны быть известны")
+        }
+
+        expected := []string{"1:8145060", "1:34808", "1:733", "1:45"}
+        if len(lottery.PrizeStructure) != len(expected) {
+                t.Fatalf("Ожидается %d категорий, получено %d", len(expected), len(lottery.PrizeStructure))
+        }
+        for i, probability := range expected {
+                if lottery.PrizeStructure[i].Probability != probability {
+                        t.Errorf("Категория %s: ожидается %s, получено %s",
+                                lottery.PrizeStructure[i].Category, probability, lottery.PrizeStructure[i].Probability)
+                }
+        }
+
+        jackpotTier := lottery.PrizeStructure[0]
+        if jackpotTier.Odds == nil || *jackpotTier.Odds != (domain.Odds{Numerator: 1, Denominator: 8145060}) {
+                t.Errorf("Джекпот: ожидается вероятность 1/8145060, получено %+v", jackpotTier.Odds)
+        }
+        if jackpotTier.Payout == nil || jackpotTier.Payout.Kind != domain.PayoutJackpot || jackpotTier.Payout.Amount != 320000000 {
+                t.Errorf("Джекпот: некорректный приз %+v", jackpotTier.Payout)
+        }
+        if jackpotTier.Prize != "Джекпот (320.0 млн ₽)" || len(jackpotTier.Match) != 1 || jackpotTier.Match[0][0] != 6 {
+                t.Errorf("Джекпот: некорректное описание категории %+v", jackpotTier)
+        }
+        second := lottery.PrizeStructure[1]
+        if second.Payout == nil || *second.Payout != (domain.Payout{Kind: domain.PayoutFixed, Amount: 10000}) || second.Prize != "10000 ₽" {
+                t.Errorf("5 из 6: некорректный приз %+v", second.Payout)
+        }
+        if second.Odds == nil || *second.Odds != (domain.Odds{Numerator: 39, Denominator: 1357510}) {
+                t.Errorf("5 из 6: ожидается вероятность 39/1357510 (234/8145060), получено %+v", second.Odds)
+        }
+
+        // Любой выигрыш: (1 + 234 + 11115 + 182780) / 8145060, в процентах
+        winProbability := 194130.0 / 8145060.0 * 100
+        if math.Abs(lottery.WinProbability-winProbability) > 1e-9 {
+                t.Errorf("WinProbability: ожидается %v, получено %v", winProbability, lottery.WinProbability)
+        }
+        if lottery.GameRules == nil || lottery.GameRules.String() != "6 из 45" {
+                t.Errorf("Правила игры заполнены неверно: %+v", lottery.GameRules)
+        }
+
+        if lottery.ExpectedValue != nil {
+                t.Error("Без цены билета EV не рассчитывается")
+        }
+        lottery.TicketPrice = 100
+        ApplyGameRules(&lottery)
+        // 320 млн / 8145060 + 10000 * 234 / 8145060 + 1000 * 11115 / 8145060 + 100 * 182780 / 8145060
+        gross := (320000000.0 + 10000*234 + 1000*11115 + 100*182780) / 8145060
+        if lottery.ExpectedValue == nil || math.Abs(lottery.ExpectedValue.Gross-gross) > 1e-9 {
+                t.Errorf("EV: ожидается %v, получено %+v", gross, lottery.ExpectedValue)
+        }
+
+        unknown := domain.Lottery{ID: "ruslotto", WinProbability: 1}
+        if ApplyGameRules(&unknown) || unknown.WinProbability != 1 || unknown.GameRules != nil {
+                t.Error("Лотерея с неизвестными правилами не должна меняться")
+        }
+}
+
+// TestFormatOdds проверяет форматирование вероятностей
+func TestFormatOdds(t *testing.T) {
+        cases := map[string]string{
+                "1/8145060": "1:8145060",
+                "720/4845":  "1:6.7",
+                "1/4":       "1:4",
+                "0":         "н/д",
+        }
+        for input, expected := range cases {
+                probability, _ := new(big.Rat).SetString(input)
+                if got := domain.FormatOdds(probability); got != expected {
+                        t.Errorf("%s: ожидается %s, получено %s", input, expected, got)
+                }
+        }
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+        "bytes"
+        "context"
+        "encoding/json"
+        "fmt"
+        "log"
+        "strconv"
+        "strings"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// MomentalResponse представляет ответ от /api/draw/momental
+type MomentalResponse struct {
+        RequestStatus string         `json:"requestStatus"`
+        MomentalCards []MomentalCard `json:"momentalCards"`
+}
+
+// MomentalCard представляет моментальную лотерею (скретч-карту) из StolotoAPI
+type MomentalCard struct {
+        Name             string      `json:"name"`
+        DisplayName      string      `json:"displayName"`
+        TicketPrice      int         `json:"ticketPrice"` // в копейках
+        MaxPrize         Money       `json:"maxPrize"`    // в копейках
+        AvailableTickets int         `json:"availableTickets"`
+        TotalTickets     int         `json:"totalTickets"`
+        OddsOfWinning    WinningOdds `json:"oddsOfWinning"`
+}
+
+// WinningOdds - вероятность выигрыша одного билета (0..1)
+// StolotoAPI отдает ее либо строкой "1:3.5", либо числом:
+// значение <= 1 считается вероятностью, значение > 1 - знаменателем "1 из N"
+type WinningOdds float64
+
+// UnmarshalJSON разбирает шансы на выигрыш в любом из форматов StolotoAPI
+func (o *WinningOdds) UnmarshalJSON(data []byte) error {
+        data = bytes.TrimSpace(data)
+        if len(data) == 0 || string(data) == "null" {
+                *o = 0
+                return nil
+        }
+
+        if data[0] != '"' {
+                var value float64
+                if err := json.Unmarshal(data, &value); err != nil {
+                        return fmt.Errorf("некорректные шансы на выигрыш %s: %w", data, err)
+                }
+                *o = oddsFromNumber(value)
+                return nil
+        }
+
+        var raw string
+        if err := json.Unmarshal(data, &raw); err != nil {
+                return err
+        }
+        raw = strings.TrimSpace(raw)
+        if raw == "" {
+                *o = 0
+                return nil
+        }
+
+        if parts := strings.SplitN(raw, ":", 2); len(parts) == 2 {
+                numerator, errNum := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+                denominator, errDen := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+                if errNum != nil || errDen != nil || denominator <= 0 {
+                        return fmt.Errorf("некорректные шансы на выигрыш %q", raw)
+                }
+                *o = WinningOdds(numerator / denominator)
+                return nil
+        }
+
+        value, err := strconv.ParseFloat(raw, 64)
+        if err != nil {
+                return fmt.Errorf("некорректные шансы на выигрыш %q", raw)
+        }
+        *o = oddsFromNumber(value)
+        return nil
+}
+
+// oddsFromNumber переводит числовые шансы в вероятность
+func oddsFromNumber(value float64) WinningOdds {
+        if value > 1 {
+                return WinningOdds(1 / value)
+        }
+        if value < 0 {
+                return 0
+        }
+        return WinningOdds(value)
+}
+
+// Probability возвращает вероятность выигрыша (0..1)
+func (o WinningOdds) Probability() float64 {
+        return float64(o)
+}
+
+// TicketsRemainingRatio возвращает долю непроданных билетов тиража (0..1)
+func (c MomentalCard) TicketsRemainingRatio() float64 {
+        if c.TotalTickets <= 0 {
+                return 0
+        }
+        ratio := float64(c.AvailableTickets) / float64(c.TotalTickets)
+        if ratio < 0 {
+                return 0
+        }
+        if ratio > 1 {
+                return 1
+        }
+        return ratio
+}
+
+// GetMomentalCards получает список моментальных лотерей из StolotoAPI
+// Использует retry логику для повышения надежности
+func (c *StolotoClient) GetMomentalCards(ctx context.Context) (*MomentalResponse, error) {
+        requestURL := fmt.Sprintf("%s/api/draw/momental", c.baseURL)
+
+        log.Printf("[StolotoClient] Fetching momental cards from %s", requestURL)
+        resp, err := c.doRequestWithRetry(ctx, EndpointMomental, requestURL)
+        if err != nil {
+                log.Printf("[StolotoClient] Momental request failed after retries: %v", err)
+                return nil, err
+        }
+        defer resp.Body.Close()
+
+        var result MomentalResponse
+        if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+                log.Printf("[StolotoClient] Momental JSON unmarshal failed: %v", err)
+                return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
+        }
+
+        log.Printf("[StolotoClient] Successfully fetched %d momental cards from API", len(result.MomentalCards))
+        return &result, nil
+}
+
+// ConvertMomentalToLottery конвертирует моментальную лотерею из StolotoAPI в domain.Lottery
+func (c *StolotoClient) ConvertMomentalToLottery(card MomentalCard) domain.Lottery {
+        displayName := card.DisplayName
+        if displayName == "" {
+                displayName = card.Name
+        }
+
+        ticketPrice := kopecksToRoubles(card.TicketPrice)
+        maxPrize := card.MaxPrize.Roubles()
+        odds := card.OddsOfWinning.Probability()
+        ticketsRemaining := card.TicketsRemainingRatio()
+
+        // По скретч-картам StolotoAPI отдает только общие шансы на выигрыш
+        probability := "н/д"
+        if odds > 0 {
+                probability = fmt.Sprintf("1:%.1f", 1/odds)
+        }
+        prizeStructure := []domain.PrizeCategory{
+                {
+                        Category:    "Любой выигрыш",
+                        Prize:       fmt.Sprintf("до %.0f ₽", maxPrize),
+                        Probability: probability,
+                },
+        }
+
+        return domain.Lottery{
+                ID:               card.Name,
+                Name:             displayName,
+                Type:             domain.LotteryTypeInstant,
+                TicketPrice:      ticketPrice,
+                MaxJackpot:       maxPrize,
+                CurrentJackpot:   maxPrize,
+                WinProbability:   odds * 100, // WinProbability хранится в процентах
+                DrawFrequency:    domain.DrawFrequencyDaily,
+                Description:      generateDescription(displayName, domain.LotteryTypeInstant),
+                Rules:            fmt.Sprintf("Купите билет %s и сотрите защитный слой - результат известен сразу. Осталось %d из %d билетов тиража.", displayName, card.AvailableTickets, card.TotalTickets),
+                PrizeStructure:   prizeStructure,
+                ImageURL:         nil,
+                IsActive:         card.AvailableTickets > 0,
+                TicketsRemaining: &ticketsRemaining,
+        }
+}
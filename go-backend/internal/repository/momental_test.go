@@ -0,0 +1,78 @@
+package repository
+
+import (
+        "encoding/json"
+        "math"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestWinningOddsFormats проверяет разбор шансов на выигрыш во всех форматах StolotoAPI
+func TestWinningOddsFormats(t *testing.T) {
+        cases := map[string]float64{
+                `"1:4"`:     0.25,
+                `" 1 : 5 "`: 0.2,
+                `0.125`:     0.125,
+                `8`:         0.125,
+                `"2.5"`:     0.4,
+                `null`:      0,
+        }
+
+        for input, expected := range cases {
+                var odds WinningOdds
+                if err := json.Unmarshal([]byte(input), &odds); err != nil {
+                        t.Errorf("%s: неожиданная ошибка %v", input, err)
+                        continue
+                }
+                if math.Abs(odds.Probability()-expected) > 1e-9 {
+                        t.Errorf("%s: ожидается %v, получено %v", input, expected, odds.Probability())
+                }
+        }
+
+        var odds WinningOdds
+        if err := json.Unmarshal([]byte(`"1:0"`), &odds); err == nil {
+                t.Error("Шансы 1:0 должны приводить к ошибке")
+        }
+}
+
+// TestConvertMomentalToLottery проверяет конвертацию скретч-карты в domain.Lottery
+func TestConvertMomentalToLottery(t *testing.T) {
+        var resp MomentalResponse
+        data := `{"requestStatus": "success", "momentalCards": [
+                {"name": "zolotaya-podkova", "displayName": "Золотая подкова", "ticketPrice": 10000,
+                 "maxPrize": 100000000, "availableTickets": 250, "totalTickets": 1000, "oddsOfWinning": "1:4"},
+                {"name": "sold-out", "ticketPrice": 5000, "maxPrize": 50000, "availableTickets": 0,
+                 "totalTickets": 500, "oddsOfWinning": 0.3}
+        ]}`
+        if err := json.Unmarshal([]byte(data), &resp); err != nil {
+                t.Fatalf("Не удалось разобрать ответ: %v", err)
+        }
+
+        client := NewStolotoClient("http://localhost")
+        lottery := client.ConvertMomentalToLottery(resp.MomentalCards[0])
+
+        if lottery.Type != domain.LotteryTypeInstant {
+                t.Errorf("Тип: ожидается %s, получено %s", domain.LotteryTypeInstant, lottery.Type)
+        }
+        if lottery.TicketPrice != 100 || lottery.MaxJackpot != 1000000 {
+                t.Errorf("Цены сконвертированы неверно: билет %v, приз %v", lottery.TicketPrice, lottery.MaxJackpot)
+        }
+        if math.Abs(lottery.WinProbability-25) > 1e-9 {
+                t.Errorf("WinProbability в процентах: ожидается 25, получено %v", lottery.WinProbability)
+        }
+        if lottery.TicketsRemaining == nil || *lottery.TicketsRemaining != 0.25 {
+                t.Errorf("Доля оставшихся билетов: ожидается 0.25, получено %v", lottery.TicketsRemaining)
+        }
+        if !lottery.IsActive {
+                t.Error("Лотерея с билетами в продаже должна быть активной")
+        }
+
+        soldOut := client.ConvertMomentalToLottery(resp.MomentalCards[1])
+        if soldOut.IsActive {
+                t.Error("Распроданная лотерея не должна быть активной")
+        }
+        if soldOut.Name != "sold-out" {
+                t.Errorf("Без displayName используется name: получено %s", soldOut.Name)
+        }
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "sync"
+        "time"
+)
+
+// ErrRateLimited возвращается, когда запрос к StolotoAPI пришлось бы ждать дольше допустимого
+var ErrRateLimited = errors.New("превышен лимит запросов к StolotoAPI")
+
+// RateLimitConfig задает ограничение исходящих запросов к StolotoAPI
+type RateLimitConfig struct {
+        RequestsPerMinute int           // Средняя скорость; 0 - без ограничения
+        Burst             int           // Сколько запросов можно выполнить подряд без ожидания
+        MaxWait           time.Duration // Сколько запрос может ждать своей очереди, прежде чем уйти в fallback
+}
+
+// DefaultRateLimitConfig возвращает ограничение из документации StolotoAPI: не больше 60 запросов в минуту
+func DefaultRateLimitConfig() RateLimitConfig {
+        return RateLimitConfig{
+                RequestsPerMinute: 60,
+                Burst:             10,
+                MaxWait:           2 * time.Second,
+        }
+}
+
+// TokenBucket - ограничитель скорости "ведро токенов", общий для всех горутин клиента
+// Токены восстанавливаются со скоростью RequestsPerMinute; ведро вмещает Burst токенов.
+// Запрос резервирует токен заранее, поэтому очередь ожидающих запросов честная.
+type TokenBucket struct {
+        mu      sync.Mutex
+        rate    float64 // токенов в секунду
+        burst   float64
+        tokens  float64 // может быть отрицательным: токены, зарезервированные ожидающими запросами
+        last    time.Time
+        maxWait time.Duration
+        now     func() time.Time
+}
+
+// NewTokenBucket создает новый экземпляр TokenBucket (nil, если ограничение выключено)
+func NewTokenBucket(config RateLimitConfig) *TokenBucket {
+        if config.RequestsPerMinute <= 0 {
+                return nil
+        }
+        if config.Burst <= 0 {
+                config.Burst = 1
+        }
+
+        return &TokenBucket{
+                rate:    float64(config.RequestsPerMinute) / 60.0,
+                burst:   float64(config.Burst),
+                tokens:  float64(config.Burst),
+                last:    time.Now(),
+                maxWait: config.MaxWait,
+                now:     time.Now,
+        }
+}
+
+// Wait ждет своей очереди на запрос
+// Возвращает ErrRateLimited сразу, если ожидание превысило бы MaxWait,
+// и ошибку контекста, если запрос отменен во время ожидания
+func (b *TokenBucket) Wait(ctx context.Context) error {
+        if b == nil {
+                return nil
+        }
+
+        delay, err := b.reserve()
+        if err != nil {
+                return err
+        }
+        if delay <= 0 {
+                return nil
+        }
+
+        if err := SleepContext(ctx, delay); err != nil {
+                b.cancelReservation()
+                return err
+        }
+        return nil
+}
+
+// reserve резервирует токен и возвращает, сколько нужно подождать до его появления
+func (b *TokenBucket) reserve() (time.Duration, error) {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        b.refill()
+        b.tokens--
+        if b.tokens >= 0 {
+                return 0, nil
+        }
+
+        delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+        if delay > b.maxWait {
+                b.tokens++
+                return 0, ErrRateLimited
+        }
+        return delay, nil
+}
+
+// cancelReservation возвращает токен запроса, который не дождался очереди
+func (b *TokenBucket) cancelReservation() {
+        b.mu.Lock()
+        defer b.mu.Unlock()
+
+        b.refill()
+        b.tokens++
+        if b.tokens > b.burst {
+                b.tokens = b.burst
+        }
+}
+
+// refill начисляет токены за прошедшее время (вызывается под mu)
+func (b *TokenBucket) refill() {
+        now := b.now()
+        elapsed := now.Sub(b.last).Seconds()
+        b.last = now
+        if elapsed <= 0 {
+                return
+        }
+
+        b.tokens += elapsed * b.rate
+        if b.tokens > b.burst {
+                b.tokens = b.burst
+        }
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "sync"
+        "testing"
+        "time"
+)
+
+// TestTokenBucketReserve проверяет расчет очереди и отказ при слишком долгом ожидании
+func TestTokenBucketReserve(t *testing.T) {
+        now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+        bucket := NewTokenBucket(RateLimitConfig{RequestsPerMinute: 60, Burst: 2, MaxWait: 1500 * time.Millisecond})
+        bucket.now = func() time.Time { return now }
+        bucket.last = now
+
+        // Два запроса проходят сразу (burst)
+        for i := 0; i < 2; i++ {
+                if delay, err := bucket.reserve(); err != nil || delay != 0 {
+                        t.Fatalf("Запрос %d должен пройти без ожидания: %v %v", i+1, delay, err)
+                }
+        }
+
+        // Третий ждет секунду, четвертому пришлось бы ждать две - это дольше MaxWait
+        if delay, err := bucket.reserve(); err != nil || delay != time.Second {
+                t.Fatalf("Третий запрос должен ждать 1s, получено %v %v", delay, err)
+        }
+        if _, err := bucket.reserve(); !errors.Is(err, ErrRateLimited) {
+                t.Fatalf("Четвертый запрос должен получить ErrRateLimited, получено %v", err)
+        }
+
+        // Через 2 секунды очередь рассасывается
+        now = now.Add(2 * time.Second)
+        if delay, err := bucket.reserve(); err != nil || delay != 0 {
+                t.Fatalf("После паузы запрос должен пройти сразу: %v %v", delay, err)
+        }
+
+        if NewTokenBucket(RateLimitConfig{RequestsPerMinute: 0}) != nil {
+                t.Error("RequestsPerMinute = 0 должно отключать ограничение")
+        }
+}
+
+// TestTokenBucketSharedAcrossGoroutines проверяет что ограничение общее для всех горутин
+func TestTokenBucketSharedAcrossGoroutines(t *testing.T) {
+        // 1200 запросов в минуту = 1 запрос в 50ms
+        bucket := NewTokenBucket(RateLimitConfig{RequestsPerMinute: 1200, Burst: 1, MaxWait: time.Second})
+
+        start := time.Now()
+        var wg sync.WaitGroup
+        for i := 0; i < 5; i++ {
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        if err := bucket.Wait(context.Background()); err != nil {
+                                t.Errorf("Wait вернул ошибку: %v", err)
+                        }
+                }()
+        }
+        wg.Wait()
+
+        // Первый запрос проходит сразу, остальные четыре - с интервалом 50ms
+        if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+                t.Errorf("5 запросов при 20 rps должны занять не меньше 200ms, заняли %v", elapsed)
+        }
+}
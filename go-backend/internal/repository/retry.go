@@ -0,0 +1,136 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "math/rand"
+        "net/http"
+        "strconv"
+        "strings"
+        "time"
+)
+
+// Endpoint - метод StolotoClient, для которого настраивается политика повторов
+type Endpoint string
+
+const (
+        EndpointDraws         Endpoint = "draws"          // GET /api/draws/
+        EndpointDraw          Endpoint = "draw"           // GET /api/draw/?name=&number=
+        EndpointLatestDraw    Endpoint = "draw-latest"    // GET /api/draw/latest
+        EndpointPrelatestDraw Endpoint = "draw-prelatest" // GET /api/draw/prelatest
+        EndpointMomental      Endpoint = "momental"       // GET /api/draw/momental
+)
+
+// RetryPolicy описывает повторные попытки запроса к StolotoAPI
+// Повторяются только временные сбои: сетевые ошибки, 429 и 5xx. Все запросы клиента - GET,
+// поэтому они идемпотентны. Паузы между попытками - экспоненциальные с полным джиттером
+// (случайная пауза от 0 до BaseDelay*2^(n-1), но не больше MaxDelay).
+type RetryPolicy struct {
+        MaxAttempts int           // Общее число попыток, включая первую (1 - без повторов)
+        BaseDelay   time.Duration // Базовая пауза перед второй попыткой
+        MaxDelay    time.Duration // Максимальная пауза; Retry-After больше этого значения не ждем
+}
+
+// DefaultRetryPolicy возвращает политику по умолчанию
+func DefaultRetryPolicy() RetryPolicy {
+        return RetryPolicy{
+                MaxAttempts: 3,
+                BaseDelay:   500 * time.Millisecond,
+                MaxDelay:    4 * time.Second,
+        }
+}
+
+// defaultRetryPolicies - политики по умолчанию для методов клиента
+// Каталог игр нужен для каждой страницы, поэтому для него попыток больше;
+// отдельные тиражи дешевле запросить повторно позже
+func defaultRetryPolicies() map[Endpoint]RetryPolicy {
+        single := RetryPolicy{MaxAttempts: 2, BaseDelay: 300 * time.Millisecond, MaxDelay: 2 * time.Second}
+        return map[Endpoint]RetryPolicy{
+                EndpointDraws:         DefaultRetryPolicy(),
+                EndpointMomental:      single,
+                EndpointDraw:          single,
+                EndpointLatestDraw:    single,
+                EndpointPrelatestDraw: single,
+        }
+}
+
+// StatusError - StolotoAPI ответил кодом, отличным от 200
+type StatusError struct {
+        StatusCode int
+        Body       string
+        RetryAfter time.Duration // Значение заголовка Retry-After (0, если его нет)
+}
+
+// Error реализует интерфейс error
+func (e *StatusError) Error() string {
+        return fmt.Sprintf("StolotoAPI вернул код %d: %s", e.StatusCode, e.Body)
+}
+
+// Temporary сообщает, имеет ли смысл повторить запрос
+func (e *StatusError) Temporary() bool {
+        return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// IsNotFound проверяет, что StolotoAPI ответил 404 (например, тиража с таким номером нет)
+func IsNotFound(err error) bool {
+        var statusErr *StatusError
+        return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// jitter возвращает случайное число из [0, 1); переопределяется в тестах
+var jitter = rand.Float64
+
+// backoff вычисляет паузу перед попыткой attempt+1 с полным джиттером
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+        ceiling := p.BaseDelay
+        for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+                ceiling *= 2
+        }
+        if p.MaxDelay > 0 && ceiling > p.MaxDelay {
+                ceiling = p.MaxDelay
+        }
+        return time.Duration(jitter() * float64(ceiling))
+}
+
+// parseRetryAfter разбирает заголовок Retry-After (секунды или HTTP-дата)
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+        value = strings.TrimSpace(value)
+        if value == "" {
+                return 0, false
+        }
+
+        if seconds, err := strconv.Atoi(value); err == nil {
+                if seconds < 0 {
+                        return 0, false
+                }
+                return time.Duration(seconds) * time.Second, true
+        }
+
+        if date, err := http.ParseTime(value); err == nil {
+                delay := date.Sub(now)
+                if delay < 0 {
+                        delay = 0
+                }
+                return delay, true
+        }
+
+        return 0, false
+}
+
+// SleepContext ждет указанное время или отмену контекста (ошибка - ctx.Err())
+func SleepContext(ctx context.Context, delay time.Duration) error {
+        if delay <= 0 {
+                return ctx.Err()
+        }
+
+        timer := time.NewTimer(delay)
+        defer timer.Stop()
+
+        select {
+        case <-ctx.Done():
+                return ctx.Err()
+        case <-timer.C:
+                return nil
+        }
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "net/http"
+        "net/http/httptest"
+        "sync/atomic"
+        "testing"
+        "time"
+)
+
+// fastRetries - политика без заметных пауз для тестов
+var fastRetries = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+
+// TestRetryOnlyTransientFailures проверяет что повторяются только 5xx/429, но не 4xx
+func TestRetryOnlyTransientFailures(t *testing.T) {
+        var calls int32
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                n := atomic.AddInt32(&calls, 1)
+                switch {
+                case r.URL.Query().Get("number") == "bad":
+                        w.WriteHeader(http.StatusBadRequest)
+                case n == 1:
+                        w.WriteHeader(http.StatusServiceUnavailable)
+                case n == 2:
+                        w.Header().Set("Retry-After", "0")
+                        w.WriteHeader(http.StatusTooManyRequests)
+                default:
+                        w.Write([]byte(`{"requestStatus": "success", "draw": {"number": 7}}`))
+                }
+        }))
+        defer server.Close()
+
+        client := NewStolotoClient(server.URL, WithRetryPolicy(EndpointDraw, fastRetries))
+        ctx := context.Background()
+
+        draw, err := client.GetDraw(ctx, "6x45", "7")
+        if err != nil {
+                t.Fatalf("После 503 и 429 третья попытка должна быть успешной: %v", err)
+        }
+        if draw.Number != 7 || atomic.LoadInt32(&calls) != 3 {
+                t.Fatalf("Ожидается тираж 7 за 3 попытки, получено тираж %d за %d", draw.Number, calls)
+        }
+
+        atomic.StoreInt32(&calls, 0)
+        _, err = client.GetDraw(ctx, "6x45", "bad")
+        var statusErr *StatusError
+        if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+                t.Fatalf("Ожидается StatusError 400, получено %v", err)
+        }
+        if atomic.LoadInt32(&calls) != 1 {
+                t.Errorf("400 не должен повторяться, выполнено %d запросов", calls)
+        }
+}
+
+// TestRetryWaitIsCancellable проверяет что пауза между попытками прерывается отменой контекста
+func TestRetryWaitIsCancellable(t *testing.T) {
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+                w.Header().Set("Retry-After", "5")
+                w.WriteHeader(http.StatusServiceUnavailable)
+        }))
+        defer server.Close()
+
+        client := NewStolotoClient(server.URL, WithRetryPolicy(EndpointLatestDraw, RetryPolicy{
+                MaxAttempts: 3,
+                BaseDelay:   time.Millisecond,
+                MaxDelay:    time.Minute,
+        }))
+
+        ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+        defer cancel()
+
+        start := time.Now()
+        _, err := client.GetLatestDraw(ctx, "6x45")
+        if !errors.Is(err, context.DeadlineExceeded) {
+                t.Fatalf("Ожидается ошибка отмены контекста, получено %v", err)
+        }
+        if elapsed := time.Since(start); elapsed > time.Second {
+                t.Errorf("Ожидание Retry-After должно прерываться отменой контекста, прошло %v", elapsed)
+        }
+}
+
+// TestRetryBackoffAndRetryAfter проверяет расчет пауз
+func TestRetryBackoffAndRetryAfter(t *testing.T) {
+        original := jitter
+        jitter = func() float64 { return 0.999999 }
+        defer func() { jitter = original }()
+
+        policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+        expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+        for i, ceiling := range expected {
+                delay := policy.backoff(i + 1)
+                if delay > ceiling || delay < ceiling-time.Millisecond {
+                        t.Errorf("Попытка %d: пауза должна быть чуть меньше %v, получено %v", i+1, ceiling, delay)
+                }
+        }
+
+        now := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+        if delay, ok := parseRetryAfter("3", now); !ok || delay != 3*time.Second {
+                t.Errorf("Retry-After в секундах разобран неверно: %v %v", delay, ok)
+        }
+        if delay, ok := parseRetryAfter("Thu, 20 Nov 2025 12:00:10 GMT", now); !ok || delay != 10*time.Second {
+                t.Errorf("Retry-After в виде даты разобран неверно: %v %v", delay, ok)
+        }
+        if _, ok := parseRetryAfter("soon", now); ok {
+                t.Error("Некорректный Retry-After должен игнорироваться")
+        }
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+        "encoding/json"
+        "fmt"
+        "os"
+        "path/filepath"
+        "sync"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// CatalogSnapshot - последний успешно загруженный каталог лотерей
+type CatalogSnapshot struct {
+        FetchedAt time.Time        `json:"fetchedAt"` // Когда каталог был получен из источников
+        Lotteries []domain.Lottery `json:"lotteries"`
+}
+
+// SnapshotStore хранит снимок каталога в JSON файле на диске
+// Снимок используется, когда StolotoAPI недоступен сразу после запуска сервера
+type SnapshotStore struct {
+        path string
+        mu   sync.Mutex
+}
+
+// NewSnapshotStore создает новый экземпляр SnapshotStore
+func NewSnapshotStore(path string) *SnapshotStore {
+        return &SnapshotStore{path: path}
+}
+
+// Path возвращает путь к файлу снимка
+func (s *SnapshotStore) Path() string {
+        return s.path
+}
+
+// Save атомарно записывает снимок: сначала во временный файл, затем переименовывает его,
+// чтобы падение сервера во время записи не испортило предыдущий снимок
+func (s *SnapshotStore) Save(snapshot CatalogSnapshot) error {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        data, err := json.Marshal(snapshot)
+        if err != nil {
+                return fmt.Errorf("ошибка сериализации снимка: %w", err)
+        }
+
+        if err := writeFileAtomic(s.path, data); err != nil {
+                return fmt.Errorf("ошибка сохранения снимка: %w", err)
+        }
+        return nil
+}
+
+// writeFileAtomic записывает файл через временный файл и переименование,
+// чтобы падение сервера во время записи не испортило предыдущую версию
+func writeFileAtomic(path string, data []byte) error {
+        dir := filepath.Dir(path)
+        if err := os.MkdirAll(dir, 0o755); err != nil {
+                return fmt.Errorf("ошибка создания каталога %s: %w", dir, err)
+        }
+
+        tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+        if err != nil {
+                return fmt.Errorf("ошибка создания временного файла: %w", err)
+        }
+        defer os.Remove(tmp.Name())
+
+        if _, err := tmp.Write(data); err != nil {
+                tmp.Close()
+                return fmt.Errorf("ошибка записи файла: %w", err)
+        }
+        if err := tmp.Close(); err != nil {
+                return fmt.Errorf("ошибка записи файла: %w", err)
+        }
+        return os.Rename(tmp.Name(), path)
+}
+
+// Load читает снимок с диска
+// Если снимка еще нет, возвращает ошибку, для которой errors.Is(err, os.ErrNotExist) == true
+func (s *SnapshotStore) Load() (*CatalogSnapshot, error) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        data, err := os.ReadFile(s.path)
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения снимка: %w", err)
+        }
+
+        var snapshot CatalogSnapshot
+        if err := json.Unmarshal(data, &snapshot); err != nil {
+                return nil, fmt.Errorf("ошибка парсинга снимка %s: %w", s.path, err)
+        }
+        return &snapshot, nil
+}
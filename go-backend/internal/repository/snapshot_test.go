@@ -0,0 +1,49 @@
+package repository
+
+import (
+        "errors"
+        "os"
+        "path/filepath"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestSnapshotStoreRoundTrip проверяет сохранение и чтение снимка каталога
+func TestSnapshotStoreRoundTrip(t *testing.T) {
+        store := NewSnapshotStore(filepath.Join(t.TempDir(), "nested", "snapshot.json"))
+
+        if _, err := store.Load(); !errors.Is(err, os.ErrNotExist) {
+                t.Fatalf("Отсутствующий снимок должен давать os.ErrNotExist, получено %v", err)
+        }
+
+        fetchedAt := time.Date(2025, 11, 20, 18, 0, 0, 0, time.UTC)
+        snapshot := CatalogSnapshot{
+                FetchedAt: fetchedAt,
+                Lotteries: []domain.Lottery{{ID: "6x45", Name: "Гослото 6 из 45", TicketPrice: 100}},
+        }
+        if err := store.Save(snapshot); err != nil {
+                t.Fatalf("Не удалось сохранить снимок: %v", err)
+        }
+
+        loaded, err := store.Load()
+        if err != nil {
+                t.Fatalf("Не удалось прочитать снимок: %v", err)
+        }
+        if !loaded.FetchedAt.Equal(fetchedAt) {
+                t.Errorf("FetchedAt: ожидается %v, получено %v", fetchedAt, loaded.FetchedAt)
+        }
+        if len(loaded.Lotteries) != 1 || loaded.Lotteries[0].ID != "6x45" {
+                t.Errorf("Ожидается одна лотерея 6x45, получено %+v", loaded.Lotteries)
+        }
+
+        // Временные файлы не должны оставаться рядом со снимком
+        entries, err := os.ReadDir(filepath.Dir(store.Path()))
+        if err != nil {
+                t.Fatalf("Не удалось прочитать каталог: %v", err)
+        }
+        if len(entries) != 1 {
+                t.Errorf("Ожидается только файл снимка, найдено %d файлов", len(entries))
+        }
+}
@@ -3,38 +3,82 @@ package repository
 import (
         "context"
         "encoding/json"
+        "errors"
         "fmt"
         "io"
         "log"
         "math"
         "net/http"
+        "net/url"
         "strings"
         "time"
 
         "github.com/stoloto-recommendations/backend/internal/domain"
 )
 
-const (
-        // Максимальное количество попыток при ошибке
-        maxRetries = 3
-        // Задержка между попытками
-        retryDelay = 500 * time.Millisecond
-)
-
 // StolotoClient предоставляет HTTP клиент для работы с StolotoAPI
 type StolotoClient struct {
-        baseURL    string
-        httpClient *http.Client
+        baseURL       string
+        httpClient    *http.Client
+        breaker       *CircuitBreaker
+        limiter       *TokenBucket
+        retryPolicies map[Endpoint]RetryPolicy
+        catalog       *GameCatalog
+}
+
+// ClientOption настраивает StolotoClient
+type ClientOption func(*StolotoClient)
+
+// WithCircuitBreaker задает пороги circuit breaker для запросов к StolotoAPI
+func WithCircuitBreaker(config CircuitBreakerConfig) ClientOption {
+        return func(c *StolotoClient) {
+                c.breaker = NewCircuitBreaker(config)
+        }
+}
+
+// WithRateLimit задает ограничение исходящих запросов к StolotoAPI
+// (RequestsPerMinute = 0 отключает ограничение)
+func WithRateLimit(config RateLimitConfig) ClientOption {
+        return func(c *StolotoClient) {
+                c.limiter = NewTokenBucket(config)
+        }
+}
+
+// WithRetryPolicy задает политику повторов для отдельного метода клиента
+func WithRetryPolicy(endpoint Endpoint, policy RetryPolicy) ClientOption {
+        return func(c *StolotoClient) {
+                c.retryPolicies[endpoint] = policy
+        }
+}
+
+// WithGameCatalog задает каталог игр, на который накладываются данные StolotoAPI
+func WithGameCatalog(catalog *GameCatalog) ClientOption {
+        return func(c *StolotoClient) {
+                c.catalog = catalog
+        }
 }
 
 // NewStolotoClient создает новый экземпляр StolotoClient
-func NewStolotoClient(baseURL string) *StolotoClient {
-        return &StolotoClient{
+func NewStolotoClient(baseURL string, opts ...ClientOption) *StolotoClient {
+        c := &StolotoClient{
                 baseURL: baseURL,
                 httpClient: &http.Client{
                         Timeout: 10 * time.Second,
                 },
+                breaker:       NewCircuitBreaker(DefaultCircuitBreakerConfig()),
+                limiter:       NewTokenBucket(DefaultRateLimitConfig()),
+                retryPolicies: defaultRetryPolicies(),
+                catalog:       DefaultGameCatalog(),
         }
+        for _, opt := range opts {
+                opt(c)
+        }
+        return c
+}
+
+// BreakerSnapshot возвращает состояние circuit breaker для мониторинга
+func (c *StolotoClient) BreakerSnapshot() BreakerSnapshot {
+        return c.breaker.Snapshot()
 }
 
 // kopecksToRoubles конвертирует копейки в рубли с правильной точностью
@@ -53,65 +97,9 @@ func kopecksToRoubles64(kopecks int64) float64 {
 }
 
 // ConvertGameToLottery конвертирует данные из StolotoAPI в domain.Lottery
+// Тип, расписание, тексты и правила берутся из каталога игр, цены и джекпот - из StolotoAPI
 func (c *StolotoClient) ConvertGameToLottery(game Game) domain.Lottery {
-        // Конвертируем цены из копеек в рубли
-        ticketPrice := kopecksToRoubles(game.TicketPrice)
-        currentJackpot := kopecksToRoubles64(game.Jackpot)
-        
-        // Определяем тип лотереи по имени игры
-        lotteryType := determineLotteryType(game.Name)
-        
-        // Конвертируем частоту розыгрышей
-        drawFrequency := convertDrawFrequency(game.DrawFrequency)
-        
-        // Генерируем описание
-        description := generateDescription(game.DisplayName, lotteryType)
-        
-        // Генерируем правила
-        rules := generateRules(game.DisplayName, lotteryType)
-        
-        // Создаем структуру призов (упрощенная версия)
-        prizeStructure := generatePrizeStructure(game.DisplayName, currentJackpot)
-        
-        // Определяем вероятность выигрыша (примерные значения)
-        winProbability := estimateWinProbability(game.Name)
-        
-        return domain.Lottery{
-                ID:             game.Name,
-                Name:           game.DisplayName,
-                Type:           lotteryType,
-                TicketPrice:    ticketPrice,
-                MaxJackpot:     currentJackpot, // Используем текущий джекпот как максимальный
-                CurrentJackpot: currentJackpot,
-                WinProbability: winProbability,
-                DrawFrequency:  drawFrequency,
-                Description:    description,
-                Rules:          rules,
-                PrizeStructure: prizeStructure,
-                ImageURL:       nil,
-                IsActive:       true,
-        }
-}
-
-// determineLotteryType определяет тип лотереи по имени
-func determineLotteryType(name string) domain.LotteryType {
-        name = strings.ToLower(name)
-        
-        if strings.Contains(name, "6x45") || strings.Contains(name, "5x36") || 
-           strings.Contains(name, "4x20") || strings.Contains(name, "7x49") {
-                return domain.LotteryTypeNumbered
-        }
-        
-        if strings.Contains(name, "rapido") || strings.Contains(name, "12x24") {
-                return domain.LotteryTypeInstant
-        }
-        
-        if strings.Contains(name, "top3") {
-                return domain.LotteryTypeSportloto
-        }
-        
-        // По умолчанию - тиражная
-        return domain.LotteryTypeDrawBased
+        return c.catalog.Merge(game)
 }
 
 // convertDrawFrequency конвертирует частоту розыгрышей
@@ -155,104 +143,120 @@ func generateRules(displayName string, lotteryType domain.LotteryType) string {
         return fmt.Sprintf("Купите билет %s, выберите числа согласно правилам игры. Розыгрыш проходит согласно расписанию. При совпадении всех чисел вы выигрываете главный приз!", displayName)
 }
 
-// generatePrizeStructure генерирует структуру призов
-func generatePrizeStructure(displayName string, jackpot float64) []domain.PrizeCategory {
-        jackpotMln := jackpot / 1000000.0
-        
-        return []domain.PrizeCategory{
-                {
-                        Category:    "Джекпот",
-                        Prize:       fmt.Sprintf("%.1f млн ₽", jackpotMln),
-                        Probability: "1:1000000",
-                },
-                {
-                        Category:    "2 категория",
-                        Prize:       fmt.Sprintf("%.0f ₽", jackpot*0.1),
-                        Probability: "1:100000",
-                },
-                {
-                        Category:    "3 категория",
-                        Prize:       "10000 ₽",
-                        Probability: "1:10000",
-                },
-                {
-                        Category:    "4 категория",
-                        Prize:       "1000 ₽",
-                        Probability: "1:1000",
-                },
+// doRequest выполняет одну попытку GET запроса через circuit breaker и ограничитель скорости
+// Ответ со статусом 5xx считается сбоем upstream; 4xx - нет (upstream жив, запрос некорректен)
+func (c *StolotoClient) doRequest(ctx context.Context, url string) (*http.Response, error) {
+        if err := c.breaker.Allow(); err != nil {
+                return nil, err
         }
-}
 
-// estimateWinProbability оценивает вероятность выигрыша
-func estimateWinProbability(name string) float64 {
-        name = strings.ToLower(name)
-        
-        // Примерные вероятности для разных типов лотерей
-        if strings.Contains(name, "6x45") {
-                return 0.00001 // 0.001%
+        // Ждем очереди уже после breaker: при недоступном upstream ждать нечего
+        if err := c.limiter.Wait(ctx); err != nil {
+                c.breaker.Release()
+                return nil, err
         }
-        
-        if strings.Contains(name, "5x36") {
-                return 0.0001 // 0.01%
+
+        req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+        if err != nil {
+                c.breaker.Release()
+                return nil, fmt.Errorf("ошибка создания запроса: %w", err)
         }
-        
-        if strings.Contains(name, "4x20") {
-                return 0.001 // 0.1%
+
+        resp, err := c.httpClient.Do(req)
+        if err != nil {
+                if ctx.Err() != nil {
+                        // Запрос отменен вызывающей стороной - это не сбой upstream
+                        c.breaker.Release()
+                } else {
+                        c.breaker.Record(true)
+                }
+                return nil, err
         }
-        
-        if strings.Contains(name, "rapido") || strings.Contains(name, "12x24") {
-                return 0.01 // 1%
+
+        c.breaker.Record(resp.StatusCode >= http.StatusInternalServerError)
+        return resp, nil
+}
+
+// retryPolicy возвращает политику повторов для метода клиента
+func (c *StolotoClient) retryPolicy(endpoint Endpoint) RetryPolicy {
+        policy, ok := c.retryPolicies[endpoint]
+        if !ok {
+                policy = DefaultRetryPolicy()
         }
-        
-        // По умолчанию
-        return 0.0001
+        if policy.MaxAttempts < 1 {
+                policy.MaxAttempts = 1
+        }
+        return policy
 }
 
-// doRequestWithRetry выполняет HTTP запрос с повторными попытками (до 3 раз)
-// После 3 неудачных попыток возвращает ошибку, которая триггерит fallback на моковые данные
-func (c *StolotoClient) doRequestWithRetry(ctx context.Context, url string) (*http.Response, error) {
+// doRequestWithRetry выполняет GET запрос по политике повторов метода endpoint
+// Повторяются только сетевые ошибки, 429 и 5xx; ожидание прерывается отменой ctx.
+// Если circuit breaker разомкнут или очередь ограничителя слишком длинная,
+// ошибка возвращается сразу, без повторов.
+// После исчерпания попыток возвращает ошибку, которая триггерит fallback на резервные данные
+func (c *StolotoClient) doRequestWithRetry(ctx context.Context, endpoint Endpoint, url string) (*http.Response, error) {
+        policy := c.retryPolicy(endpoint)
         var lastErr error
-        
-        for attempt := 1; attempt <= maxRetries; attempt++ {
-                req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-                if err != nil {
-                        return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+
+        for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+                resp, err := c.doRequest(ctx, url)
+                if errors.Is(err, ErrCircuitOpen) {
+                        log.Printf("Circuit breaker открыт, запрос к %s пропущен", url)
+                        return nil, err
+                }
+                if errors.Is(err, ErrRateLimited) {
+                        log.Printf("Лимит запросов к StolotoAPI исчерпан, запрос к %s пропущен", url)
+                        return nil, err
+                }
+                if err != nil && ctx.Err() != nil {
+                        return nil, fmt.Errorf("запрос к StolotoAPI отменен: %w", ctx.Err())
                 }
-                
-                resp, err := c.httpClient.Do(req)
+
+                delay := policy.backoff(attempt)
                 if err != nil {
-                        lastErr = err
-                        if attempt < maxRetries {
-                                log.Printf("Попытка %d/%d не удалась: %v. Повторная попытка через %v...", 
-                                        attempt, maxRetries, err, retryDelay * time.Duration(math.Pow(2, float64(attempt-1))))
-                                time.Sleep(retryDelay * time.Duration(math.Pow(2, float64(attempt-1))))
-                                continue
+                        lastErr = fmt.Errorf("ошибка выполнения запроса: %w", err)
+                } else {
+                        if resp.StatusCode == http.StatusOK {
+                                if attempt > 1 {
+                                        log.Printf("Успех на попытке %d/%d", attempt, policy.MaxAttempts)
+                                }
+                                return resp, nil
                         }
-                        log.Printf("Все %d попыток исчерпаны. Последняя ошибка: %v", maxRetries, err)
-                        return nil, fmt.Errorf("ошибка выполнения запроса после %d попыток: %w", maxRetries, err)
-                }
-                
-                if resp.StatusCode == http.StatusOK {
-                        if attempt > 1 {
-                                log.Printf("Успех на попытке %d/%d", attempt, maxRetries)
+
+                        body, _ := io.ReadAll(resp.Body)
+                        resp.Body.Close()
+                        statusErr := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+                        if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+                                statusErr.RetryAfter = retryAfter
+                        }
+                        lastErr = statusErr
+
+                        // 4xx (кроме 429) повторять бесполезно - запрос некорректен
+                        if !statusErr.Temporary() {
+                                return nil, lastErr
+                        }
+
+                        if statusErr.RetryAfter > 0 {
+                                if statusErr.RetryAfter > policy.MaxDelay {
+                                        log.Printf("StolotoAPI просит подождать %v - это дольше допустимых %v, прекращаем попытки", statusErr.RetryAfter, policy.MaxDelay)
+                                        return nil, lastErr
+                                }
+                                delay = statusErr.RetryAfter
                         }
-                        return resp, nil
                 }
-                
-                body, _ := io.ReadAll(resp.Body)
-                resp.Body.Close()
-                lastErr = fmt.Errorf("StolotoAPI вернул код %d: %s", resp.StatusCode, string(body))
-                
-                if attempt < maxRetries {
-                        log.Printf("Попытка %d/%d не удалась: статус %d. Повторная попытка через %v...", 
-                                attempt, maxRetries, resp.StatusCode, retryDelay * time.Duration(math.Pow(2, float64(attempt-1))))
-                        time.Sleep(retryDelay * time.Duration(math.Pow(2, float64(attempt-1))))
-                        continue
+
+                if attempt == policy.MaxAttempts {
+                        break
+                }
+
+                log.Printf("Попытка %d/%d не удалась: %v. Повторная попытка через %v...", attempt, policy.MaxAttempts, lastErr, delay)
+                if err := SleepContext(ctx, delay); err != nil {
+                        return nil, fmt.Errorf("запрос к StolotoAPI отменен: %w", err)
                 }
         }
-        
-        log.Printf("Все %d попыток исчерпаны. Последняя ошибка: %v", maxRetries, lastErr)
-        return nil, fmt.Errorf("не удалось выполнить запрос после %d попыток: %w", maxRetries, lastErr)
+
+        log.Printf("Все %d попыток исчерпаны. Последняя ошибка: %v", policy.MaxAttempts, lastErr)
+        return nil, fmt.Errorf("не удалось выполнить запрос после %d попыток: %w", policy.MaxAttempts, lastErr)
 }
 
 // DrawsResponse представляет ответ от /api/draws/
@@ -272,21 +276,13 @@ type Game struct {
         CompletedDraw *Draw  `json:"completedDraw,omitempty"`
 }
 
-// Draw представляет информацию о розыгрыше
-type Draw struct {
-        Number         int    `json:"number"`
-        DrawDate       string `json:"drawDate"`
-        Status         string `json:"status"`
-        WinningNumbers []int  `json:"winningNumbers,omitempty"`
-}
-
 // GetAllDraws получает список всех доступных игр из StolotoAPI
 // Использует retry логику для повышения надежности
 func (c *StolotoClient) GetAllDraws(ctx context.Context) (*DrawsResponse, error) {
         url := fmt.Sprintf("%s/api/draws/", c.baseURL)
 
         log.Printf("[StolotoClient] Fetching all draws from %s", url)
-        resp, err := c.doRequestWithRetry(ctx, url)
+        resp, err := c.doRequestWithRetry(ctx, EndpointDraws, url)
         if err != nil {
                 log.Printf("[StolotoClient] Request failed after retries: %v - will trigger mock fallback", err)
                 return nil, err
@@ -305,62 +301,47 @@ func (c *StolotoClient) GetAllDraws(ctx context.Context) (*DrawsResponse, error)
 
 // GetDraw получает информацию о конкретном розыгрыше
 func (c *StolotoClient) GetDraw(ctx context.Context, gameName string, drawNumber string) (*Draw, error) {
-        url := fmt.Sprintf("%s/api/draw/?name=%s&number=%s", c.baseURL, gameName, drawNumber)
-
-        req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-        if err != nil {
-                return nil, fmt.Errorf("ошибка создания запроса: %w", err)
-        }
-
-        resp, err := c.httpClient.Do(req)
-        if err != nil {
-                return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
-        }
-        defer resp.Body.Close()
-
-        if resp.StatusCode != http.StatusOK {
-                body, _ := io.ReadAll(resp.Body)
-                return nil, fmt.Errorf("StolotoAPI вернул код %d: %s", resp.StatusCode, string(body))
-        }
-
-        var result struct {
-                RequestStatus string `json:"requestStatus"`
-                Draw          *Draw  `json:"draw"`
-        }
-        if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-                return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-        }
-
-        return result.Draw, nil
+        requestURL := fmt.Sprintf("%s/api/draw/?name=%s&number=%s", c.baseURL, url.QueryEscape(gameName), url.QueryEscape(drawNumber))
+        return c.fetchDraw(ctx, EndpointDraw, requestURL, gameName)
 }
 
 // GetLatestDraw получает последний розыгрыш для указанной игры
 func (c *StolotoClient) GetLatestDraw(ctx context.Context, gameName string) (*Draw, error) {
-        url := fmt.Sprintf("%s/api/draw/latest?name=%s", c.baseURL, gameName)
+        requestURL := fmt.Sprintf("%s/api/draw/latest?name=%s", c.baseURL, url.QueryEscape(gameName))
+        return c.fetchDraw(ctx, EndpointLatestDraw, requestURL, gameName)
+}
 
-        req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-        if err != nil {
-                return nil, fmt.Errorf("ошибка создания запроса: %w", err)
-        }
+// GetPrelatestDraw получает предпоследний розыгрыш для указанной игры
+func (c *StolotoClient) GetPrelatestDraw(ctx context.Context, gameName string) (*Draw, error) {
+        requestURL := fmt.Sprintf("%s/api/draw/prelatest?name=%s", c.baseURL, url.QueryEscape(gameName))
+        return c.fetchDraw(ctx, EndpointPrelatestDraw, requestURL, gameName)
+}
 
-        resp, err := c.httpClient.Do(req)
+// fetchDraw выполняет запрос к одному из эндпоинтов /api/draw/ и декодирует тираж
+func (c *StolotoClient) fetchDraw(ctx context.Context, endpoint Endpoint, requestURL string, gameName string) (*Draw, error) {
+        resp, err := c.doRequestWithRetry(ctx, endpoint, requestURL)
         if err != nil {
-                return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
+                return nil, err
         }
         defer resp.Body.Close()
 
-        if resp.StatusCode != http.StatusOK {
-                body, _ := io.ReadAll(resp.Body)
-                return nil, fmt.Errorf("StolotoAPI вернул код %d: %s", resp.StatusCode, string(body))
-        }
-
         var result struct {
                 RequestStatus string `json:"requestStatus"`
                 Draw          *Draw  `json:"draw"`
         }
         if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+                log.Printf("[StolotoClient] JSON unmarshal failed for %s: %v", requestURL, err)
                 return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
         }
 
+        if result.Draw == nil {
+                return nil, fmt.Errorf("StolotoAPI не вернул данные тиража (requestStatus: %s)", result.RequestStatus)
+        }
+
+        // gameName есть не во всех ответах - подставляем запрошенную игру
+        if result.Draw.GameName == "" {
+                result.Draw.GameName = gameName
+        }
+
         return result.Draw, nil
 }
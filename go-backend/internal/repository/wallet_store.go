@@ -0,0 +1,216 @@
+package repository
+
+import (
+        "context"
+        "encoding/json"
+        "errors"
+        "fmt"
+        "os"
+        "path/filepath"
+        "regexp"
+        "sort"
+        "sync"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+var (
+        // ErrTicketNotFound возвращается, если билета нет в кошельке пользователя
+        ErrTicketNotFound = errors.New("билет не найден")
+        // ErrInvalidUserID возвращается для идентификатора пользователя недопустимого формата
+        ErrInvalidUserID = errors.New("некорректный идентификатор пользователя")
+)
+
+// userIDPattern - допустимые идентификаторы пользователей (клиент генерирует user_<время>_<случайная строка>)
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// WalletStore - хранилище билетов пользователей
+// Билеты пользователя уникальны по ID
+type WalletStore interface {
+        // Add добавляет билет в кошелек пользователя
+        Add(ctx context.Context, userID string, ticket domain.WalletTicket) error
+        // Get возвращает билет или ErrTicketNotFound
+        Get(ctx context.Context, userID, ticketID string) (*domain.WalletTicket, error)
+        // Update изменяет билет под блокировкой; если update возвращает ошибку, билет не сохраняется
+        Update(ctx context.Context, userID, ticketID string, update func(ticket *domain.WalletTicket) error) error
+        // Delete удаляет билет или возвращает ErrTicketNotFound
+        Delete(ctx context.Context, userID, ticketID string) error
+        // List возвращает все билеты пользователя
+        List(ctx context.Context, userID string) ([]domain.WalletTicket, error)
+        // Users возвращает пользователей, у которых есть кошелек
+        Users(ctx context.Context) ([]string, error)
+}
+
+// ValidateUserID проверяет формат идентификатора пользователя
+func ValidateUserID(userID string) error {
+        if !userIDPattern.MatchString(userID) {
+                return fmt.Errorf("%w: %q", ErrInvalidUserID, userID)
+        }
+        return nil
+}
+
+// FileWalletStore хранит кошельки в JSON файлах: по одному файлу на пользователя (<dir>/<userID>.json)
+// Файл перезаписывается атомарно при каждом изменении; кошелек загружается в память при первом обращении.
+type FileWalletStore struct {
+        dir     string
+        mu      sync.Mutex
+        wallets map[string][]domain.WalletTicket // Загруженные кошельки
+}
+
+// NewFileWalletStore создает новый экземпляр FileWalletStore
+func NewFileWalletStore(dir string) (*FileWalletStore, error) {
+        if err := os.MkdirAll(dir, 0o755); err != nil {
+                return nil, fmt.Errorf("ошибка создания каталога кошельков %s: %w", dir, err)
+        }
+        return &FileWalletStore{
+                dir:     dir,
+                wallets: make(map[string][]domain.WalletTicket),
+        }, nil
+}
+
+// Add добавляет билет в кошелек пользователя
+func (s *FileWalletStore) Add(ctx context.Context, userID string, ticket domain.WalletTicket) error {
+        return s.modify(userID, func(tickets []domain.WalletTicket) ([]domain.WalletTicket, error) {
+                for _, existing := range tickets {
+                        if existing.ID == ticket.ID {
+                                return nil, fmt.Errorf("билет %s уже есть в кошельке", ticket.ID)
+                        }
+                }
+                return append(tickets, ticket), nil
+        })
+}
+
+// Get возвращает билет пользователя по ID
+func (s *FileWalletStore) Get(ctx context.Context, userID, ticketID string) (*domain.WalletTicket, error) {
+        tickets, err := s.List(ctx, userID)
+        if err != nil {
+                return nil, err
+        }
+        for _, ticket := range tickets {
+                if ticket.ID == ticketID {
+                        return &ticket, nil
+                }
+        }
+        return nil, ErrTicketNotFound
+}
+
+// Update изменяет билет пользователя
+func (s *FileWalletStore) Update(ctx context.Context, userID, ticketID string, update func(ticket *domain.WalletTicket) error) error {
+        return s.modify(userID, func(tickets []domain.WalletTicket) ([]domain.WalletTicket, error) {
+                for i := range tickets {
+                        if tickets[i].ID != ticketID {
+                                continue
+                        }
+                        ticket := tickets[i]
+                        if err := update(&ticket); err != nil {
+                                return nil, err
+                        }
+                        updated := append([]domain.WalletTicket(nil), tickets...)
+                        updated[i] = ticket
+                        return updated, nil
+                }
+                return nil, ErrTicketNotFound
+        })
+}
+
+// Delete удаляет билет пользователя
+func (s *FileWalletStore) Delete(ctx context.Context, userID, ticketID string) error {
+        return s.modify(userID, func(tickets []domain.WalletTicket) ([]domain.WalletTicket, error) {
+                for i := range tickets {
+                        if tickets[i].ID == ticketID {
+                                updated := append([]domain.WalletTicket(nil), tickets[:i]...)
+                                return append(updated, tickets[i+1:]...), nil
+                        }
+                }
+                return nil, ErrTicketNotFound
+        })
+}
+
+// List возвращает копию билетов пользователя (пустой список, если кошелька нет)
+func (s *FileWalletStore) List(ctx context.Context, userID string) ([]domain.WalletTicket, error) {
+        if err := ValidateUserID(userID); err != nil {
+                return nil, err
+        }
+
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        tickets, err := s.load(userID)
+        if err != nil {
+                return nil, err
+        }
+        return append([]domain.WalletTicket{}, tickets...), nil
+}
+
+// Users возвращает пользователей, у которых есть файл кошелька
+func (s *FileWalletStore) Users(ctx context.Context) ([]string, error) {
+        files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+        if err != nil {
+                return nil, err
+        }
+
+        users := make([]string, 0, len(files))
+        for _, file := range files {
+                name := filepath.Base(file)
+                users = append(users, name[:len(name)-len(".json")])
+        }
+        sort.Strings(users)
+        return users, nil
+}
+
+// modify применяет изменение к кошельку пользователя и сохраняет его на диск
+// Кошелек в памяти заменяется только после успешной записи
+func (s *FileWalletStore) modify(userID string, change func(tickets []domain.WalletTicket) ([]domain.WalletTicket, error)) error {
+        if err := ValidateUserID(userID); err != nil {
+                return err
+        }
+
+        s.mu.Lock()
+        defer s.mu.Unlock()
+
+        tickets, err := s.load(userID)
+        if err != nil {
+                return err
+        }
+        updated, err := change(tickets)
+        if err != nil {
+                return err
+        }
+
+        data, err := json.Marshal(updated)
+        if err != nil {
+                return fmt.Errorf("ошибка сериализации кошелька: %w", err)
+        }
+        if err := writeFileAtomic(s.walletPath(userID), data); err != nil {
+                return fmt.Errorf("ошибка сохранения кошелька %s: %w", userID, err)
+        }
+        s.wallets[userID] = updated
+        return nil
+}
+
+// load читает кошелек пользователя в память (вызывается под mu)
+func (s *FileWalletStore) load(userID string) ([]domain.WalletTicket, error) {
+        if tickets, ok := s.wallets[userID]; ok {
+                return tickets, nil
+        }
+
+        data, err := os.ReadFile(s.walletPath(userID))
+        if errors.Is(err, os.ErrNotExist) {
+                return nil, nil
+        }
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения кошелька %s: %w", userID, err)
+        }
+
+        var tickets []domain.WalletTicket
+        if err := json.Unmarshal(data, &tickets); err != nil {
+                return nil, fmt.Errorf("ошибка парсинга кошелька %s: %w", userID, err)
+        }
+        s.wallets[userID] = tickets
+        return tickets, nil
+}
+
+// walletPath возвращает путь к файлу кошелька пользователя
+func (s *FileWalletStore) walletPath(userID string) string {
+        return filepath.Join(s.dir, userID+".json")
+}
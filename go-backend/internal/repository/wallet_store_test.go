@@ -0,0 +1,95 @@
+package repository
+
+import (
+        "context"
+        "errors"
+        "reflect"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestFileWalletStore проверяет добавление, изменение, удаление и чтение билетов после перезапуска
+func TestFileWalletStore(t *testing.T) {
+        ctx := context.Background()
+        dir := t.TempDir()
+        store, err := NewFileWalletStore(dir)
+        if err != nil {
+                t.Fatalf("Не удалось создать хранилище: %v", err)
+        }
+
+        if tickets, err := store.List(ctx, "user_1"); err != nil || len(tickets) != 0 {
+                t.Fatalf("Кошелек нового пользователя должен быть пустым, получено %v, %v", tickets, err)
+        }
+        for _, id := range []string{"a", "b"} {
+                ticket := domain.WalletTicket{ID: id, LotteryID: "6x45", DrawNumbers: []int{10}, Status: domain.TicketStatusPending}
+                if err := store.Add(ctx, "user_1", ticket); err != nil {
+                        t.Fatalf("Add %s: %v", id, err)
+                }
+        }
+        if err := store.Add(ctx, "user_1", domain.WalletTicket{ID: "a"}); err == nil {
+                t.Error("Повторное добавление билета с тем же ID должно возвращать ошибку")
+        }
+
+        err = store.Update(ctx, "user_1", "a", func(ticket *domain.WalletTicket) error {
+                ticket.Status = domain.TicketStatusLost
+                return nil
+        })
+        if err != nil {
+                t.Fatalf("Update: %v", err)
+        }
+        err = store.Update(ctx, "user_1", "b", func(ticket *domain.WalletTicket) error {
+                ticket.Status = domain.TicketStatusWon
+                return errors.New("отмена")
+        })
+        if err == nil {
+                t.Error("Ошибка update должна возвращаться из Update")
+        }
+        if err := store.Delete(ctx, "user_1", "missing"); !errors.Is(err, ErrTicketNotFound) {
+                t.Errorf("Удаление отсутствующего билета: ожидается ErrTicketNotFound, получено %v", err)
+        }
+        if err := store.Add(ctx, "user_2", domain.WalletTicket{ID: "c"}); err != nil {
+                t.Fatalf("Add c: %v", err)
+        }
+        if err := store.Delete(ctx, "user_2", "c"); err != nil {
+                t.Fatalf("Delete: %v", err)
+        }
+
+        // Новый экземпляр читает кошельки с диска
+        reopened, err := NewFileWalletStore(dir)
+        if err != nil {
+                t.Fatalf("Не удалось открыть хранилище: %v", err)
+        }
+        users, err := reopened.Users(ctx)
+        if err != nil || !reflect.DeepEqual(users, []string{"user_1", "user_2"}) {
+                t.Errorf("Users: ожидается [user_1 user_2], получено %v, %v", users, err)
+        }
+        a, err := reopened.Get(ctx, "user_1", "a")
+        if err != nil || a.Status != domain.TicketStatusLost {
+                t.Errorf("Билет a должен быть сохранен со статусом lost, получено %+v, %v", a, err)
+        }
+        b, err := reopened.Get(ctx, "user_1", "b")
+        if err != nil || b.Status != domain.TicketStatusPending {
+                t.Errorf("Отмененное изменение билета b не должно сохраняться, получено %+v, %v", b, err)
+        }
+        if _, err := reopened.Get(ctx, "user_2", "c"); !errors.Is(err, ErrTicketNotFound) {
+                t.Errorf("Удаленный билет: ожидается ErrTicketNotFound, получено %v", err)
+        }
+}
+
+// TestFileWalletStoreRejectsUserID проверяет, что идентификатор пользователя не может указывать за пределы каталога
+func TestFileWalletStoreRejectsUserID(t *testing.T) {
+        store, err := NewFileWalletStore(t.TempDir())
+        if err != nil {
+                t.Fatalf("Не удалось создать хранилище: %v", err)
+        }
+
+        for _, userID := range []string{"", "../etc", "user/1", "user.json"} {
+                if _, err := store.List(context.Background(), userID); !errors.Is(err, ErrInvalidUserID) {
+                        t.Errorf("%q: ожидается ErrInvalidUserID, получено %v", userID, err)
+                }
+                if err := store.Add(context.Background(), userID, domain.WalletTicket{ID: "a"}); !errors.Is(err, ErrInvalidUserID) {
+                        t.Errorf("%q: ожидается ErrInvalidUserID при добавлении, получено %v", userID, err)
+                }
+        }
+}
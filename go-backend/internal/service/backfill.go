@@ -0,0 +1,221 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "log"
+        "strconv"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// DrawFetcher - источник результатов тиражей для BackfillCrawler и DrawService (реализуется StolotoClient)
+type DrawFetcher interface {
+        GetAllDraws(ctx context.Context) (*repository.DrawsResponse, error)
+        GetLatestDraw(ctx context.Context, gameName string) (*repository.Draw, error)
+        GetPrelatestDraw(ctx context.Context, gameName string) (*repository.Draw, error)
+        GetDraw(ctx context.Context, gameName string, drawNumber string) (*repository.Draw, error)
+}
+
+// BackfillConfig задает обход истории тиражей
+type BackfillConfig struct {
+        Games     []string      // Игры для обхода; пусто - все игры из /api/draws/
+        MaxDepth  int           // Сколько тиражей от последнего проходить вглубь
+        MaxMisses int           // Сколько отсутствующих тиражей (404) подряд считать началом истории игры
+        Pause     time.Duration // Пауза между запросами к StolotoAPI, чтобы оставить лимит пользовательским запросам (0 - без пауз)
+        Interval  time.Duration // Пауза между проходами в Run
+}
+
+// DefaultBackfillConfig возвращает настройки обхода по умолчанию
+func DefaultBackfillConfig() BackfillConfig {
+        return BackfillConfig{
+                MaxDepth:  1000,
+                MaxMisses: 10,
+                Pause:     2 * time.Second,
+                Interval:  time.Hour,
+        }
+}
+
+// BackfillResult - итог обхода одной игры
+type BackfillResult struct {
+        GameName string
+        Latest   int  // Номер последнего тиража
+        Fetched  int  // Загружено и сохранено тиражей
+        Skipped  int  // Уже были в архиве
+        Missing  int  // StolotoAPI ответил 404
+        Complete bool // Обход дошел до MaxDepth или начала истории игры
+}
+
+// BackfillCrawler заполняет архив тиражей, проходя номера от последнего тиража назад
+// Тиражи, которые уже есть в архиве, не запрашиваются, поэтому прерванный обход
+// (рестарт, лимит запросов, недоступность StolotoAPI) продолжается со следующего прохода.
+type BackfillCrawler struct {
+        fetcher DrawFetcher
+        archive repository.DrawArchive
+        config  BackfillConfig
+}
+
+// NewBackfillCrawler создает новый экземпляр BackfillCrawler
+func NewBackfillCrawler(fetcher DrawFetcher, archive repository.DrawArchive, config BackfillConfig) *BackfillCrawler {
+        defaults := DefaultBackfillConfig()
+        if config.MaxDepth <= 0 {
+                config.MaxDepth = defaults.MaxDepth
+        }
+        if config.MaxMisses <= 0 {
+                config.MaxMisses = defaults.MaxMisses
+        }
+        if config.Interval <= 0 {
+                config.Interval = defaults.Interval
+        }
+
+        return &BackfillCrawler{
+                fetcher: fetcher,
+                archive: archive,
+                config:  config,
+        }
+}
+
+// Run периодически выполняет RunOnce до отмены контекста
+func (c *BackfillCrawler) Run(ctx context.Context) {
+        for {
+                c.RunOnce(ctx)
+
+                select {
+                case <-ctx.Done():
+                        return
+                case <-time.After(c.config.Interval):
+                }
+        }
+}
+
+// RunOnce обходит все игры один раз
+// Если StolotoAPI ограничивает запросы или недоступен, проход прекращается до следующего запуска
+func (c *BackfillCrawler) RunOnce(ctx context.Context) []BackfillResult {
+        games, err := c.games(ctx)
+        if err != nil {
+                log.Printf("[Backfill] Failed to list games: %v", err)
+                return nil
+        }
+
+        results := make([]BackfillResult, 0, len(games))
+        for _, gameName := range games {
+                result, err := c.Backfill(ctx, gameName)
+                results = append(results, result)
+                log.Printf("[Backfill] %s: latest %d, fetched %d, skipped %d, missing %d, complete %v",
+                        gameName, result.Latest, result.Fetched, result.Skipped, result.Missing, result.Complete)
+
+                if err != nil {
+                        log.Printf("[Backfill] %s interrupted: %v", gameName, err)
+                        if ctx.Err() != nil || errors.Is(err, repository.ErrRateLimited) || errors.Is(err, repository.ErrCircuitOpen) {
+                                break
+                        }
+                }
+        }
+        return results
+}
+
+// Backfill дополняет архив тиражами одной игры
+func (c *BackfillCrawler) Backfill(ctx context.Context, gameName string) (BackfillResult, error) {
+        result := BackfillResult{GameName: gameName}
+
+        latest, err := c.fetcher.GetLatestDraw(ctx, gameName)
+        if err != nil {
+                return result, fmt.Errorf("ошибка получения последнего тиража: %w", err)
+        }
+        result.Latest = latest.Number
+        if err := c.store(ctx, latest, &result); err != nil {
+                return result, err
+        }
+
+        lowest := latest.Number - c.config.MaxDepth + 1
+        if lowest < 1 {
+                lowest = 1
+        }
+
+        misses := 0
+        for number := latest.Number - 1; number >= lowest; number-- {
+                archived, err := c.archive.Has(ctx, gameName, number)
+                if err != nil {
+                        return result, err
+                }
+                if archived {
+                        result.Skipped++
+                        misses = 0
+                        continue
+                }
+
+                if err := repository.SleepContext(ctx, c.config.Pause); err != nil {
+                        return result, err
+                }
+
+                draw, err := c.fetcher.GetDraw(ctx, gameName, strconv.Itoa(number))
+                if repository.IsNotFound(err) {
+                        result.Missing++
+                        misses++
+                        if misses >= c.config.MaxMisses {
+                                result.Complete = true
+                                return result, nil
+                        }
+                        continue
+                }
+                if err != nil {
+                        return result, fmt.Errorf("ошибка получения тиража %d: %w", number, err)
+                }
+                misses = 0
+
+                if draw.Number == 0 {
+                        draw.Number = number
+                }
+                if err := c.store(ctx, draw, &result); err != nil {
+                        return result, err
+                }
+        }
+
+        result.Complete = true
+        return result, nil
+}
+
+// store сохраняет завершенный тираж, если его еще нет в архиве
+// Тиражи без выигрышной комбинации (еще не разыграны) не сохраняются
+func (c *BackfillCrawler) store(ctx context.Context, draw *repository.Draw, result *BackfillResult) error {
+        if len(draw.WinningNumbers) == 0 {
+                return nil
+        }
+        if draw.GameName == "" {
+                draw.GameName = result.GameName
+        }
+
+        archived, err := c.archive.Has(ctx, draw.GameName, draw.Number)
+        if err != nil {
+                return err
+        }
+        if archived {
+                result.Skipped++
+                return nil
+        }
+
+        if err := c.archive.Save(ctx, *draw); err != nil {
+                return fmt.Errorf("ошибка сохранения тиража %d: %w", draw.Number, err)
+        }
+        result.Fetched++
+        return nil
+}
+
+// games возвращает игры для обхода
+func (c *BackfillCrawler) games(ctx context.Context) ([]string, error) {
+        if len(c.config.Games) > 0 {
+                return c.config.Games, nil
+        }
+
+        response, err := c.fetcher.GetAllDraws(ctx)
+        if err != nil {
+                return nil, err
+        }
+        games := make([]string, 0, len(response.Games))
+        for _, game := range response.Games {
+                games = append(games, game.Name)
+        }
+        return games, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+        "context"
+        "net/http"
+        "strconv"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// fakeDrawFetcher - StolotoAPI с тиражами в памяти
+type fakeDrawFetcher struct {
+        latest    int
+        missing   map[int]bool
+        failAfter int   // Сколько запросов GetDraw выполнить до ErrRateLimited (0 - без ограничения)
+        down      error // Ошибка всех запросов (StolotoAPI недоступен)
+        requests  int
+}
+
+func (f *fakeDrawFetcher) GetAllDraws(ctx context.Context) (*repository.DrawsResponse, error) {
+        return &repository.DrawsResponse{Games: []repository.Game{{Name: "6x45"}}}, nil
+}
+
+func (f *fakeDrawFetcher) GetLatestDraw(ctx context.Context, gameName string) (*repository.Draw, error) {
+        if f.down != nil {
+                return nil, f.down
+        }
+        return f.draw(gameName, f.latest), nil
+}
+
+func (f *fakeDrawFetcher) GetPrelatestDraw(ctx context.Context, gameName string) (*repository.Draw, error) {
+        if f.down != nil {
+                return nil, f.down
+        }
+        return f.draw(gameName, f.latest-1), nil
+}
+
+func (f *fakeDrawFetcher) GetDraw(ctx context.Context, gameName string, drawNumber string) (*repository.Draw, error) {
+        f.requests++
+        if f.down != nil {
+                return nil, f.down
+        }
+        if f.failAfter > 0 && f.requests > f.failAfter {
+                return nil, repository.ErrRateLimited
+        }
+
+        number, _ := strconv.Atoi(drawNumber)
+        if number < 1 || f.missing[number] {
+                return nil, &repository.StatusError{StatusCode: http.StatusNotFound}
+        }
+        return f.draw(gameName, number), nil
+}
+
+func (f *fakeDrawFetcher) draw(gameName string, number int) *repository.Draw {
+        return &repository.Draw{Number: number, GameName: gameName, WinningNumbers: []int{1, 2, 3, 4, 5, number % 45}}
+}
+
+// TestBackfillCrawlerResumes проверяет, что прерванный обход продолжается и не запрашивает тиражи повторно
+func TestBackfillCrawlerResumes(t *testing.T) {
+        ctx := context.Background()
+        archive, err := repository.NewFileDrawArchive(t.TempDir())
+        if err != nil {
+                t.Fatalf("Не удалось создать архив: %v", err)
+        }
+
+        fetcher := &fakeDrawFetcher{latest: 20, failAfter: 5}
+        crawler := NewBackfillCrawler(fetcher, archive, BackfillConfig{MaxDepth: 20})
+
+        results := crawler.RunOnce(ctx)
+        if len(results) != 1 || results[0].Complete || results[0].Fetched != 6 {
+                t.Fatalf("Ожидается прерванный обход с 6 тиражами (последний + 5), получено %+v", results)
+        }
+
+        fetcher.failAfter = 0
+        fetcher.requests = 0
+        result, err := crawler.Backfill(ctx, "6x45")
+        if err != nil {
+                t.Fatalf("Повторный обход: %v", err)
+        }
+        if !result.Complete || result.Fetched != 14 || result.Skipped != 6 {
+                t.Errorf("Ожидается 14 новых и 6 пропущенных тиражей, получено %+v", result)
+        }
+        if fetcher.requests != 14 {
+                t.Errorf("Архивные тиражи не должны запрашиваться повторно: %d запросов", fetcher.requests)
+        }
+
+        _, total, _ := archive.Query(ctx, repository.DrawQuery{GameName: "6x45"})
+        if total != 20 {
+                t.Errorf("Ожидается 20 тиражей в архиве, получено %d", total)
+        }
+}
+
+// TestBackfillCrawlerStopsAtHistoryStart проверяет остановку после MaxMisses отсутствующих тиражей подряд
+func TestBackfillCrawlerStopsAtHistoryStart(t *testing.T) {
+        ctx := context.Background()
+        archive, err := repository.NewFileDrawArchive(t.TempDir())
+        if err != nil {
+                t.Fatalf("Не удалось создать архив: %v", err)
+        }
+
+        // Тиражи 1-10 не существуют, 14 - пропуск в нумерации
+        missing := map[int]bool{14: true}
+        for number := 1; number <= 10; number++ {
+                missing[number] = true
+        }
+        fetcher := &fakeDrawFetcher{latest: 20, missing: missing}
+        crawler := NewBackfillCrawler(fetcher, archive, BackfillConfig{MaxDepth: 100, MaxMisses: 3})
+
+        result, err := crawler.Backfill(ctx, "6x45")
+        if err != nil {
+                t.Fatalf("Backfill: %v", err)
+        }
+        if !result.Complete || result.Fetched != 9 || result.Missing != 4 {
+                t.Errorf("Ожидается 9 тиражей (11-20 без 14) и 4 отсутствующих, получено %+v", result)
+        }
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+        "context"
+        "fmt"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// Backtest проверяет комбинации так, как если бы они играли в каждом тираже архива из диапазона
+// Стоимость считается по текущей цене билета: цены прошлых тиражей в архиве не хранятся
+func (s *DrawService) Backtest(ctx context.Context, lotteryID string, request domain.BacktestRequest) (*domain.BacktestResult, error) {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if err := validateCombinations(*lottery, request.Combinations); err != nil {
+                return nil, err
+        }
+        if s.archive == nil {
+                return nil, fmt.Errorf("%w: архив тиражей отключен", ErrNoDrawHistory)
+        }
+
+        query := repository.DrawQuery{GameName: lotteryID, FromNumber: request.FromNumber, ToNumber: request.ToNumber}
+        if query.From, err = parseBacktestDate(request.From); err != nil {
+                return nil, fmt.Errorf("%w: дата from: %v", ErrInvalidTicket, err)
+        }
+        if query.To, err = parseBacktestDate(request.To); err != nil {
+                return nil, fmt.Errorf("%w: дата to: %v", ErrInvalidTicket, err)
+        }
+        if !query.To.IsZero() {
+                query.To = query.To.AddDate(0, 0, 1) // Дата to включительно
+        }
+
+        draws, _, err := s.archive.Query(ctx, query)
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения архива тиражей: %w", err)
+        }
+        if len(draws) == 0 {
+                if _, total, err := s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID, Limit: 1}); err == nil && total == 0 {
+                        return nil, fmt.Errorf("%w: %s", ErrNoDrawHistory, lotteryID)
+                }
+                return nil, fmt.Errorf("%w: в архиве нет тиражей лотереи %s за выбранный период", ErrDrawNotFound, lotteryID)
+        }
+
+        result := &domain.BacktestResult{LotteryID: lotteryID, Tiers: make([]domain.BacktestTier, len(lottery.GameRules.Tiers))}
+        tiers := make(map[string]*domain.BacktestTier, len(lottery.GameRules.Tiers))
+        for i, tier := range lottery.GameRules.Tiers {
+                result.Tiers[i].Category = tier.Name
+                tiers[tier.Name] = &result.Tiers[i]
+        }
+
+        bestWins := 0
+        for _, draw := range draws {
+                // Тиражи без выигрышной комбинации или с комбинацией не по правилам игры пропускаются
+                check, err := matchTicket(*lottery, repository.ConvertDrawToResult(draw), request.Combinations)
+                if err != nil {
+                        continue
+                }
+
+                if result.Draws == 0 {
+                        result.LastDraw = draw.Number
+                }
+                result.FirstDraw = draw.Number
+                result.Draws++
+                result.Plays += len(check.Combinations)
+                result.TotalPrize += check.TotalPrize
+
+                wins := 0
+                for _, combination := range check.Combinations {
+                        if !combination.Won {
+                                continue
+                        }
+                        wins++
+                        tier := tiers[combination.Category]
+                        tier.Hits++
+                        if combination.Prize != nil {
+                                tier.Prize += *combination.Prize
+                        } else {
+                                tier.UnknownPrizes++
+                        }
+                }
+                result.WinningPlays += wins
+
+                // Лучший тираж - с наибольшим выигрышем, при равенстве - с большим числом выигрышных комбинаций
+                if wins > 0 && (result.BestDraw == nil || check.TotalPrize > result.BestDraw.TotalPrize ||
+                        (check.TotalPrize == result.BestDraw.TotalPrize && wins > bestWins)) {
+                        result.BestDraw = check
+                        bestWins = wins
+                }
+        }
+        if result.Draws == 0 {
+                return nil, fmt.Errorf("%w: в архиве нет результатов тиражей лотереи %s за выбранный период", ErrDrawNotFound, lotteryID)
+        }
+
+        result.Cost = float64(result.Plays) * lottery.TicketPrice
+        result.Net = result.TotalPrize - result.Cost
+        return result, nil
+}
+
+// parseBacktestDate разбирает дату ГГГГ-ММ-ДД по МСК (пустая строка - нулевое время)
+func parseBacktestDate(value string) (time.Time, error) {
+        if value == "" {
+                return time.Time{}, nil
+        }
+        return time.ParseInLocation("2006-01-02", value, domain.MoscowTime)
+}
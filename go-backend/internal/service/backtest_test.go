@@ -0,0 +1,94 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// TestBacktest проверяет выигрыши комбинаций по архиву тиражей и выбор диапазона
+func TestBacktest(t *testing.T) {
+        base := time.Date(2025, 11, 1, 21, 0, 0, 0, domain.MoscowTime)
+        draws := make([]repository.Draw, 0, 6)
+        for number := 1; number <= 6; number++ {
+                date := base.AddDate(0, 0, number)
+                draw := repository.Draw{Number: number, GameName: "6x45", DrawDate: date.Format("2006-01-02 15:04:05"), Date: date}
+                switch {
+                case number <= 4:
+                        draw.WinningNumbers = []int{10, 20, 30, 40, 41, 42}
+                case number == 5:
+                        draw.WinningNumbers = []int{1, 2, 3, 4, 5, 6}
+                }
+                if number == 2 {
+                        draw.Winners = repository.WinnerTiers{{Tier: "4", Winners: 10, Prize: 200000}}
+                }
+                draws = append(draws, draw) // Тираж 6 еще не разыгран
+        }
+        service := newTestTicketService(t, draws...)
+        ctx := context.Background()
+
+        combinations := []domain.TicketCombination{
+                {{10, 20, 30, 1, 2, 3}},    // 3 из 6 в каждом тираже
+                {{10, 20, 30, 40, 44, 45}}, // 4 из 6 в тиражах 1-4 (в тираже 2 - фактическая выплата 2000)
+        }
+        result, err := service.Backtest(ctx, "6x45", domain.BacktestRequest{Combinations: combinations})
+        if err != nil {
+                t.Fatalf("Backtest: %v", err)
+        }
+        if result.Draws != 5 || result.FirstDraw != 1 || result.LastDraw != 5 || result.Plays != 10 || result.WinningPlays != 9 {
+                t.Errorf("Ожидается 5 тиражей (1-5) и 9 выигрышей из 10, получено %+v", result)
+        }
+        if result.Cost != 1000 || result.TotalPrize != 5500 || result.Net != 4500 {
+                t.Errorf("Ожидается стоимость 1000 и выигрыш 5500 (5 x 100 + 2000 + 3 x 1000), получено %+v", result)
+        }
+        for _, tier := range result.Tiers {
+                switch tier.Category {
+                case "3 из 6":
+                        if tier.Hits != 5 || tier.Prize != 500 || tier.UnknownPrizes != 0 {
+                                t.Errorf("3 из 6: ожидается 5 выигрышей по 100, получено %+v", tier)
+                        }
+                case "4 из 6":
+                        if tier.Hits != 4 || tier.Prize != 5000 || tier.UnknownPrizes != 0 {
+                                t.Errorf("4 из 6: ожидается выплата тиража 2 и фиксированный приз в остальных, получено %+v", tier)
+                        }
+                default:
+                        if tier.Hits != 0 {
+                                t.Errorf("%s: лишние выигрыши %+v", tier.Category, tier)
+                        }
+                }
+        }
+        if result.BestDraw == nil || result.BestDraw.DrawNumber != 2 || result.BestDraw.TotalPrize != 2100 {
+                t.Errorf("Лучший тираж: ожидается 2 с выигрышем 2100, получено %+v", result.BestDraw)
+        }
+
+        // Даты тиражей: 1 - 2 ноября, 2 - 3 ноября и т.д.
+        result, err = service.Backtest(ctx, "6x45", domain.BacktestRequest{Combinations: combinations, From: "2025-11-03", To: "2025-11-04"})
+        if err != nil || result.FirstDraw != 2 || result.LastDraw != 3 {
+                t.Errorf("По датам: ожидаются тиражи 2-3, получено %+v, %v", result, err)
+        }
+        result, err = service.Backtest(ctx, "6x45", domain.BacktestRequest{Combinations: combinations, FromNumber: 4})
+        if err != nil || result.Draws != 2 || result.BestDraw == nil || result.BestDraw.DrawNumber != 4 {
+                t.Errorf("С тиража 4: ожидаются тиражи 4-5 и лучший тираж 4, получено %+v, %v", result, err)
+        }
+
+        tests := []struct {
+                name      string
+                lotteryID string
+                request   domain.BacktestRequest
+                want      error
+        }{
+                {"нет тиражей в диапазоне", "6x45", domain.BacktestRequest{Combinations: combinations, FromNumber: 100}, ErrDrawNotFound},
+                {"нет истории", "5x36plus", domain.BacktestRequest{Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5}, {1}}}}, ErrNoDrawHistory},
+                {"нет правил", "ruslotto", domain.BacktestRequest{Combinations: combinations}, ErrRulesUnavailable},
+                {"комбинация не по правилам", "6x45", domain.BacktestRequest{Combinations: []domain.TicketCombination{{{1, 2, 3}}}}, ErrInvalidTicket},
+        }
+        for _, tt := range tests {
+                if _, err := service.Backtest(ctx, tt.lotteryID, tt.request); !errors.Is(err, tt.want) {
+                        t.Errorf("%s: ожидается %v, получено %v", tt.name, tt.want, err)
+                }
+        }
+}
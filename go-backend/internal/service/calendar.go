@@ -0,0 +1,134 @@
+package service
+
+import (
+        "bytes"
+        "context"
+        "errors"
+        "fmt"
+        "strings"
+        "time"
+        "unicode/utf8"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// ErrScheduleUnavailable возвращается, если расписание тиражей лотереи неизвестно
+var ErrScheduleUnavailable = errors.New("расписание тиражей лотереи неизвестно")
+
+const (
+        // DefaultCalendarDraws - сколько ближайших тиражей каждой лотереи попадает в календарь по умолчанию
+        DefaultCalendarDraws = 20
+
+        calendarEventDuration = 15 * time.Minute // Длительность события тиража в календаре
+        calendarRefresh       = "PT6H"           // Как часто клиенту обновлять подписку
+        calendarLineLimit     = 75               // Максимальная длина строки iCalendar в байтах (RFC 5545)
+        icsTimeFormat         = "20060102T150405Z"
+)
+
+// GetLotteryCalendar возвращает календарь iCalendar с ближайшими count тиражами лотереи
+func (s *StolotoService) GetLotteryCalendar(ctx context.Context, id string, count int) ([]byte, error) {
+        lottery, err := s.GetLotteryByID(ctx, id)
+        if err != nil {
+                return nil, err
+        }
+        if lottery.Schedule == nil {
+                return nil, fmt.Errorf("%w: %s", ErrScheduleUnavailable, id)
+        }
+        return BuildCalendar("Тиражи: "+lottery.Name, []domain.Lottery{*lottery}, s.now(), count), nil
+}
+
+// GetCalendar возвращает календарь iCalendar с ближайшими count тиражами каждой активной лотереи
+// Лотереи без известного расписания в календарь не попадают
+func (s *StolotoService) GetCalendar(ctx context.Context, count int) ([]byte, error) {
+        lotteries, err := s.GetActiveLotteries(ctx)
+        if err != nil {
+                return nil, err
+        }
+        return BuildCalendar("Тиражи лотерей", lotteries, s.now(), count), nil
+}
+
+// BuildCalendar формирует календарь iCalendar (RFC 5545) с ближайшими count тиражами лотерей после now
+// Время событий передается в UTC, поэтому описание часового пояса (VTIMEZONE) не требуется
+func BuildCalendar(name string, lotteries []domain.Lottery, now time.Time, count int) []byte {
+        var calendar icsBuilder
+        calendar.line("BEGIN", "VCALENDAR")
+        calendar.line("VERSION", "2.0")
+        calendar.line("PRODID", "-//LottoAdvisor//Draw Schedule//RU")
+        calendar.line("CALSCALE", "GREGORIAN")
+        calendar.line("METHOD", "PUBLISH")
+        calendar.line("X-WR-CALNAME", escapeICSText(name))
+        calendar.line("X-WR-TIMEZONE", "Europe/Moscow")
+        calendar.line("REFRESH-INTERVAL;VALUE=DURATION", calendarRefresh)
+        calendar.line("X-PUBLISHED-TTL", calendarRefresh)
+
+        stamp := now.UTC().Format(icsTimeFormat)
+        for _, lottery := range lotteries {
+                if lottery.Schedule == nil {
+                        continue
+                }
+
+                summary := escapeICSText("Тираж " + lottery.Name)
+                description := escapeICSText(calendarDescription(lottery))
+                for _, draw := range lottery.Schedule.NextDraws(now, count) {
+                        start := draw.UTC().Format(icsTimeFormat)
+                        calendar.line("BEGIN", "VEVENT")
+                        calendar.line("UID", fmt.Sprintf("%s-%s@lottoadvisor", lottery.ID, start))
+                        calendar.line("DTSTAMP", stamp)
+                        calendar.line("DTSTART", start)
+                        calendar.line("DTEND", draw.Add(calendarEventDuration).UTC().Format(icsTimeFormat))
+                        calendar.line("SUMMARY", summary)
+                        calendar.line("DESCRIPTION", description)
+                        calendar.line("END", "VEVENT")
+                }
+        }
+
+        calendar.line("END", "VCALENDAR")
+        return calendar.Bytes()
+}
+
+// calendarDescription описывает тираж в событии календаря: джекпот и цена билета на момент выгрузки
+func calendarDescription(lottery domain.Lottery) string {
+        parts := make([]string, 0, 2)
+        if lottery.CurrentJackpot > 0 {
+                jackpot := domain.Payout{Kind: domain.PayoutJackpot, Amount: lottery.CurrentJackpot}
+                parts = append(parts, jackpot.String())
+        }
+        if lottery.TicketPrice > 0 {
+                parts = append(parts, fmt.Sprintf("Билет: %.0f ₽", lottery.TicketPrice))
+        }
+        return strings.Join(parts, ". ")
+}
+
+// icsBuilder собирает строки iCalendar с переносом длинных строк и окончаниями CRLF
+type icsBuilder struct {
+        bytes.Buffer
+}
+
+// line добавляет свойство NAME:VALUE, перенося строки длиннее 75 байт (не разрывая символы UTF-8)
+func (b *icsBuilder) line(name, value string) {
+        content := name + ":" + value
+        limit := calendarLineLimit
+        for len(content) > limit {
+                cut := limit
+                for cut > 0 && !utf8.RuneStart(content[cut]) {
+                        cut--
+                }
+                b.WriteString(content[:cut])
+                b.WriteString("\r\n ")
+                content = content[cut:]
+                limit = calendarLineLimit - 1 // Строка продолжения начинается с пробела
+        }
+        b.WriteString(content)
+        b.WriteString("\r\n")
+}
+
+// escapeICSText экранирует текстовое значение iCalendar
+func escapeICSText(text string) string {
+        return strings.NewReplacer(
+                `\`, `\\`,
+                ";", `\;`,
+                ",", `\,`,
+                "\r\n", `\n`,
+                "\n", `\n`,
+        ).Replace(text)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "strings"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestBuildCalendar проверяет формат календаря iCalendar
+func TestBuildCalendar(t *testing.T) {
+        now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+        lotteries := []domain.Lottery{
+                {
+                        ID:             "7x49",
+                        Name:           "Гослото 7 из 49, розыгрыш; среда и суббота",
+                        TicketPrice:    100,
+                        CurrentJackpot: 50000000,
+                        Schedule:       &domain.DrawSchedule{Weekdays: []int{3, 6}, Times: []string{"21:00"}},
+                },
+                {ID: "no-schedule", Name: "Без расписания"},
+        }
+
+        calendar := string(BuildCalendar("Тиражи", lotteries, now, 2))
+
+        if !strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(calendar, "END:VCALENDAR\r\n") {
+                t.Fatalf("Некорректная обертка календаря:\n%s", calendar)
+        }
+        if count := strings.Count(calendar, "BEGIN:VEVENT"); count != 2 {
+                t.Errorf("Ожидается 2 события, получено %d", count)
+        }
+        // Суббота, 17 октября, 21:00 МСК = 18:00 UTC
+        for _, line := range []string{
+                "UID:7x49-20261017T180000Z@lottoadvisor\r\n",
+                "DTSTART:20261017T180000Z\r\n",
+                "DTEND:20261017T181500Z\r\n",
+                "DTSTART:20261021T180000Z\r\n",
+                "DTSTAMP:20261016T120000Z\r\n",
+        } {
+                if !strings.Contains(calendar, line) {
+                        t.Errorf("Календарь не содержит %q", line)
+                }
+        }
+        if strings.Contains(calendar, "Без расписания") {
+                t.Error("Лотереи без расписания не попадают в календарь")
+        }
+
+        for _, line := range strings.Split(strings.TrimSuffix(calendar, "\r\n"), "\r\n") {
+                if len(line) > calendarLineLimit {
+                        t.Errorf("Строка длиннее %d байт: %q", calendarLineLimit, line)
+                }
+        }
+
+        // После разворачивания перенесенных строк текст экранирован и не поврежден
+        unfolded := strings.ReplaceAll(calendar, "\r\n ", "")
+        if !strings.Contains(unfolded, `SUMMARY:Тираж Гослото 7 из 49\, розыгрыш\; среда и суббота`+"\r\n") {
+                t.Errorf("Некорректное название события:\n%s", unfolded)
+        }
+        if !strings.Contains(unfolded, "DESCRIPTION:Джекпот (50.0 млн ₽). Билет: 100 ₽\r\n") {
+                t.Errorf("Некорректное описание события:\n%s", unfolded)
+        }
+}
+
+// TestLotteryNextDraws проверяет ближайшие тиражи в описании лотереи и календарь лотереи
+func TestLotteryNextDraws(t *testing.T) {
+        svc := NewStolotoService(nil, WithProviders(&fakeProvider{name: "stoloto-draws", lotteries: []domain.Lottery{
+                {ID: "6x45", Name: "Гослото 6 из 45", IsActive: true, Schedule: &domain.DrawSchedule{Times: []string{"20:00"}}},
+                {ID: "ruslotto", Name: "Русское лото", IsActive: true},
+        }}))
+        svc.now = func() time.Time { return time.Date(2026, 10, 16, 21, 0, 0, 0, domain.MoscowTime) }
+        ctx := context.Background()
+
+        lottery, err := svc.GetLotteryByID(ctx, "6x45")
+        if err != nil {
+                t.Fatalf("Неожиданная ошибка: %v", err)
+        }
+        if len(lottery.NextDraws) != NextDrawsCount || !lottery.NextDraws[0].Equal(time.Date(2026, 10, 17, 20, 0, 0, 0, domain.MoscowTime)) {
+                t.Errorf("Некорректные ближайшие тиражи: %v", lottery.NextDraws)
+        }
+
+        lotteries, _ := svc.GetAllLotteries(ctx)
+        for _, lottery := range lotteries {
+                if (lottery.Schedule == nil) != (lottery.NextDraws == nil) {
+                        t.Errorf("%s: ближайшие тиражи передаются только при известном расписании", lottery.ID)
+                }
+        }
+
+        if _, err := svc.GetLotteryCalendar(ctx, "ruslotto", 10); !errors.Is(err, ErrScheduleUnavailable) {
+                t.Errorf("Ожидается ErrScheduleUnavailable, получено %v", err)
+        }
+        if _, err := svc.GetLotteryCalendar(ctx, "unknown", 10); !errors.Is(err, ErrLotteryNotFound) {
+                t.Errorf("Ожидается ErrLotteryNotFound, получено %v", err)
+        }
+
+        calendar, err := svc.GetCalendar(ctx, 3)
+        if err != nil {
+                t.Fatalf("Неожиданная ошибка: %v", err)
+        }
+        if count := strings.Count(string(calendar), "BEGIN:VEVENT"); count != 3 {
+                t.Errorf("Ожидается 3 события, получено %d", count)
+        }
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+        "context"
+        "log"
+        "sync"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+const (
+        // defaultCatalogTTL - сколько каталог считается свежим
+        defaultCatalogTTL = 5 * time.Minute
+        // catalogRefreshTimeout - ограничение на одно обновление каталога
+        catalogRefreshTimeout = 30 * time.Second
+        // catalogFailureCooldown - сколько после неудачного обновления каталог не обновляется снова:
+        // при пустом кэше запросы сразу получают последнюю ошибку
+        catalogFailureCooldown = 10 * time.Second
+)
+
+// catalog - загруженный каталог лотерей с индексом по ID
+type catalog struct {
+        lotteries []domain.Lottery
+        index     map[string]int
+        source    domain.DataSource
+        fetchedAt time.Time
+}
+
+// newCatalog строит каталог и индекс по ID
+func newCatalog(lotteries []domain.Lottery, source domain.DataSource, fetchedAt time.Time) *catalog {
+        index := make(map[string]int, len(lotteries))
+        for i, lottery := range lotteries {
+                index[lottery.ID] = i
+        }
+        return &catalog{
+                lotteries: lotteries,
+                index:     index,
+                source:    source,
+                fetchedAt: fetchedAt,
+        }
+}
+
+// stale возвращает тот же каталог с источником stale: он устарел, а обновить его не удалось
+func (c *catalog) stale() *catalog {
+        stale := *c
+        stale.source = domain.DataSourceStale
+        return &stale
+}
+
+// provenance описывает происхождение каталога для ответа API
+func (c *catalog) provenance() domain.DataProvenance {
+        provenance := domain.DataProvenance{Source: c.source}
+        if !c.fetchedAt.IsZero() {
+                fetchedAt := c.fetchedAt
+                provenance.FetchedAt = &fetchedAt
+        }
+        return provenance
+}
+
+// list возвращает копию списка лотерей, чтобы вызывающий код не мог испортить кэш
+func (c *catalog) list() []domain.Lottery {
+        lotteries := make([]domain.Lottery, len(c.lotteries))
+        copy(lotteries, c.lotteries)
+        return lotteries
+}
+
+// find ищет лотерею по ID за O(1)
+func (c *catalog) find(id string) (domain.Lottery, bool) {
+        i, ok := c.index[id]
+        if !ok {
+                return domain.Lottery{}, false
+        }
+        return c.lotteries[i], true
+}
+
+// catalogLoader загружает свежий каталог лотерей
+type catalogLoader func(ctx context.Context) ([]domain.Lottery, error)
+
+// refreshCall - одно выполняющееся обновление каталога, результат которого ждут все запросы
+type refreshCall struct {
+        done   chan struct{}
+        result *catalog
+        err    error
+}
+
+// catalogCache - кэш каталога лотерей со stale-while-revalidate
+// Свежий каталог (моложе ttl) отдается как есть. Устаревший тоже отдается сразу,
+// а обновление запускается в фоне; если последнее обновление не удалось, каталог помечается stale.
+// Пустой кэш заполняется синхронно. После неудачного обновления новое не запускается catalogFailureCooldown.
+// Одновременные обновления схлопываются в одно (singleflight).
+type catalogCache struct {
+        ttl  time.Duration
+        load catalogLoader
+        now  func() time.Time
+
+        mu       sync.RWMutex
+        current  *catalog
+        failedAt time.Time // Когда не удалось последнее обновление (нулевое, если оно прошло успешно)
+        failure  error     // Ошибка последнего неудачного обновления
+
+        flightMu sync.Mutex
+        flight   *refreshCall
+}
+
+// newCatalogCache создает новый кэш каталога
+func newCatalogCache(ttl time.Duration, load catalogLoader) *catalogCache {
+        return &catalogCache{
+                ttl:  ttl,
+                load: load,
+                now:  time.Now,
+        }
+}
+
+// get возвращает каталог, при необходимости загружая или обновляя его
+func (c *catalogCache) get(ctx context.Context) (*catalog, error) {
+        c.mu.RLock()
+        current, failedAt, failure := c.current, c.failedAt, c.failure
+        c.mu.RUnlock()
+
+        failed := !failedAt.IsZero()
+        coolingDown := failed && c.now().Sub(failedAt) < catalogFailureCooldown
+        if current != nil {
+                if c.now().Sub(current.fetchedAt) >= c.ttl {
+                        if !coolingDown {
+                                c.startRefresh()
+                        }
+                        if failed {
+                                return current.stale(), nil
+                        }
+                }
+                return current, nil
+        }
+
+        if coolingDown {
+                return nil, failure
+        }
+        return c.refresh(ctx)
+}
+
+// refresh обновляет каталог и ждет результата (или отмены ctx)
+func (c *catalogCache) refresh(ctx context.Context) (*catalog, error) {
+        call := c.startRefresh()
+        select {
+        case <-call.done:
+                return call.result, call.err
+        case <-ctx.Done():
+                return nil, ctx.Err()
+        }
+}
+
+// startRefresh запускает обновление или присоединяется к уже выполняющемуся
+// Загрузка идет с собственным контекстом: отмена одного запроса не должна
+// прерывать обновление, которого ждут остальные
+func (c *catalogCache) startRefresh() *refreshCall {
+        c.flightMu.Lock()
+        defer c.flightMu.Unlock()
+
+        if c.flight != nil {
+                return c.flight
+        }
+
+        call := &refreshCall{done: make(chan struct{})}
+        c.flight = call
+
+        go func() {
+                ctx, cancel := context.WithTimeout(context.Background(), catalogRefreshTimeout)
+                defer cancel()
+
+                lotteries, err := c.load(ctx)
+                if err != nil {
+                        log.Printf("[CatalogCache] Refresh failed: %v", err)
+                        call.err = err
+                        c.mu.Lock()
+                        c.failedAt, c.failure = c.now(), err
+                        c.mu.Unlock()
+                } else {
+                        call.result = newCatalog(lotteries, domain.DataSourceLive, c.now())
+                        c.mu.Lock()
+                        c.current = call.result
+                        c.failedAt, c.failure = time.Time{}, nil
+                        c.mu.Unlock()
+                        log.Printf("[CatalogCache] Catalog refreshed: %d lotteries", len(lotteries))
+                }
+
+                c.flightMu.Lock()
+                c.flight = nil
+                c.flightMu.Unlock()
+                close(call.done)
+        }()
+
+        return call
+}
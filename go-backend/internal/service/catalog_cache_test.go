@@ -0,0 +1,192 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "sync"
+        "sync/atomic"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestCatalogCacheSingleflight проверяет, что одновременные запросы при пустом кэше вызывают одну загрузку
+func TestCatalogCacheSingleflight(t *testing.T) {
+        var loads int32
+        release := make(chan struct{})
+        cache := newCatalogCache(time.Minute, func(ctx context.Context) ([]domain.Lottery, error) {
+                atomic.AddInt32(&loads, 1)
+                <-release
+                return []domain.Lottery{{ID: "6x45"}}, nil
+        })
+
+        var wg sync.WaitGroup
+        for i := 0; i < 10; i++ {
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        current, err := cache.get(context.Background())
+                        if err != nil {
+                                t.Errorf("get: %v", err)
+                                return
+                        }
+                        if _, ok := current.find("6x45"); !ok {
+                                t.Error("Ожидается 6x45 в каталоге")
+                        }
+                }()
+        }
+
+        time.Sleep(20 * time.Millisecond)
+        close(release)
+        wg.Wait()
+
+        if got := atomic.LoadInt32(&loads); got != 1 {
+                t.Errorf("Ожидается одна загрузка, получено %d", got)
+        }
+}
+
+// TestCatalogCacheServesStaleWhileRefreshing проверяет, что устаревший каталог отдается сразу, а обновляется в фоне
+func TestCatalogCacheServesStaleWhileRefreshing(t *testing.T) {
+        var loads int32
+        release := make(chan struct{})
+        cache := newCatalogCache(time.Minute, func(ctx context.Context) ([]domain.Lottery, error) {
+                if atomic.AddInt32(&loads, 1) == 1 {
+                        return []domain.Lottery{{ID: "old"}}, nil
+                }
+                <-release
+                return []domain.Lottery{{ID: "new"}}, nil
+        })
+
+        now := time.Now()
+        cache.now = func() time.Time { return now }
+
+        if _, err := cache.get(context.Background()); err != nil {
+                t.Fatalf("Первая загрузка: %v", err)
+        }
+
+        // Каталог устарел: ответ должен прийти без ожидания загрузки
+        now = now.Add(2 * time.Minute)
+        for i := 0; i < 3; i++ {
+                current, err := cache.get(context.Background())
+                if err != nil {
+                        t.Fatalf("get: %v", err)
+                }
+                if _, ok := current.find("old"); !ok {
+                        t.Error("Ожидается, что будет отдан устаревший каталог")
+                }
+        }
+
+        close(release)
+        current, err := cache.refresh(context.Background())
+        if err != nil {
+                t.Fatalf("refresh: %v", err)
+        }
+        if _, ok := current.find("new"); !ok {
+                t.Error("Ожидается обновленный каталог")
+        }
+        if got := atomic.LoadInt32(&loads); got != 2 {
+                t.Errorf("Ожидается 2 загрузки (первая и одно фоновое обновление), получено %d", got)
+        }
+}
+
+// TestCatalogCacheKeepsDataOnRefreshError проверяет, что ошибка обновления не затирает кэш
+func TestCatalogCacheKeepsDataOnRefreshError(t *testing.T) {
+        fail := false
+        cache := newCatalogCache(time.Minute, func(ctx context.Context) ([]domain.Lottery, error) {
+                if fail {
+                        return nil, errors.New("источник недоступен")
+                }
+                return []domain.Lottery{{ID: "6x45"}}, nil
+        })
+
+        if _, err := cache.get(context.Background()); err != nil {
+                t.Fatalf("Первая загрузка: %v", err)
+        }
+
+        fail = true
+        if _, err := cache.refresh(context.Background()); err == nil {
+                t.Error("Ожидается ошибка обновления")
+        }
+
+        current, err := cache.get(context.Background())
+        if err != nil {
+                t.Fatalf("get: %v", err)
+        }
+        if _, ok := current.find("6x45"); !ok {
+                t.Error("После неудачного обновления ожидается прежний каталог")
+        }
+}
+
+// TestCatalogCacheMarksStaleAfterFailedRefresh проверяет, что устаревший каталог после неудачного обновления помечается stale
+func TestCatalogCacheMarksStaleAfterFailedRefresh(t *testing.T) {
+        var fail atomic.Bool
+        cache := newCatalogCache(time.Minute, func(ctx context.Context) ([]domain.Lottery, error) {
+                if fail.Load() {
+                        return nil, errors.New("источник недоступен")
+                }
+                return []domain.Lottery{{ID: "6x45"}}, nil
+        })
+
+        now := time.Now()
+        cache.now = func() time.Time { return now }
+        if _, err := cache.get(context.Background()); err != nil {
+                t.Fatalf("Первая загрузка: %v", err)
+        }
+
+        fail.Store(true)
+        if _, err := cache.refresh(context.Background()); err == nil {
+                t.Fatal("Ожидается ошибка обновления")
+        }
+        current, err := cache.get(context.Background())
+        if err != nil || current.provenance().Source != domain.DataSourceLive {
+                t.Errorf("Каталог моложе ttl: ожидается источник live, получено %+v, %v", current, err)
+        }
+
+        now = now.Add(2 * time.Minute)
+        current, err = cache.get(context.Background())
+        if err != nil || current.provenance().Source != domain.DataSourceStale {
+                t.Fatalf("Устаревший каталог после неудачного обновления: ожидается источник stale, получено %+v, %v", current, err)
+        }
+        if _, ok := current.find("6x45"); !ok {
+                t.Error("Ожидается прежний каталог")
+        }
+
+        // Дожидаемся фонового обновления, запущенного get, чтобы следующее обновление было новым
+        cache.refresh(context.Background())
+        fail.Store(false)
+        if _, err := cache.refresh(context.Background()); err != nil {
+                t.Fatalf("refresh: %v", err)
+        }
+        if current, _ := cache.get(context.Background()); current.provenance().Source != domain.DataSourceLive {
+                t.Errorf("После успешного обновления ожидается источник live, получено %s", current.provenance().Source)
+        }
+}
+
+// TestCatalogCacheFailureCooldown проверяет, что после неудачной загрузки пустого кэша источники не опрашиваются до конца паузы
+func TestCatalogCacheFailureCooldown(t *testing.T) {
+        var loads int32
+        cache := newCatalogCache(time.Minute, func(ctx context.Context) ([]domain.Lottery, error) {
+                atomic.AddInt32(&loads, 1)
+                return nil, errors.New("источник недоступен")
+        })
+
+        now := time.Now()
+        cache.now = func() time.Time { return now }
+        for i := 0; i < 3; i++ {
+                if _, err := cache.get(context.Background()); err == nil {
+                        t.Fatal("Ожидается ошибка загрузки")
+                }
+        }
+        if got := atomic.LoadInt32(&loads); got != 1 {
+                t.Errorf("Во время паузы ожидается одна загрузка, получено %d", got)
+        }
+
+        now = now.Add(catalogFailureCooldown)
+        if _, err := cache.get(context.Background()); err == nil {
+                t.Fatal("Ожидается ошибка загрузки")
+        }
+        if got := atomic.LoadInt32(&loads); got != 2 {
+                t.Errorf("После паузы ожидается новая загрузка, получено %d", got)
+        }
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "log"
+        "strconv"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+var (
+        // ErrDrawNotFound возвращается, если тиража с указанным номером нет (или у лотереи нет тиражей)
+        ErrDrawNotFound = errors.New("тираж не найден")
+        // ErrDrawsUnavailable возвращается, если StolotoAPI недоступен, а в архиве нужного тиража нет
+        ErrDrawsUnavailable = errors.New("результаты тиражей временно недоступны")
+)
+
+const (
+        defaultDrawsPageSize = 20
+        maxDrawsPageSize     = 100
+)
+
+// DrawService предоставляет результаты тиражей лотерей
+// Разыгранные тиражи не меняются, поэтому сначала используется архив, затем StolotoAPI.
+// Тиражи, полученные из StolotoAPI, сохраняются в архив.
+type DrawService struct {
+        lotteries *StolotoService
+        fetcher   DrawFetcher
+        archive   repository.DrawArchive // nil - архив не используется
+}
+
+// NewDrawService создает новый экземпляр DrawService
+func NewDrawService(lotteries *StolotoService, fetcher DrawFetcher, archive repository.DrawArchive) *DrawService {
+        return &DrawService{
+                lotteries: lotteries,
+                fetcher:   fetcher,
+                archive:   archive,
+        }
+}
+
+// GetLatestDraw возвращает последний разыгранный тираж лотереи
+// Если StolotoAPI недоступен, возвращает последний тираж из архива
+func (s *DrawService) GetLatestDraw(ctx context.Context, lotteryID string) (*domain.DrawResult, domain.DataProvenance, error) {
+        if err := s.checkLottery(ctx, lotteryID); err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        draw, err := s.fetcher.GetLatestDraw(ctx, lotteryID)
+        if err == nil {
+                s.archiveDraw(ctx, draw)
+                result := repository.ConvertDrawToResult(*draw)
+                return &result, liveProvenance(), nil
+        }
+        if repository.IsNotFound(err) {
+                return nil, domain.DataProvenance{}, fmt.Errorf("%w: у лотереи %s нет тиражей", ErrDrawNotFound, lotteryID)
+        }
+
+        log.Printf("[DrawService] Latest draw for %s unavailable, using archive: %v", lotteryID, err)
+        if s.archive != nil {
+                draws, _, archiveErr := s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID, Limit: 1})
+                if archiveErr == nil && len(draws) > 0 {
+                        result := repository.ConvertDrawToResult(draws[0])
+                        return &result, archiveProvenance(), nil
+                }
+        }
+        return nil, domain.DataProvenance{}, fmt.Errorf("%w: %v", ErrDrawsUnavailable, err)
+}
+
+// GetDraw возвращает тираж лотереи по номеру
+func (s *DrawService) GetDraw(ctx context.Context, lotteryID string, number int) (*domain.DrawResult, domain.DataProvenance, error) {
+        if number <= 0 {
+                return nil, domain.DataProvenance{}, fmt.Errorf("%w: некорректный номер тиража %d", ErrDrawNotFound, number)
+        }
+        if err := s.checkLottery(ctx, lotteryID); err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        if s.archive != nil {
+                draw, err := s.archive.Get(ctx, lotteryID, number)
+                if err == nil {
+                        result := repository.ConvertDrawToResult(*draw)
+                        return &result, archiveProvenance(), nil
+                }
+                if !errors.Is(err, repository.ErrDrawNotArchived) {
+                        log.Printf("[DrawService] Archive lookup failed for %s #%d: %v", lotteryID, number, err)
+                }
+        }
+
+        draw, err := s.fetcher.GetDraw(ctx, lotteryID, strconv.Itoa(number))
+        if repository.IsNotFound(err) {
+                return nil, domain.DataProvenance{}, fmt.Errorf("%w: тираж %d лотереи %s", ErrDrawNotFound, number, lotteryID)
+        }
+        if err != nil {
+                return nil, domain.DataProvenance{}, fmt.Errorf("%w: %v", ErrDrawsUnavailable, err)
+        }
+
+        s.archiveDraw(ctx, draw)
+        result := repository.ConvertDrawToResult(*draw)
+        return &result, liveProvenance(), nil
+}
+
+// ListDraws возвращает страницу истории тиражей от новых к старым
+// История читается из архива; если архива нет или он пуст, возвращаются
+// последний и предпоследний тиражи из StolotoAPI
+func (s *DrawService) ListDraws(ctx context.Context, lotteryID string, offset, limit int) (*domain.DrawResultsPage, domain.DataProvenance, error) {
+        if err := s.checkLottery(ctx, lotteryID); err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        if offset < 0 {
+                offset = 0
+        }
+        if limit <= 0 {
+                limit = defaultDrawsPageSize
+        }
+        if limit > maxDrawsPageSize {
+                limit = maxDrawsPageSize
+        }
+
+        if s.archive != nil {
+                draws, total, err := s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID, Offset: offset, Limit: limit})
+                if err != nil {
+                        log.Printf("[DrawService] Archive query failed for %s: %v", lotteryID, err)
+                } else if total > 0 {
+                        return newDrawResultsPage(draws, total, offset, limit), archiveProvenance(), nil
+                }
+        }
+
+        draws, err := s.fetchRecentDraws(ctx, lotteryID)
+        if err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        total := len(draws)
+        if offset >= len(draws) {
+                draws = nil
+        } else {
+                draws = draws[offset:]
+        }
+        if len(draws) > limit {
+                draws = draws[:limit]
+        }
+        return newDrawResultsPage(draws, total, offset, limit), liveProvenance(), nil
+}
+
+// fetchRecentDraws запрашивает последний и предпоследний тиражи из StolotoAPI
+func (s *DrawService) fetchRecentDraws(ctx context.Context, lotteryID string) ([]repository.Draw, error) {
+        latest, err := s.fetcher.GetLatestDraw(ctx, lotteryID)
+        if repository.IsNotFound(err) {
+                return nil, nil
+        }
+        if err != nil {
+                return nil, fmt.Errorf("%w: %v", ErrDrawsUnavailable, err)
+        }
+        s.archiveDraw(ctx, latest)
+        draws := []repository.Draw{*latest}
+
+        prelatest, err := s.fetcher.GetPrelatestDraw(ctx, lotteryID)
+        if err != nil {
+                // Последний тираж уже есть - отдаем хотя бы его
+                if !repository.IsNotFound(err) {
+                        log.Printf("[DrawService] Prelatest draw for %s unavailable: %v", lotteryID, err)
+                }
+                return draws, nil
+        }
+        s.archiveDraw(ctx, prelatest)
+        return append(draws, *prelatest), nil
+}
+
+// checkLottery проверяет, что лотерея есть в каталоге и у нее бывают тиражи
+func (s *DrawService) checkLottery(ctx context.Context, lotteryID string) error {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return err
+        }
+        if !hasDraws(*lottery) {
+                return fmt.Errorf("%w: у моментальной лотереи %s нет тиражей", ErrDrawNotFound, lotteryID)
+        }
+        return nil
+}
+
+// hasDraws проверяет, проводит ли лотерея тиражи
+// Тиражей нет только у моментальных лотерей без правил игры (билеты со стираемым слоем). Тиражные игры,
+// которые каталог Столото относит к моментальным (Рапидо, 12 из 24), определяются по правилам игры.
+func hasDraws(lottery domain.Lottery) bool {
+        return lottery.Type != domain.LotteryTypeInstant || lottery.GameRules != nil
+}
+
+// archiveDraw сохраняет разыгранный тираж в архив (ошибки только логируются)
+func (s *DrawService) archiveDraw(ctx context.Context, draw *repository.Draw) {
+        if s.archive == nil || len(draw.WinningNumbers) == 0 {
+                return
+        }
+
+        archived, err := s.archive.Has(ctx, draw.GameName, draw.Number)
+        if err == nil && !archived {
+                err = s.archive.Save(ctx, *draw)
+        }
+        if err != nil {
+                log.Printf("[DrawService] Failed to archive %s #%d: %v", draw.GameName, draw.Number, err)
+        }
+}
+
+// newDrawResultsPage конвертирует тиражи в страницу ответа
+func newDrawResultsPage(draws []repository.Draw, total, offset, limit int) *domain.DrawResultsPage {
+        results := make([]domain.DrawResult, 0, len(draws))
+        for _, draw := range draws {
+                results = append(results, repository.ConvertDrawToResult(draw))
+        }
+        return &domain.DrawResultsPage{
+                Draws:  results,
+                Total:  total,
+                Offset: offset,
+                Limit:  limit,
+        }
+}
+
+// liveProvenance - данные только что получены из StolotoAPI
+func liveProvenance() domain.DataProvenance {
+        now := time.Now()
+        return domain.DataProvenance{Source: domain.DataSourceLive, FetchedAt: &now}
+}
+
+// archiveProvenance - данные из локального архива тиражей
+func archiveProvenance() domain.DataProvenance {
+        return domain.DataProvenance{Source: domain.DataSourceArchive}
+}
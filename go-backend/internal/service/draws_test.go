@@ -0,0 +1,129 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// newTestDrawService создает DrawService с каталогом из тиражной лотереи, моментальной лотереи
+// и тиражной игры, которую каталог Столото относит к моментальным (Рапидо)
+func newTestDrawService(t *testing.T, fetcher *fakeDrawFetcher, withArchive bool) (*DrawService, repository.DrawArchive) {
+        t.Helper()
+
+        rapido := domain.Lottery{ID: "rapido", Type: domain.LotteryTypeInstant, IsActive: true}
+        repository.ApplyGameRules(&rapido)
+        catalog := &fakeProvider{name: "catalog", lotteries: []domain.Lottery{
+                {ID: "6x45", Type: domain.LotteryTypeNumbered, IsActive: true},
+                {ID: "scratch", Type: domain.LotteryTypeInstant, IsActive: true},
+                rapido,
+        }}
+        lotteries := NewStolotoService(nil, WithProviders(catalog))
+
+        var archive repository.DrawArchive
+        if withArchive {
+                fileArchive, err := repository.NewFileDrawArchive(t.TempDir())
+                if err != nil {
+                        t.Fatalf("Не удалось создать архив: %v", err)
+                }
+                archive = fileArchive
+        }
+        return NewDrawService(lotteries, fetcher, archive), archive
+}
+
+// TestDrawServiceGetDraw проверяет получение тиража, запись в архив и ошибки валидации
+func TestDrawServiceGetDraw(t *testing.T) {
+        ctx := context.Background()
+        fetcher := &fakeDrawFetcher{latest: 100, missing: map[int]bool{50: true}}
+        service, _ := newTestDrawService(t, fetcher, true)
+
+        draw, provenance, err := service.GetDraw(ctx, "6x45", 42)
+        if err != nil {
+                t.Fatalf("GetDraw: %v", err)
+        }
+        if draw.Number != 42 || draw.LotteryID != "6x45" || provenance.Source != domain.DataSourceLive {
+                t.Errorf("Ожидается live тираж 42 лотереи 6x45, получено %+v (%s)", draw, provenance.Source)
+        }
+
+        // Повторный запрос берется из архива, даже если StolotoAPI недоступен
+        fetcher.down = errors.New("upstream down")
+        _, provenance, err = service.GetDraw(ctx, "6x45", 42)
+        if err != nil || provenance.Source != domain.DataSourceArchive {
+                t.Errorf("Ожидается тираж из архива, получено %s, ошибка %v", provenance.Source, err)
+        }
+        if _, _, err := service.GetDraw(ctx, "6x45", 43); !errors.Is(err, ErrDrawsUnavailable) {
+                t.Errorf("Ожидается ErrDrawsUnavailable, получено %v", err)
+        }
+        fetcher.down = nil
+
+        if _, _, err := service.GetDraw(ctx, "6x45", 50); !errors.Is(err, ErrDrawNotFound) {
+                t.Errorf("Ожидается ErrDrawNotFound для отсутствующего тиража, получено %v", err)
+        }
+        if _, _, err := service.GetDraw(ctx, "unknown", 1); !errors.Is(err, ErrLotteryNotFound) {
+                t.Errorf("Ожидается ErrLotteryNotFound, получено %v", err)
+        }
+        if _, _, err := service.GetDraw(ctx, "scratch", 1); !errors.Is(err, ErrDrawNotFound) {
+                t.Errorf("У моментальной лотереи не должно быть тиражей, получено %v", err)
+        }
+        if draw, _, err := service.GetDraw(ctx, "rapido", 42); err != nil || draw.Number != 42 {
+                t.Errorf("У Рапидо (моментальная в каталоге, но с правилами игры) ожидается тираж 42, получено %+v, ошибка %v", draw, err)
+        }
+}
+
+// TestDrawServiceLatestFallsBackToArchive проверяет резервный последний тираж из архива
+func TestDrawServiceLatestFallsBackToArchive(t *testing.T) {
+        ctx := context.Background()
+        fetcher := &fakeDrawFetcher{latest: 100}
+        service, archive := newTestDrawService(t, fetcher, true)
+
+        if err := archive.Save(ctx, *fetcher.draw("6x45", 98), *fetcher.draw("6x45", 99)); err != nil {
+                t.Fatalf("Не удалось заполнить архив: %v", err)
+        }
+
+        fetcher.down = errors.New("upstream down")
+        draw, provenance, err := service.GetLatestDraw(ctx, "6x45")
+        if err != nil {
+                t.Fatalf("GetLatestDraw: %v", err)
+        }
+        if draw.Number != 99 || provenance.Source != domain.DataSourceArchive {
+                t.Errorf("Ожидается тираж 99 из архива, получено %d (%s)", draw.Number, provenance.Source)
+        }
+}
+
+// TestDrawServiceListDraws проверяет пагинацию по архиву и резервный список без архива
+func TestDrawServiceListDraws(t *testing.T) {
+        ctx := context.Background()
+
+        // Без архива - последний и предпоследний тиражи из StolotoAPI
+        service, _ := newTestDrawService(t, &fakeDrawFetcher{latest: 100}, false)
+        page, provenance, err := service.ListDraws(ctx, "6x45", 0, 0)
+        if err != nil {
+                t.Fatalf("ListDraws: %v", err)
+        }
+        if page.Total != 2 || len(page.Draws) != 2 || page.Draws[0].Number != 100 || page.Limit != defaultDrawsPageSize {
+                t.Errorf("Ожидаются тиражи 100 и 99, получено %+v", page)
+        }
+        if provenance.Source != domain.DataSourceLive {
+                t.Errorf("Ожидается live, получено %s", provenance.Source)
+        }
+
+        // С архивом - страница из архива
+        fetcher := &fakeDrawFetcher{latest: 100}
+        service, archive := newTestDrawService(t, fetcher, true)
+        for number := 1; number <= 30; number++ {
+                if err := archive.Save(ctx, *fetcher.draw("6x45", number)); err != nil {
+                        t.Fatalf("Не удалось заполнить архив: %v", err)
+                }
+        }
+
+        page, provenance, err = service.ListDraws(ctx, "6x45", 10, 5)
+        if err != nil {
+                t.Fatalf("ListDraws: %v", err)
+        }
+        if page.Total != 30 || len(page.Draws) != 5 || page.Draws[0].Number != 20 || provenance.Source != domain.DataSourceArchive {
+                t.Errorf("Ожидается страница 20..16 из 30 тиражей архива, получено %+v (%s)", page, provenance.Source)
+        }
+}
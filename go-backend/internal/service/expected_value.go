@@ -0,0 +1,155 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "math"
+        "sort"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// ErrOddsUnavailable возвращается, если для лотереи неизвестны вероятности и призы категорий
+var ErrOddsUnavailable = errors.New("вероятности выигрыша лотереи неизвестны")
+
+// DefaultValueWindow - сколько последних тиражей учитывать при оценке числа победителей
+const DefaultValueWindow = 50
+
+// GetExpectedValueReport возвращает простой EV лотереи и EV с учетом дележа призов
+// по числу победителей в последних window тиражах архива
+func (s *StatsService) GetExpectedValueReport(ctx context.Context, lotteryID string, window int) (*domain.ExpectedValueReport, error) {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if lottery.ExpectedValue == nil {
+                return nil, fmt.Errorf("%w: %s", ErrOddsUnavailable, lotteryID)
+        }
+        if window <= 0 {
+                window = DefaultValueWindow
+        }
+
+        var draws []repository.Draw
+        if s.archive != nil && hasDraws(*lottery) {
+                draws, _, err = s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID, Limit: window})
+                if err != nil {
+                        return nil, fmt.Errorf("ошибка чтения архива тиражей: %w", err)
+                }
+        }
+
+        report := ComputeExpectedValueReport(*lottery, draws)
+        return &report, nil
+}
+
+// ComputeExpectedValueReport считает EV лотереи с учетом дележа призов между победителями
+// Фиксированные призы не делятся. Для джекпота типичная выплата - джекпот * E[1/(1+X)],
+// где X - число других победителей, распределенное по Пуассону со средним из истории.
+// Для доли призового фонда - медиана фактических выплат на выигрышный билет.
+func ComputeExpectedValueReport(lottery domain.Lottery, draws []repository.Draw) domain.ExpectedValueReport {
+        report := domain.ExpectedValueReport{
+                LotteryID:   lottery.ID,
+                TicketPrice: lottery.TicketPrice,
+        }
+        if lottery.ExpectedValue != nil {
+                report.Plain = *lottery.ExpectedValue
+        }
+
+        // Учитываются только тиражи с данными о победителях
+        withWinners := make([]repository.Draw, 0, len(draws))
+        for _, draw := range draws {
+                if len(draw.Winners) > 0 {
+                        withWinners = append(withWinners, draw)
+                }
+        }
+        report.DrawsAnalyzed = len(withWinners)
+
+        var adjusted domain.ExpectedValue
+        for i, category := range lottery.PrizeStructure {
+                if category.Odds == nil || category.Payout == nil {
+                        continue
+                }
+
+                plainPrize := category.Payout.AveragePrize(lottery.TicketPrice, *category.Odds)
+                tier := domain.TierValue{
+                        Category:     category.Category,
+                        Kind:         category.Payout.Kind,
+                        Odds:         *category.Odds,
+                        PlainPrize:   plainPrize,
+                        TypicalPrize: plainPrize,
+                }
+
+                if key := tierKey(lottery, i); key != "" && len(withWinners) > 0 {
+                        averageWinners, payouts := tierHistory(withWinners, key)
+                        tier.AverageWinners = &averageWinners
+
+                        switch category.Payout.Kind {
+                        case domain.PayoutJackpot:
+                                tier.TypicalPrize = plainPrize * sharedPrizeFactor(averageWinners)
+                        case domain.PayoutPoolShare:
+                                if len(payouts) > 0 {
+                                        tier.TypicalPrize = median(payouts)
+                                }
+                        }
+                }
+
+                probability := tier.Odds.Float64()
+                adjusted.Gross += probability * tier.TypicalPrize
+                adjusted.Net += probability * domain.NetPrize(tier.TypicalPrize)
+                report.Tiers = append(report.Tiers, tier)
+        }
+
+        if report.DrawsAnalyzed > 0 && lottery.TicketPrice > 0 {
+                adjusted.GrossReturn = adjusted.Gross / lottery.TicketPrice
+                adjusted.NetReturn = adjusted.Net / lottery.TicketPrice
+                report.Adjusted = &adjusted
+        }
+        return report
+}
+
+// tierKey возвращает ключ категории i в победителях тиража ("" - неизвестен)
+func tierKey(lottery domain.Lottery, i int) string {
+        if lottery.GameRules == nil || i >= len(lottery.GameRules.Tiers) {
+                return ""
+        }
+        return lottery.GameRules.Tiers[i].Key
+}
+
+// tierHistory возвращает среднее число победителей категории за тираж
+// и фактические выплаты на выигрышный билет в тиражах, где победители были
+func tierHistory(draws []repository.Draw, key string) (float64, []float64) {
+        totalWinners := 0
+        var payouts []float64
+        for _, draw := range draws {
+                tier, ok := draw.Winners.Find(key)
+                if !ok || tier.Winners <= 0 {
+                        continue
+                }
+                totalWinners += tier.Winners
+                if tier.Prize > 0 {
+                        payouts = append(payouts, tier.Prize.Roubles())
+                }
+        }
+        return float64(totalWinners) / float64(len(draws)), payouts
+}
+
+// sharedPrizeFactor возвращает E[1/(1+X)] для X ~ Пуассон(lambda): (1 - e^-lambda) / lambda
+// Это ожидаемая доля приза, достающаяся победителю, если приз делится поровну
+func sharedPrizeFactor(lambda float64) float64 {
+        if lambda <= 1e-9 {
+                return 1
+        }
+        return -math.Expm1(-lambda) / lambda
+}
+
+// median возвращает медиану значений (values не пустой)
+func median(values []float64) float64 {
+        sorted := append([]float64(nil), values...)
+        sort.Float64s(sorted)
+        middle := len(sorted) / 2
+        if len(sorted)%2 == 1 {
+                return sorted[middle]
+        }
+        return (sorted[middle-1] + sorted[middle]) / 2
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+        "math"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// TestComputeExpectedValueReport проверяет EV с учетом дележа джекпота и долей призового фонда
+func TestComputeExpectedValueReport(t *testing.T) {
+        lottery := domain.Lottery{ID: "5x36plus", TicketPrice: 80, CurrentJackpot: 100000000}
+        if !repository.ApplyGameRules(&lottery) {
+                t.Fatal("Правила 5x36plus должны быть известны")
+        }
+
+        winners := func(jackpotWinners, poolWinners int, poolPrize repository.Money) repository.WinnerTiers {
+                return repository.WinnerTiers{
+                        {Tier: "5+1", Winners: jackpotWinners, Prize: 10000000000},
+                        {Tier: "5", Winners: poolWinners, Prize: poolPrize},
+                        {Tier: "4", Winners: 100, Prize: 500000},
+                }
+        }
+        draws := []repository.Draw{
+                {Number: 5, Winners: winners(1, 1, 20000000)},
+                {Number: 4, Winners: winners(0, 2, 30000000)},
+                {Number: 3}, // Тираж без данных о победителях не учитывается
+                {Number: 2, Winners: winners(0, 1, 40000000)},
+                {Number: 1, Winners: winners(0, 0, 0)},
+        }
+
+        report := ComputeExpectedValueReport(lottery, draws)
+        if report.DrawsAnalyzed != 4 || report.Adjusted == nil {
+                t.Fatalf("Ожидается 4 тиража и скорректированный EV: %+v", report)
+        }
+        if report.Plain != *lottery.ExpectedValue {
+                t.Errorf("Простой EV должен совпадать с EV лотереи: %+v", report.Plain)
+        }
+
+        // Джекпот: в среднем 0.25 победителя за тираж, доля победителя (1 - e^-0.25) / 0.25
+        jackpot := report.Tiers[0]
+        if jackpot.AverageWinners == nil || *jackpot.AverageWinners != 0.25 {
+                t.Fatalf("Джекпот: ожидается 0.25 победителя за тираж, получено %v", jackpot.AverageWinners)
+        }
+        expectedJackpot := 100000000 * (1 - math.Exp(-0.25)) / 0.25
+        if math.Abs(jackpot.TypicalPrize-expectedJackpot) > 1e-6 || jackpot.PlainPrize != 100000000 {
+                t.Errorf("Джекпот: ожидается %v, получено %+v", expectedJackpot, jackpot)
+        }
+
+        // Доля фонда: медиана фактических выплат 200 000, 300 000, 400 000 ₽
+        if pool := report.Tiers[1]; pool.Kind != domain.PayoutPoolShare || pool.TypicalPrize != 300000 {
+                t.Errorf("Доля фонда: ожидается типичная выплата 300000, получено %+v", pool)
+        }
+
+        // Фиксированный приз не делится
+        if fixed := report.Tiers[2]; fixed.TypicalPrize != fixed.PlainPrize || fixed.TypicalPrize != 5000 {
+                t.Errorf("Фиксированный приз не должен меняться: %+v", fixed)
+        }
+
+        gross := 0.0
+        for _, tier := range report.Tiers {
+                gross += tier.Odds.Float64() * tier.TypicalPrize
+        }
+        if math.Abs(report.Adjusted.Gross-gross) > 1e-9 || math.Abs(report.Adjusted.GrossReturn-gross/80) > 1e-9 {
+                t.Errorf("Скорректированный EV: ожидается %v, получено %+v", gross, report.Adjusted)
+        }
+        if report.Adjusted.Net >= report.Adjusted.Gross {
+                t.Errorf("EV после налога должен быть меньше: %+v", report.Adjusted)
+        }
+
+        empty := ComputeExpectedValueReport(lottery, nil)
+        if empty.Adjusted != nil || empty.DrawsAnalyzed != 0 || empty.Tiers[0].AverageWinners != nil {
+                t.Errorf("Без истории скорректированный EV не считается: %+v", empty)
+        }
+}
+
+// TestSharedPrizeFactor проверяет ожидаемую долю делимого приза
+func TestSharedPrizeFactor(t *testing.T) {
+        if sharedPrizeFactor(0) != 1 {
+                t.Error("Без других победителей приз не делится")
+        }
+        // При большом среднем числе победителей доля стремится к 1/lambda
+        if f := sharedPrizeFactor(100); math.Abs(f-0.01) > 1e-9 {
+                t.Errorf("Ожидается 0.01, получено %v", f)
+        }
+        if f := sharedPrizeFactor(1); math.Abs(f-(1-math.Exp(-1))) > 1e-12 {
+                t.Errorf("Ожидается 1 - e^-1, получено %v", f)
+        }
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+        "context"
+        "fmt"
+        "math"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+const (
+        // DefaultOddsTickets - билетов в тираже по умолчанию для расчета шансов
+        DefaultOddsTickets = 1
+        // DefaultOddsDraws - количество тиражей по умолчанию для расчета шансов
+        DefaultOddsDraws = 100
+
+        anyPrizeCategory = "Любой выигрыш"
+)
+
+// GetCumulativeOdds возвращает шансы выиграть в каждой категории, играя tickets билетами в каждом из draws тиражей
+func (s *StolotoService) GetCumulativeOdds(ctx context.Context, id string, tickets, draws int) (*domain.CumulativeOdds, error) {
+        lottery, err := s.GetLotteryByID(ctx, id)
+        if err != nil {
+                return nil, err
+        }
+        if tickets <= 0 {
+                tickets = DefaultOddsTickets
+        }
+        if draws <= 0 {
+                draws = DefaultOddsDraws
+        }
+
+        odds, ok := ComputeCumulativeOdds(*lottery, tickets, draws)
+        if !ok {
+                return nil, fmt.Errorf("%w: %s", ErrOddsUnavailable, id)
+        }
+        return &odds, nil
+}
+
+// ComputeCumulativeOdds считает шансы по вероятностям категорий на один билет
+// Билеты одного тиража - разные комбинации. Если категорию в тираже выигрывает только одна комбинация (суперприз),
+// шанс хотя бы одного приза за тираж точный: tickets * p. Иначе он оценивается как для независимых билетов,
+// 1 - (1 - p)^tickets, и категория помечается exact = false (с одним билетом расчет всегда точный).
+// Тиражи независимы, поэтому шанс за draws тиражей - 1 - (1 - P)^draws, а до первого приза в среднем 1 / P тиражей.
+// Вероятность любого выигрыша - сумма вероятностей категорий, а без них - WinProbability лотереи.
+// Возвращает false, если вероятности выигрыша лотереи неизвестны.
+func ComputeCumulativeOdds(lottery domain.Lottery, tickets, draws int) (domain.CumulativeOdds, bool) {
+        odds := domain.CumulativeOdds{
+                LotteryID:      lottery.ID,
+                TicketPrice:    lottery.TicketPrice,
+                TicketsPerDraw: tickets,
+                Draws:          draws,
+                Cost:           float64(tickets*draws) * lottery.TicketPrice,
+                Tiers:          []domain.TierCumulativeOdds{},
+        }
+
+        anyPrize := 0.0
+        for i, category := range lottery.PrizeStructure {
+                if category.Odds == nil || category.Odds.Float64() <= 0 {
+                        continue
+                }
+                probability := category.Odds.Float64()
+                anyPrize += probability
+                singleWinner := lottery.GameRules != nil && lottery.GameRules.SingleWinner(i)
+                odds.Tiers = append(odds.Tiers, cumulativeTier(category.Category, probability, singleWinner, lottery.TicketPrice, tickets, draws))
+        }
+        if len(odds.Tiers) == 0 {
+                anyPrize = lottery.WinProbability / 100 // WinProbability хранится в процентах
+        }
+        if anyPrize <= 0 {
+                return odds, false
+        }
+
+        odds.AnyPrize = cumulativeTier(anyPrizeCategory, math.Min(anyPrize, 1), false, lottery.TicketPrice, tickets, draws)
+        return odds, true
+}
+
+// cumulativeTier считает шансы категории с вероятностью probability на один билет
+// singleWinner - категорию в тираже выигрывает только одна комбинация
+func cumulativeTier(category string, probability float64, singleWinner bool, ticketPrice float64, tickets, draws int) domain.TierCumulativeOdds {
+        tier := domain.TierCumulativeOdds{
+                Category:    category,
+                Probability: probability,
+                Exact:       singleWinner || tickets == 1,
+        }
+        if singleWinner {
+                tier.PerDraw = math.Min(float64(tickets)*probability, 1)
+        } else {
+                tier.PerDraw = atLeastOne(probability, tickets)
+        }
+        tier.AtLeastOne = atLeastOne(tier.PerDraw, draws)
+        tier.ExpectedDraws = 1 / tier.PerDraw
+        if ticketPrice > 0 {
+                spend := tier.ExpectedDraws * float64(tickets) * ticketPrice
+                tier.ExpectedSpend = &spend
+        }
+        return tier
+}
+
+// atLeastOne возвращает вероятность хотя бы одного успеха в n независимых испытаниях: 1 - (1 - p)^n
+// Считается через log1p/expm1, чтобы не терять точность на вероятностях порядка 1e-8
+func atLeastOne(probability float64, n int) float64 {
+        if probability >= 1 {
+                return 1
+        }
+        return -math.Expm1(float64(n) * math.Log1p(-probability))
+}
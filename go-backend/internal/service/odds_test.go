@@ -0,0 +1,81 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "math"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestGetCumulativeOdds проверяет шансы категорий за несколько тиражей и ожидаемые затраты до первого приза
+func TestGetCumulativeOdds(t *testing.T) {
+        lotteries := newTestLotteries(t)
+        ctx := context.Background()
+
+        odds, err := lotteries.GetCumulativeOdds(ctx, "6x45", 2, 50)
+        if err != nil {
+                t.Fatalf("GetCumulativeOdds: %v", err)
+        }
+        if odds.TicketsPerDraw != 2 || odds.Draws != 50 || odds.Cost != 10000 || len(odds.Tiers) == 0 {
+                t.Fatalf("Ожидается 100 билетов по 100 ₽ и категории 6x45, получено %+v", odds)
+        }
+
+        jackpot := odds.Tiers[0]
+        p := 1.0 / 8145060
+        if jackpot.Category != "6 из 6" || jackpot.Probability != p {
+                t.Errorf("Ожидается категория 6 из 6 с вероятностью 1:8145060, получено %+v", jackpot)
+        }
+        if !jackpot.Exact || jackpot.PerDraw != 2*p {
+                t.Errorf("Суперприз двумя разными комбинациями: ожидается точный шанс за тираж %v, получено %+v", 2*p, jackpot)
+        }
+        if expected := 1 - math.Pow(1-2*p, 50); math.Abs(jackpot.AtLeastOne-expected)/expected > 1e-9 {
+                t.Errorf("Шанс за 50 тиражей: ожидается %v, получено %v", expected, jackpot.AtLeastOne)
+        }
+        if expected := 1 / (2 * p); math.Abs(jackpot.ExpectedDraws-expected)/expected > 1e-9 {
+                t.Errorf("Тиражей до суперприза: ожидается %v, получено %v", expected, jackpot.ExpectedDraws)
+        }
+        if jackpot.ExpectedSpend == nil || math.Abs(*jackpot.ExpectedSpend-jackpot.ExpectedDraws*200) > 1e-6 {
+                t.Errorf("Затраты до суперприза: ожидается %v, получено %v", jackpot.ExpectedDraws*200, jackpot.ExpectedSpend)
+        }
+
+        sum := 0.0
+        for _, tier := range odds.Tiers {
+                sum += tier.Probability
+                if tier.AtLeastOne < tier.PerDraw || tier.AtLeastOne > odds.AnyPrize.AtLeastOne {
+                        t.Errorf("Категория %s: шанс за все тиражи %v не между шансом за тираж %v и любым выигрышем %v",
+                                tier.Category, tier.AtLeastOne, tier.PerDraw, odds.AnyPrize.AtLeastOne)
+                }
+        }
+        if lower := odds.Tiers[len(odds.Tiers)-1]; lower.Exact {
+                t.Errorf("Категория %s с двумя билетами: ожидается оценка, а не точный расчет", lower.Category)
+        }
+        if odds.AnyPrize.Exact {
+                t.Error("Любой выигрыш с двумя билетами: ожидается оценка, а не точный расчет")
+        }
+        if math.Abs(odds.AnyPrize.Probability-sum) > 1e-15 {
+                t.Errorf("Любой выигрыш: ожидается сумма категорий %v, получено %v", sum, odds.AnyPrize.Probability)
+        }
+
+        if _, err := lotteries.GetCumulativeOdds(ctx, "ruslotto", 1, 1); !errors.Is(err, ErrOddsUnavailable) {
+                t.Errorf("Лотерея без вероятностей: ожидается ErrOddsUnavailable, получено %v", err)
+        }
+}
+
+// TestComputeCumulativeOddsWinProbability проверяет расчет по WinProbability без вероятностей категорий
+func TestComputeCumulativeOddsWinProbability(t *testing.T) {
+        odds, ok := ComputeCumulativeOdds(domain.Lottery{ID: "momental", WinProbability: 25}, 1, 4)
+        if !ok {
+                t.Fatal("Ожидается расчет по WinProbability")
+        }
+        if odds.AnyPrize.Probability != 0.25 || math.Abs(odds.AnyPrize.AtLeastOne-0.68359375) > 1e-12 || math.Abs(odds.AnyPrize.ExpectedDraws-4) > 1e-12 {
+                t.Errorf("Ожидается 1 - 0.75^4 за 4 тиража и 4 тиража до выигрыша, получено %+v", odds.AnyPrize)
+        }
+        if !odds.AnyPrize.Exact {
+                t.Error("С одним билетом в тираже ожидается точный расчет")
+        }
+        if odds.AnyPrize.ExpectedSpend != nil || len(odds.Tiers) != 0 {
+                t.Errorf("Без цены билета затраты не передаются, получено %+v", odds)
+        }
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "math/big"
+        "math/rand"
+        "sort"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// ErrInvalidPicks возвращается, если запрошенное количество разных комбинаций нельзя сгенерировать
+var ErrInvalidPicks = errors.New("нельзя сгенерировать столько разных комбинаций")
+
+const (
+        // DefaultPickWindow - сколько последних тиражей учитывают стратегии hot и cold по умолчанию
+        DefaultPickWindow = 100
+
+        maxPickAttempts = 1000 // Попыток подобрать комбинацию под ограничения стратегии
+        birthdayMax     = 31   // Числа до 31 часто выбирают по датам рождения
+)
+
+// GeneratePicks генерирует комбинации по правилам игры лотереи выбранной стратегией
+// С одним и тем же зерном (и той же историей тиражей для avoidPopular, hot и cold) результат повторяется
+func (s *StatsService) GeneratePicks(ctx context.Context, lotteryID string, request domain.PicksRequest) (*domain.PicksResult, error) {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if lottery.GameRules == nil {
+                return nil, fmt.Errorf("%w: %s", ErrRulesUnavailable, lotteryID)
+        }
+        rules := *lottery.GameRules
+
+        if request.Count <= 0 {
+                request.Count = 1
+        }
+        if request.Strategy == "" {
+                request.Strategy = domain.PickStrategyRandom
+        }
+        if request.Window <= 0 {
+                request.Window = DefaultPickWindow
+        }
+        seed := rand.Int63()
+        if request.Seed != nil {
+                seed = *request.Seed
+        }
+
+        result := &domain.PicksResult{LotteryID: lotteryID, Strategy: request.Strategy, Seed: seed}
+        generator := &pickGenerator{rules: rules, strategy: request.Strategy, rng: rand.New(rand.NewSource(seed))}
+        switch request.Strategy {
+        case domain.PickStrategyHot, domain.PickStrategyCold:
+                history, err := s.drawnHistory(ctx, lotteryID, rules, request.Window)
+                if err != nil {
+                        return nil, err
+                }
+                generator.weights = pickWeights(rules, history, request.Strategy == domain.PickStrategyHot)
+                result.Draws = len(history)
+        case domain.PickStrategyAvoidPopular:
+                // Без истории тиражей отсеиваются только даты и прогрессии
+                history, err := s.drawnHistory(ctx, lotteryID, rules, 0)
+                if err != nil && !errors.Is(err, ErrNoDrawHistory) {
+                        return nil, err
+                }
+                generator.previous = make(map[string]bool, len(history))
+                for _, drawn := range history {
+                        generator.previous[combinationKey(drawn)] = true
+                }
+                result.Draws = len(history)
+        }
+
+        result.Combinations, err = generator.generate(request.Count)
+        if err != nil {
+                return nil, err
+        }
+        return result, nil
+}
+
+// drawnHistory возвращает выигрышные комбинации последних limit тиражей архива по полям (0 - все)
+func (s *StatsService) drawnHistory(ctx context.Context, lotteryID string, rules domain.GameRules, limit int) ([][][]int, error) {
+        if s.archive == nil {
+                return nil, fmt.Errorf("%w: архив тиражей отключен", ErrNoDrawHistory)
+        }
+        draws, _, err := s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID, Limit: limit})
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения архива тиражей: %w", err)
+        }
+
+        history := make([][][]int, 0, len(draws))
+        for _, draw := range draws {
+                drawn, err := rules.SplitDrawn(draw.WinningNumbers)
+                if err != nil || !drawnInRange(rules, drawn) {
+                        continue
+                }
+                history = append(history, drawn)
+        }
+        if len(history) == 0 {
+                return nil, fmt.Errorf("%w: %s", ErrNoDrawHistory, lotteryID)
+        }
+        return history, nil
+}
+
+// drawnInRange проверяет, что выпавшие числа каждого поля лежат от 1 до Of
+func drawnInRange(rules domain.GameRules, drawn [][]int) bool {
+        for i, field := range rules.Fields {
+                for _, n := range drawn[i] {
+                        if n < 1 || n > field.Of {
+                                return false
+                        }
+                }
+        }
+        return true
+}
+
+// pickWeights считает веса чисел каждого поля по частоте выпадения (countFrequencies)
+// hot: вес - число выпадений + 1; cold: вес растет с числом тиражей, в которых число не выпало
+func pickWeights(rules domain.GameRules, history [][][]int, hot bool) [][]float64 {
+        weights := make([][]float64, len(rules.Fields))
+        for i, field := range rules.Fields {
+                combinations := make([][]int, 0, len(history))
+                for _, drawn := range history {
+                        combinations = append(combinations, drawn[i])
+                }
+
+                frequencies := countFrequencies(combinations, field.Of)
+                weights[i] = make([]float64, field.Of+1)
+                for _, frequency := range frequencies {
+                        if hot {
+                                weights[i][frequency.Number] = float64(frequency.Count + 1)
+                        } else {
+                                weights[i][frequency.Number] = float64(len(history) - frequency.Count + 1)
+                        }
+                }
+        }
+        return weights
+}
+
+// pickGenerator генерирует комбинации по правилам игры
+type pickGenerator struct {
+        rules    domain.GameRules
+        strategy domain.PickStrategy
+        rng      *rand.Rand
+        weights  [][]float64     // Веса чисел по полям (индекс - число); nil - числа равновероятны
+        previous map[string]bool // Прошлые выигрышные комбинации (combinationKey)
+}
+
+// generate возвращает count разных комбинаций
+// Если за maxPickAttempts попыток новую комбинацию под ограничения стратегии не подобрать,
+// берется любая новая комбинация: комбинации в ответе не повторяются
+func (g *pickGenerator) generate(count int) ([]domain.TicketCombination, error) {
+        if total := g.rules.Combinations(); total.Cmp(big.NewInt(int64(count))) < 0 {
+                return nil, fmt.Errorf("%w: в игре всего %s разных комбинаций, запрошено %d", ErrInvalidPicks, total, count)
+        }
+
+        combinations := make([]domain.TicketCombination, 0, count)
+        seen := make(map[string]bool, count)
+        for len(combinations) < count {
+                candidate, ok := g.next(seen, true)
+                if !ok {
+                        candidate, ok = g.next(seen, false)
+                }
+                if !ok {
+                        return nil, fmt.Errorf("%w: за %d попыток подобрано %d из %d", ErrInvalidPicks, maxPickAttempts, len(combinations), count)
+                }
+                seen[combinationKey(candidate)] = true
+                combinations = append(combinations, candidate)
+        }
+        return combinations, nil
+}
+
+// next подбирает комбинацию, которой еще нет в seen (strict - и под ограничения стратегии)
+func (g *pickGenerator) next(seen map[string]bool, strict bool) (domain.TicketCombination, bool) {
+        for attempt := 0; attempt < maxPickAttempts; attempt++ {
+                candidate := g.candidate()
+                if !seen[combinationKey(candidate)] && (!strict || g.acceptable(candidate)) {
+                        return candidate, true
+                }
+        }
+        return nil, false
+}
+
+// candidate выбирает числа каждого поля (по возрастанию)
+func (g *pickGenerator) candidate() domain.TicketCombination {
+        combination := make(domain.TicketCombination, len(g.rules.Fields))
+        for i, field := range g.rules.Fields {
+                var numbers []int
+                if g.weights != nil {
+                        numbers = weightedSample(g.rng, g.weights[i], field.Pick)
+                } else {
+                        numbers = g.rng.Perm(field.Of)[:field.Pick]
+                        for j := range numbers {
+                                numbers[j]++
+                        }
+                }
+                sort.Ints(numbers)
+                combination[i] = numbers
+        }
+        return combination
+}
+
+// acceptable проверяет ограничения стратегии
+func (g *pickGenerator) acceptable(combination domain.TicketCombination) bool {
+        switch g.strategy {
+        case domain.PickStrategyBalanced:
+                for i, field := range g.rules.Fields {
+                        if !balancedField(combination[i], field) {
+                                return false
+                        }
+                }
+        case domain.PickStrategyAvoidPopular:
+                if g.previous[combinationKey(combination)] {
+                        return false
+                }
+                for i, field := range g.rules.Fields {
+                        if popularField(combination[i], field) {
+                                return false
+                        }
+                }
+        }
+        return true
+}
+
+// weightedSample выбирает count разных чисел с вероятностью, пропорциональной весу (weights[0] не используется)
+func weightedSample(rng *rand.Rand, weights []float64, count int) []int {
+        remaining := append([]float64(nil), weights...)
+        remaining[0] = 0
+        numbers := make([]int, 0, count)
+        for len(numbers) < count {
+                total := 0.0
+                for _, weight := range remaining {
+                        total += weight
+                }
+                target := rng.Float64() * total
+                chosen := 0
+                for n, weight := range remaining {
+                        if weight <= 0 {
+                                continue
+                        }
+                        chosen = n
+                        if target < weight {
+                                break
+                        }
+                        target -= weight
+                }
+                numbers = append(numbers, chosen)
+                remaining[chosen] = 0
+        }
+        return numbers
+}
+
+// balancedField проверяет, что четных и нечетных, малых (до половины поля) и больших чисел поровну (±1)
+func balancedField(numbers []int, field domain.NumberField) bool {
+        if len(numbers) < 2 {
+                return true
+        }
+        odd, low := 0, 0
+        for _, n := range numbers {
+                if n%2 == 1 {
+                        odd++
+                }
+                if n <= field.Of/2 {
+                        low++
+                }
+        }
+        return abs(2*odd-len(numbers)) <= 1 && abs(2*low-len(numbers)) <= 1
+}
+
+// popularField проверяет, похожи ли числа поля (по возрастанию) на популярную у игроков комбинацию:
+// все числа - дни месяца, три числа подряд или арифметическая прогрессия из 4 чисел (из 3 для полей до 3 чисел)
+func popularField(numbers []int, field domain.NumberField) bool {
+        if len(numbers) < 3 {
+                return false
+        }
+        if field.Of > birthdayMax && numbers[len(numbers)-1] <= birthdayMax {
+                return true
+        }
+
+        progression := 4
+        if len(numbers) < progression {
+                progression = len(numbers)
+        }
+        run := 1 // Длина текущей последовательности с одинаковым шагом
+        for i := 1; i < len(numbers); i++ {
+                step := numbers[i] - numbers[i-1]
+                if i > 1 && step == numbers[i-1]-numbers[i-2] {
+                        run++
+                } else {
+                        run = 2
+                }
+                if run >= progression || (step == 1 && run >= 3) {
+                        return true
+                }
+        }
+        return false
+}
+
+// combinationKey возвращает ключ комбинации по полям (числа полей по возрастанию)
+func combinationKey(combination [][]int) string {
+        fields := make([][]int, len(combination))
+        for i, numbers := range combination {
+                fields[i] = append([]int(nil), numbers...)
+                sort.Ints(fields[i])
+        }
+        return fmt.Sprint(fields)
+}
+
+// abs возвращает модуль числа
+func abs(n int) int {
+        if n < 0 {
+                return -n
+        }
+        return n
+}
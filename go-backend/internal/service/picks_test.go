@@ -0,0 +1,175 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "math/rand"
+        "reflect"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// newTestPicksService создает StatsService с лотереями newTestTicketService
+// В архиве 30 тиражей 6x45, в каждом выпали числа 1-6
+func newTestPicksService(t *testing.T) *StatsService {
+        t.Helper()
+
+        draws := make([]repository.Draw, 0, 30)
+        for number := 1; number <= 30; number++ {
+                draws = append(draws, repository.Draw{Number: number, GameName: "6x45", WinningNumbers: []int{1, 2, 3, 4, 5, 6}})
+        }
+        service := newTestTicketService(t, draws...)
+        return NewStatsService(service.lotteries, service.archive)
+}
+
+// TestGeneratePicks проверяет, что комбинации соответствуют правилам, стратегии и повторяются с тем же зерном
+func TestGeneratePicks(t *testing.T) {
+        service := newTestPicksService(t)
+        ctx := context.Background()
+        seed := int64(42)
+        lottery, err := service.lotteries.GetLotteryByID(ctx, "6x45")
+        if err != nil {
+                t.Fatalf("GetLotteryByID: %v", err)
+        }
+        gameRules := *lottery.GameRules
+
+        strategies := []domain.PickStrategy{
+                domain.PickStrategyRandom, domain.PickStrategyAvoidPopular, domain.PickStrategyBalanced,
+                domain.PickStrategyHot, domain.PickStrategyCold,
+        }
+        for _, strategy := range strategies {
+                request := domain.PicksRequest{Count: 50, Strategy: strategy, Seed: &seed}
+                result, err := service.GeneratePicks(ctx, "6x45", request)
+                if err != nil {
+                        t.Fatalf("%s: %v", strategy, err)
+                }
+                if result.Strategy != strategy || result.Seed != seed || len(result.Combinations) != 50 {
+                        t.Fatalf("%s: некорректный результат %+v", strategy, result)
+                }
+
+                seen := make(map[string]bool)
+                popular := 0 // Комбинации с числами 1-6, выпадавшими в каждом тираже
+                for _, combination := range result.Combinations {
+                        if err := gameRules.ValidatePicks(combination); err != nil {
+                                t.Fatalf("%s: комбинация %v не по правилам: %v", strategy, combination, err)
+                        }
+                        key := combinationKey(combination)
+                        if seen[key] {
+                                t.Errorf("%s: комбинация %v повторяется", strategy, combination)
+                        }
+                        seen[key] = true
+
+                        for _, n := range combination[0] {
+                                if n <= 6 {
+                                        popular++
+                                }
+                        }
+                        switch strategy {
+                        case domain.PickStrategyBalanced:
+                                if !balancedField(combination[0], gameRules.Fields[0]) {
+                                        t.Errorf("Несбалансированная комбинация %v", combination)
+                                }
+                        case domain.PickStrategyAvoidPopular:
+                                if popularField(combination[0], gameRules.Fields[0]) {
+                                        t.Errorf("Популярная комбинация %v", combination)
+                                }
+                        }
+                }
+
+                // Равновероятно на 50 комбинаций приходится около 50*6*6/45 = 40 чисел от 1 до 6
+                switch strategy {
+                case domain.PickStrategyHot:
+                        if popular < 150 || result.Draws != 30 {
+                                t.Errorf("hot: ожидается преобладание чисел 1-6 по 30 тиражам, получено %d чисел, %d тиражей", popular, result.Draws)
+                        }
+                case domain.PickStrategyCold:
+                        if popular > 5 {
+                                t.Errorf("cold: числа 1-6 должны почти не выбираться, получено %d", popular)
+                        }
+                }
+
+                repeated, err := service.GeneratePicks(ctx, "6x45", request)
+                if err != nil || !reflect.DeepEqual(repeated.Combinations, result.Combinations) {
+                        t.Errorf("%s: с тем же зерном ожидаются те же комбинации", strategy)
+                }
+        }
+
+        result, err := service.GeneratePicks(ctx, "5x36plus", domain.PicksRequest{Count: 3, Strategy: domain.PickStrategyBalanced})
+        if err != nil {
+                t.Fatalf("5x36plus: %v", err)
+        }
+        for _, combination := range result.Combinations {
+                if len(combination) != 2 || len(combination[0]) != 5 || len(combination[1]) != 1 {
+                        t.Errorf("5x36plus: ожидается 5 чисел и 1 число по полям, получено %v", combination)
+                }
+        }
+
+        if _, err := service.GeneratePicks(ctx, "5x36plus", domain.PicksRequest{Strategy: domain.PickStrategyHot}); !errors.Is(err, ErrNoDrawHistory) {
+                t.Errorf("hot без истории тиражей: ожидается ErrNoDrawHistory, получено %v", err)
+        }
+        if _, err := service.GeneratePicks(ctx, "ruslotto", domain.PicksRequest{}); !errors.Is(err, ErrRulesUnavailable) {
+                t.Errorf("Лотерея без правил: ожидается ErrRulesUnavailable, получено %v", err)
+        }
+}
+
+// TestPopularField проверяет распознавание популярных шаблонов
+func TestPopularField(t *testing.T) {
+        field := domain.NumberField{Pick: 6, Of: 45}
+        tests := []struct {
+                numbers []int
+                popular bool
+        }{
+                {[]int{3, 9, 14, 22, 27, 31}, true},  // Все числа - дни месяца
+                {[]int{3, 9, 14, 15, 16, 40}, true},  // Три числа подряд
+                {[]int{5, 10, 15, 20, 33, 41}, true}, // Прогрессия с шагом 5
+                {[]int{2, 9, 14, 15, 33, 41}, false},
+                {[]int{7, 14, 21, 30, 39, 44}, false}, // Прогрессия из трех чисел допустима
+        }
+        for _, tt := range tests {
+                if popular := popularField(tt.numbers, field); popular != tt.popular {
+                        t.Errorf("%v: ожидается %v, получено %v", tt.numbers, tt.popular, popular)
+                }
+        }
+
+        generator := &pickGenerator{
+                rules:    domain.GameRules{Fields: []domain.NumberField{field}},
+                strategy: domain.PickStrategyAvoidPopular,
+                previous: map[string]bool{combinationKey([][]int{{2, 9, 14, 15, 33, 41}}): true},
+        }
+        if generator.acceptable(domain.TicketCombination{{41, 33, 15, 14, 9, 2}}) {
+                t.Error("Прошлая выигрышная комбинация должна отсеиваться")
+        }
+}
+
+// TestPickGeneratorUnique проверяет, что комбинации не повторяются, даже если ограничения стратегии не выполнить
+func TestPickGeneratorUnique(t *testing.T) {
+        // 2 из 4: всего 6 комбинаций, сбалансированных (одно четное, одно малое) - только 2: {1,4} и {2,3}
+        rules := domain.GameRules{Fields: []domain.NumberField{{Pick: 2, Of: 4}}}
+        generator := &pickGenerator{rules: rules, strategy: domain.PickStrategyBalanced, rng: rand.New(rand.NewSource(1))}
+
+        combinations, err := generator.generate(6)
+        if err != nil {
+                t.Fatalf("generate: %v", err)
+        }
+        seen := make(map[string]bool)
+        for _, combination := range combinations {
+                if seen[combinationKey(combination)] {
+                        t.Errorf("Комбинация %v повторяется", combination)
+                }
+                seen[combinationKey(combination)] = true
+        }
+        if len(seen) != 6 {
+                t.Errorf("Ожидаются все 6 комбинаций, получено %v", combinations)
+        }
+        for _, combination := range combinations[:2] {
+                if !balancedField(combination[0], rules.Fields[0]) {
+                        t.Errorf("Пока есть сбалансированные комбинации, берутся они: %v", combinations)
+                }
+        }
+
+        if _, err := generator.generate(7); !errors.Is(err, ErrInvalidPicks) {
+                t.Errorf("7 комбинаций из 6 возможных: ожидается ErrInvalidPicks, получено %v", err)
+        }
+}
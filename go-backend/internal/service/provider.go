@@ -0,0 +1,200 @@
+package service
+
+import (
+        "context"
+        "encoding/json"
+        "errors"
+        "fmt"
+        "log"
+        "os"
+        "sync"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// LotteryProvider представляет источник данных для каталога лотерей
+type LotteryProvider interface {
+        // Name возвращает имя источника для логов
+        Name() string
+        // FetchLotteries возвращает лотереи источника в формате domain.Lottery
+        FetchLotteries(ctx context.Context) ([]domain.Lottery, error)
+}
+
+// DrawsProvider - тиражные лотереи из /api/draws/ StolotoAPI
+type DrawsProvider struct {
+        client *repository.StolotoClient
+}
+
+// NewDrawsProvider создает новый экземпляр DrawsProvider
+func NewDrawsProvider(client *repository.StolotoClient) *DrawsProvider {
+        return &DrawsProvider{client: client}
+}
+
+// Name возвращает имя источника
+func (p *DrawsProvider) Name() string {
+        return "stoloto-draws"
+}
+
+// FetchLotteries получает игры из StolotoAPI и конвертирует их в domain.Lottery
+func (p *DrawsProvider) FetchLotteries(ctx context.Context) ([]domain.Lottery, error) {
+        drawsResp, err := p.client.GetAllDraws(ctx)
+        if err != nil {
+                return nil, err
+        }
+
+        lotteries := make([]domain.Lottery, 0, len(drawsResp.Games))
+        for _, game := range drawsResp.Games {
+                lotteries = append(lotteries, p.client.ConvertGameToLottery(game))
+        }
+        return lotteries, nil
+}
+
+// MomentalProvider - моментальные лотереи из /api/draw/momental StolotoAPI
+type MomentalProvider struct {
+        client *repository.StolotoClient
+}
+
+// NewMomentalProvider создает новый экземпляр MomentalProvider
+func NewMomentalProvider(client *repository.StolotoClient) *MomentalProvider {
+        return &MomentalProvider{client: client}
+}
+
+// Name возвращает имя источника
+func (p *MomentalProvider) Name() string {
+        return "stoloto-momental"
+}
+
+// FetchLotteries получает скретч-карты из StolotoAPI и конвертирует их в domain.Lottery
+func (p *MomentalProvider) FetchLotteries(ctx context.Context) ([]domain.Lottery, error) {
+        momentalResp, err := p.client.GetMomentalCards(ctx)
+        if err != nil {
+                return nil, err
+        }
+
+        lotteries := make([]domain.Lottery, 0, len(momentalResp.MomentalCards))
+        for _, card := range momentalResp.MomentalCards {
+                lotteries = append(lotteries, p.client.ConvertMomentalToLottery(card))
+        }
+        return lotteries, nil
+}
+
+// StaticCatalogProvider - лотереи из статического JSON файла (массив domain.Lottery)
+// Файл читается при каждом запросе, поэтому его можно править без перезапуска сервера
+type StaticCatalogProvider struct {
+        path string
+}
+
+// NewStaticCatalogProvider создает новый экземпляр StaticCatalogProvider
+func NewStaticCatalogProvider(path string) *StaticCatalogProvider {
+        return &StaticCatalogProvider{path: path}
+}
+
+// Name возвращает имя источника
+func (p *StaticCatalogProvider) Name() string {
+        return "static-catalog"
+}
+
+// FetchLotteries читает лотереи из файла каталога
+func (p *StaticCatalogProvider) FetchLotteries(ctx context.Context) ([]domain.Lottery, error) {
+        data, err := os.ReadFile(p.path)
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения каталога %s: %w", p.path, err)
+        }
+
+        var lotteries []domain.Lottery
+        if err := json.Unmarshal(data, &lotteries); err != nil {
+                return nil, fmt.Errorf("ошибка декодирования каталога %s: %w", p.path, err)
+        }
+        return lotteries, nil
+}
+
+// DefaultProviders возвращает стандартный набор источников StolotoAPI
+func DefaultProviders(client *repository.StolotoClient) []LotteryProvider {
+        return []LotteryProvider{
+                NewDrawsProvider(client),
+                NewMomentalProvider(client),
+        }
+}
+
+// ProviderRegistry объединяет данные нескольких источников в один каталог
+// Источники опрашиваются параллельно; при совпадении ID приоритет у источника,
+// зарегистрированного раньше. Ошибка одного источника не влияет на остальные.
+type ProviderRegistry struct {
+        providers []LotteryProvider
+}
+
+// NewProviderRegistry создает новый экземпляр ProviderRegistry
+func NewProviderRegistry(providers ...LotteryProvider) *ProviderRegistry {
+        return &ProviderRegistry{providers: providers}
+}
+
+// Register добавляет источник с наименьшим приоритетом
+func (r *ProviderRegistry) Register(provider LotteryProvider) {
+        r.providers = append(r.providers, provider)
+}
+
+// providerResult - результат опроса одного источника
+type providerResult struct {
+        lotteries []domain.Lottery
+        err       error
+}
+
+// FetchAll опрашивает все источники и возвращает объединенный список без дубликатов
+// Ошибка возвращается только если не ответил ни один источник
+func (r *ProviderRegistry) FetchAll(ctx context.Context) ([]domain.Lottery, error) {
+        if len(r.providers) == 0 {
+                return nil, errors.New("не зарегистрировано ни одного источника лотерей")
+        }
+
+        results := make([]providerResult, len(r.providers))
+        var wg sync.WaitGroup
+        for i, provider := range r.providers {
+                wg.Add(1)
+                go func(i int, provider LotteryProvider) {
+                        defer wg.Done()
+                        results[i] = fetchFromProvider(ctx, provider)
+                }(i, provider)
+        }
+        wg.Wait()
+
+        merged := make([]domain.Lottery, 0)
+        seen := make(map[string]struct{})
+        errs := make([]error, 0)
+        for i, result := range results {
+                name := r.providers[i].Name()
+                if result.err != nil {
+                        log.Printf("[ProviderRegistry] Provider %s failed: %v", name, result.err)
+                        errs = append(errs, fmt.Errorf("%s: %w", name, result.err))
+                        continue
+                }
+
+                added := 0
+                for _, lottery := range result.lotteries {
+                        if _, duplicate := seen[lottery.ID]; duplicate {
+                                continue
+                        }
+                        seen[lottery.ID] = struct{}{}
+                        merged = append(merged, lottery)
+                        added++
+                }
+                log.Printf("[ProviderRegistry] Provider %s returned %d lotteries (%d new)", name, len(result.lotteries), added)
+        }
+
+        if len(errs) == len(r.providers) {
+                return nil, errors.Join(errs...)
+        }
+        return merged, nil
+}
+
+// fetchFromProvider опрашивает источник, превращая панику в ошибку
+func fetchFromProvider(ctx context.Context, provider LotteryProvider) (result providerResult) {
+        defer func() {
+                if rec := recover(); rec != nil {
+                        result = providerResult{err: fmt.Errorf("паника в источнике: %v", rec)}
+                }
+        }()
+
+        lotteries, err := provider.FetchLotteries(ctx)
+        return providerResult{lotteries: lotteries, err: err}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "os"
+        "path/filepath"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// fakeProvider - источник лотерей для тестов без HTTP сервера
+type fakeProvider struct {
+        name      string
+        lotteries []domain.Lottery
+        err       error
+}
+
+func (p *fakeProvider) Name() string {
+        return p.name
+}
+
+func (p *fakeProvider) FetchLotteries(ctx context.Context) ([]domain.Lottery, error) {
+        return p.lotteries, p.err
+}
+
+// TestProviderRegistryMergesAndDeduplicates проверяет объединение источников и приоритет по порядку
+func TestProviderRegistryMergesAndDeduplicates(t *testing.T) {
+        registry := NewProviderRegistry(
+                &fakeProvider{name: "primary", lotteries: []domain.Lottery{
+                        {ID: "6x45", Name: "Из основного источника"},
+                }},
+                &fakeProvider{name: "broken", err: errors.New("upstream down")},
+                &fakeProvider{name: "secondary", lotteries: []domain.Lottery{
+                        {ID: "6x45", Name: "Дубликат"},
+                        {ID: "5x36", Name: "Только во втором источнике"},
+                }},
+        )
+
+        lotteries, err := registry.FetchAll(context.Background())
+        if err != nil {
+                t.Fatalf("Ошибка одного источника не должна ломать каталог: %v", err)
+        }
+
+        if len(lotteries) != 2 {
+                t.Fatalf("Ожидается 2 лотереи без дубликатов, получено %d", len(lotteries))
+        }
+
+        if lotteries[0].ID != "6x45" || lotteries[0].Name != "Из основного источника" {
+                t.Errorf("При совпадении ID должен побеждать первый источник, получено: %+v", lotteries[0])
+        }
+
+        if lotteries[1].ID != "5x36" {
+                t.Errorf("Ожидается лотерея 5x36 из второго источника, получено: %s", lotteries[1].ID)
+        }
+}
+
+// TestProviderRegistryAllFailed проверяет что ошибка возвращается только при отказе всех источников
+func TestProviderRegistryAllFailed(t *testing.T) {
+        registry := NewProviderRegistry(
+                &fakeProvider{name: "a", err: errors.New("a down")},
+                &fakeProvider{name: "b", err: errors.New("b down")},
+        )
+
+        if _, err := registry.FetchAll(context.Background()); err == nil {
+                t.Fatal("FetchAll должен вернуть ошибку, если не ответил ни один источник")
+        }
+
+        // Сервис при этом все равно отдает моковые данные
+        service := NewStolotoService(nil, WithProviders(&fakeProvider{name: "a", err: errors.New("a down")}))
+        lotteries, err := service.GetAllLotteries(context.Background())
+        if err != nil || len(lotteries) == 0 {
+                t.Fatalf("GetAllLotteries должна вернуть моковые данные, получено %d лотерей, ошибка %v", len(lotteries), err)
+        }
+}
+
+// TestStaticCatalogProvider проверяет чтение статического каталога из файла
+func TestStaticCatalogProvider(t *testing.T) {
+        path := filepath.Join(t.TempDir(), "catalog.json")
+        data := `[{"id": "bingo75", "name": "Бинго-75", "type": "тиражная", "ticketPrice": 150, "isActive": true}]`
+        if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+                t.Fatalf("Не удалось записать каталог: %v", err)
+        }
+
+        service := NewStolotoService(nil, WithProviders(NewStaticCatalogProvider(path)))
+        lottery, err := service.GetLotteryByID(context.Background(), "bingo75")
+        if err != nil {
+                t.Fatalf("Лотерея из статического каталога не найдена: %v", err)
+        }
+
+        if lottery.TicketPrice != 150 {
+                t.Errorf("Цена билета: ожидается 150, получено %.0f", lottery.TicketPrice)
+        }
+
+        missing := NewStaticCatalogProvider(filepath.Join(t.TempDir(), "missing.json"))
+        if _, err := missing.FetchLotteries(context.Background()); err == nil {
+                t.Error("Отсутствующий файл каталога должен приводить к ошибке")
+        }
+}
@@ -62,8 +62,12 @@ func (s *RecommendationService) GenerateRecommendations(
         }
 
         // Сортируем по оценке (от большей к меньшей)
-        sort.Slice(scored, func(i, j int) bool {
-                return scored[i].matchScore > scored[j].matchScore
+        // При равной оценке и PreferValue выше лотерея с большим возвратом с рубля
+        sort.SliceStable(scored, func(i, j int) bool {
+                if scored[i].matchScore != scored[j].matchScore || !preferences.PreferValue {
+                        return scored[i].matchScore > scored[j].matchScore
+                }
+                return netReturn(scored[i].lottery) > netReturn(scored[j].lottery)
         })
 
         // Фильтруем рекомендации (минимум 50% совпадения)
@@ -183,6 +187,15 @@ func (s *RecommendationService) calculateMatchScore(
                 }
         }
 
+        // Ожидаемый возврат с рубля (вес: 20, только если пользователь просил учитывать ценность билета)
+        // Полный балл - возврат не меньше доли призового фонда; лотереи без расчета EV баллов не получают
+        if preferences.PreferValue {
+                maxScore += 20
+                if lottery.ExpectedValue != nil {
+                        score += 20 * math.Min(1, math.Max(0, lottery.ExpectedValue.NetReturn/domain.PrizeFundRate))
+                }
+        }
+
         // Защита от деления на 0 и от некорректных значений
         if maxScore == 0 {
                 return 0
@@ -276,6 +289,22 @@ func (s *RecommendationService) generatePersonalizedReason(
                 }
         }
 
+        // Ожидаемый возврат
+        if preferences.PreferValue && lottery.ExpectedValue != nil {
+                reasons = append(reasons, fmt.Sprintf(
+                        "С каждого рубля в среднем возвращается %.0f коп. после налога",
+                        lottery.ExpectedValue.NetReturn*100,
+                ))
+        }
+
+        // Остаток билетов моментальной лотереи
+        if lottery.TicketsRemaining != nil && *lottery.TicketsRemaining > 0 {
+                reasons = append(reasons, fmt.Sprintf(
+                        "В продаже осталось %.0f%% билетов тиража",
+                        *lottery.TicketsRemaining*100,
+                ))
+        }
+
         // Формируем итоговое сообщение в зависимости от оценки
         reasonsText := strings.Join(reasons, ". ")
         if len(reasons) > 0 {
@@ -334,9 +363,22 @@ func (s *RecommendationService) getMatchedCriteria(
                 criteria = append(criteria, "Частота розыгрышей")
         }
 
+        // Ценность билета: возврат с рубля не ниже доли призового фонда
+        if preferences.PreferValue && netReturn(lottery) >= domain.PrizeFundRate {
+                criteria = append(criteria, "Ценность билета")
+        }
+
         return criteria
 }
 
+// netReturn возвращает ожидаемый возврат с рубля после налога (0, если EV неизвестен)
+func netReturn(lottery domain.Lottery) float64 {
+        if lottery.ExpectedValue == nil {
+                return 0
+        }
+        return lottery.ExpectedValue.NetReturn
+}
+
 // contains проверяет, содержит ли массив строк заданную строку
 func contains(slice []string, item string) bool {
         for _, s := range slice {
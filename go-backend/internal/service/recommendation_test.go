@@ -2,6 +2,7 @@ package service
 
 import (
         "context"
+        "strings"
         "testing"
 
         "github.com/stoloto-recommendations/backend/internal/domain"
@@ -523,3 +524,54 @@ func TestNewRecommendationFlag(t *testing.T) {
                 t.Error("IsNew должен быть true для новой лотереи")
         }
 }
+
+// TestPreferValue проверяет учет ожидаемого возврата с рубля
+func TestPreferValue(t *testing.T) {
+        service := NewRecommendationService()
+        ctx := context.Background()
+
+        base := domain.Lottery{
+                Type:           domain.LotteryTypeNumbered,
+                TicketPrice:    100.0,
+                CurrentJackpot: 1000000.0,
+                WinProbability: 0.01,
+                DrawFrequency:  domain.DrawFrequencyDaily,
+                IsActive:       true,
+        }
+        poor, good, unknown := base, base, base
+        poor.ID, poor.ExpectedValue = "poor", &domain.ExpectedValue{Net: 20, NetReturn: 0.2}
+        good.ID, good.ExpectedValue = "good", &domain.ExpectedValue{Net: 45, NetReturn: 0.45}
+        unknown.ID = "unknown"
+
+        preferences := domain.UserPreferences{
+                TicketPrice:    domain.PriceRange{Min: 50.0, Max: 150.0},
+                PlayFrequency:  domain.DrawFrequencyDaily,
+                MaxJackpot:     domain.JackpotRange{Min: 500000.0, Max: 1500000.0},
+                WinProbability: domain.ProbabilityRange{Min: 0.005, Max: 0.015},
+        }
+
+        // Без PreferValue EV не влияет на оценку
+        if service.calculateMatchScore(poor, preferences) != service.calculateMatchScore(good, preferences) {
+                t.Error("Без PreferValue оценка не должна зависеть от EV")
+        }
+
+        preferences.PreferValue = true
+        goodScore := service.calculateMatchScore(good, preferences)
+        poorScore := service.calculateMatchScore(poor, preferences)
+        unknownScore := service.calculateMatchScore(unknown, preferences)
+        if !(goodScore > poorScore && poorScore > unknownScore) {
+                t.Errorf("Ожидается good > poor > unknown, получено %d, %d, %d", goodScore, poorScore, unknownScore)
+        }
+
+        response, err := service.GenerateRecommendations(ctx, domain.RecommendationRequest{Preferences: preferences},
+                []domain.Lottery{unknown, poor, good})
+        if err != nil {
+                t.Fatalf("GenerateRecommendations returned error: %v", err)
+        }
+        if len(response.Recommendations) == 0 || response.Recommendations[0].Lottery.ID != "good" {
+                t.Fatalf("Первой должна быть лотерея с лучшим возвратом: %+v", response.Recommendations)
+        }
+        if !strings.Contains(response.Recommendations[0].PersonalizedReason, "45 коп.") {
+                t.Errorf("Причина должна упоминать возврат с рубля: %s", response.Recommendations[0].PersonalizedReason)
+        }
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+        "context"
+        "fmt"
+        "math"
+        "math/rand"
+        "runtime"
+        "sort"
+        "sync"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+const (
+        // DefaultSimulationTrials - количество испытаний по умолчанию
+        DefaultSimulationTrials = 10000
+
+        simulationChunk     = 500 // Испытаний в одной порции воркера
+        normalApproximation = 100 // Дисперсия n·p·(1-p), с которой число выигрышей моделируется нормальным распределением
+)
+
+// simulationPercentiles - процентили результата в ответе
+var simulationPercentiles = []int{1, 5, 10, 25, 50, 75, 90, 95, 99}
+
+// SimulationService моделирует результаты плана игры методом Монте-Карло
+// Испытания считаются порциями; одновременно считается не больше workers порций по всем запросам
+type SimulationService struct {
+        lotteries *StolotoService
+        workers   chan struct{} // Семафор воркеров
+}
+
+// NewSimulationService создает новый экземпляр SimulationService (workers <= 0 - по числу CPU)
+func NewSimulationService(lotteries *StolotoService, workers int) *SimulationService {
+        if workers <= 0 {
+                workers = runtime.NumCPU()
+        }
+        return &SimulationService{
+                lotteries: lotteries,
+                workers:   make(chan struct{}, workers),
+        }
+}
+
+// simulatedPlan - план игры в лотерее, подготовленный для моделирования
+type simulatedPlan struct {
+        tickets int
+        tiers   []simulatedTier // Категории с известными вероятностью и призом
+}
+
+// simulatedTier - категория приза плана
+type simulatedTier struct {
+        index       int // Индекс категории в ответе
+        probability float64
+        prize       float64
+}
+
+// Simulate моделирует результат плана игры: в каждом испытании для каждого билета разыгрывается категория приза
+// по ее вероятности; приз категории - как в EV билета (суперприз целиком, доля фонда - средний приз), до НДФЛ.
+// С одним и тем же зерном результат повторяется. Отмена контекста прерывает моделирование.
+func (s *SimulationService) Simulate(ctx context.Context, request domain.SimulationRequest) (*domain.SimulationResult, error) {
+        trials := request.Trials
+        if trials <= 0 {
+                trials = DefaultSimulationTrials
+        }
+        seed := rand.Int63()
+        if request.Seed != nil {
+                seed = *request.Seed
+        }
+
+        result := &domain.SimulationResult{Trials: trials, Seed: seed, Tiers: []domain.SimulationTier{}}
+        plans := make([]simulatedPlan, 0, len(request.Plans))
+        for _, plan := range request.Plans {
+                lottery, err := s.lotteries.GetLotteryByID(ctx, plan.LotteryID)
+                if err != nil {
+                        return nil, err
+                }
+                if lottery.ExpectedValue == nil {
+                        return nil, fmt.Errorf("%w: %s", ErrOddsUnavailable, plan.LotteryID)
+                }
+
+                simulated := simulatedPlan{tickets: plan.TicketsPerDraw * plan.Draws}
+                for i, category := range lottery.PrizeStructure {
+                        if category.Odds == nil || category.Payout == nil {
+                                continue
+                        }
+                        probability := category.Odds.Float64()
+                        singleWinner := lottery.GameRules != nil && lottery.GameRules.SingleWinner(i)
+                        calculated := cumulativeTier(category.Category, probability, singleWinner, 0, plan.TicketsPerDraw, plan.Draws)
+                        simulated.tiers = append(simulated.tiers, simulatedTier{
+                                index:       len(result.Tiers),
+                                probability: probability,
+                                prize:       category.Payout.AveragePrize(lottery.TicketPrice, *category.Odds),
+                        })
+                        result.Tiers = append(result.Tiers, domain.SimulationTier{
+                                LotteryID:            plan.LotteryID,
+                                Category:             category.Category,
+                                Probability:          probability,
+                                Prize:                simulated.tiers[len(simulated.tiers)-1].prize,
+                                AtLeastOneCalculated: calculated.AtLeastOne,
+                                Exact:                calculated.Exact,
+                        })
+                }
+                plans = append(plans, simulated)
+
+                result.Tickets += simulated.tickets
+                result.Cost += float64(simulated.tickets) * lottery.TicketPrice
+                result.ExpectedNet += float64(simulated.tickets) * (lottery.ExpectedValue.Gross - lottery.TicketPrice)
+        }
+        result.ExpectedLoss = -result.ExpectedNet
+
+        nets := make([]float64, trials)
+        hits, err := s.run(ctx, plans, len(result.Tiers), result.Cost, seed, nets)
+        if err != nil {
+                return nil, err
+        }
+
+        for i := range result.Tiers {
+                result.Tiers[i].AtLeastOne = float64(hits[i]) / float64(trials)
+        }
+        summarizeNets(result, nets)
+        return result, nil
+}
+
+// run распределяет порции испытаний по воркерам и заполняет nets результатами испытаний
+// Возвращает, в скольких испытаниях выигран хотя бы один приз каждой категории
+func (s *SimulationService) run(ctx context.Context, plans []simulatedPlan, tiers int, cost float64, seed int64, nets []float64) ([]int, error) {
+        chunks := (len(nets) + simulationChunk - 1) / simulationChunk
+        workers := cap(s.workers)
+        if workers > chunks {
+                workers = chunks
+        }
+
+        hits := make([]int, tiers)
+        jobs := make(chan int)
+        var mu sync.Mutex
+        var wg sync.WaitGroup
+        for i := 0; i < workers; i++ {
+                wg.Add(1)
+                go func() {
+                        defer wg.Done()
+                        for chunk := range jobs {
+                                select {
+                                case s.workers <- struct{}{}:
+                                case <-ctx.Done():
+                                        continue
+                                }
+
+                                from := chunk * simulationChunk
+                                to := from + simulationChunk
+                                if to > len(nets) {
+                                        to = len(nets)
+                                }
+                                // Генератор порции зависит только от зерна и номера порции - результат не зависит от порядка воркеров
+                                rng := rand.New(rand.NewSource(seed + int64(chunk)*1_000_003))
+                                chunkHits := simulateTrials(ctx, rng, plans, tiers, cost, nets[from:to])
+                                <-s.workers
+
+                                mu.Lock()
+                                for i, count := range chunkHits {
+                                        hits[i] += count
+                                }
+                                mu.Unlock()
+                        }
+                }()
+        }
+
+feed:
+        for chunk := 0; chunk < chunks; chunk++ {
+                select {
+                case jobs <- chunk:
+                case <-ctx.Done():
+                        break feed
+                }
+        }
+        close(jobs)
+        wg.Wait()
+
+        if err := ctx.Err(); err != nil {
+                return nil, err
+        }
+        return hits, nil
+}
+
+// simulateTrials проводит испытания, записывая результат каждого в nets
+// Число выигрышей категорий плана разыгрывается мультиномиально: последовательными биномиальными выборками
+func simulateTrials(ctx context.Context, rng *rand.Rand, plans []simulatedPlan, tiers int, cost float64, nets []float64) []int {
+        hits := make([]int, tiers)
+        for trial := range nets {
+                if trial%64 == 0 && ctx.Err() != nil {
+                        return hits
+                }
+
+                net := -cost
+                for _, plan := range plans {
+                        remaining := plan.tickets
+                        rest := 1.0 // Вероятность не попасть в предыдущие категории
+                        for _, tier := range plan.tiers {
+                                if remaining == 0 || rest <= 0 {
+                                        break
+                                }
+                                count := binomialSample(rng, remaining, tier.probability/rest)
+                                remaining -= count
+                                rest -= tier.probability
+                                if count > 0 {
+                                        net += float64(count) * tier.prize
+                                        hits[tier.index]++
+                                }
+                        }
+                }
+                nets[trial] = net
+        }
+        return hits
+}
+
+// binomialSample возвращает случайное число успехов в n испытаниях с вероятностью p
+// Малые значения разыгрываются точно (обращением функции распределения), большие - нормальным приближением
+func binomialSample(rng *rand.Rand, n int, p float64) int {
+        switch {
+        case n <= 0 || p <= 0:
+                return 0
+        case p >= 1:
+                return n
+        case p > 0.5:
+                return n - binomialSample(rng, n, 1-p)
+        }
+
+        mean := float64(n) * p
+        if variance := mean * (1 - p); variance > normalApproximation {
+                count := int(math.Round(mean + math.Sqrt(variance)*rng.NormFloat64()))
+                if count < 0 {
+                        return 0
+                }
+                if count > n {
+                        return n
+                }
+                return count
+        }
+
+        ratio := p / (1 - p)
+        probability := math.Pow(1-p, float64(n)) // P(X = 0)
+        u := rng.Float64()
+        count := 0
+        for u > probability && count < n {
+                u -= probability
+                probability *= ratio * float64(n-count) / float64(count+1)
+                count++
+        }
+        return count
+}
+
+// summarizeNets считает среднее, крайние значения, процентили и долю испытаний в плюсе
+func summarizeNets(result *domain.SimulationResult, nets []float64) {
+        sort.Float64s(nets)
+        sum, ahead := 0.0, 0
+        for _, net := range nets {
+                sum += net
+                if net > 0 {
+                        ahead++
+                }
+        }
+        result.MeanNet = sum / float64(len(nets))
+        result.MinNet = nets[0]
+        result.MaxNet = nets[len(nets)-1]
+        result.ProbabilityAhead = float64(ahead) / float64(len(nets))
+
+        result.Percentiles = make([]domain.SimulationPercentile, 0, len(simulationPercentiles))
+        for _, percentile := range simulationPercentiles {
+                // Метод ближайшего ранга
+                rank := int(math.Ceil(float64(percentile)/100*float64(len(nets)))) - 1
+                if rank < 0 {
+                        rank = 0
+                }
+                result.Percentiles = append(result.Percentiles, domain.SimulationPercentile{Percentile: percentile, Net: nets[rank]})
+        }
+}
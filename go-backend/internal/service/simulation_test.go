@@ -0,0 +1,121 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "math"
+        "math/rand"
+        "reflect"
+        "strings"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestSimulate проверяет воспроизводимость по зерну и согласие испытаний с расчетными вероятностями
+func TestSimulate(t *testing.T) {
+        simulation := NewSimulationService(newTestLotteries(t), 2)
+        ctx := context.Background()
+
+        seed := int64(42)
+        request := domain.SimulationRequest{
+                Plans:  []domain.SimulationPlan{{LotteryID: "6x45", TicketsPerDraw: 10, Draws: 100}},
+                Trials: 2000,
+                Seed:   &seed,
+        }
+        result, err := simulation.Simulate(ctx, request)
+        if err != nil {
+                t.Fatalf("Simulate: %v", err)
+        }
+        again, err := simulation.Simulate(ctx, request)
+        if err != nil {
+                t.Fatalf("Simulate: %v", err)
+        }
+        if !reflect.DeepEqual(result, again) {
+                t.Error("С одним зерном ожидается одинаковый результат")
+        }
+
+        if result.Trials != 2000 || result.Seed != 42 || result.Tickets != 1000 || result.Cost != 100000 {
+                t.Errorf("Ожидается 1000 билетов по 100 ₽, получено %+v", result)
+        }
+        if result.ExpectedNet >= 0 || result.ExpectedLoss != -result.ExpectedNet {
+                t.Errorf("Ожидается отрицательный EV плана, получено %v (проигрыш %v)", result.ExpectedNet, result.ExpectedLoss)
+        }
+        if len(result.Percentiles) != len(simulationPercentiles) || result.MinNet > result.Percentiles[0].Net || result.MaxNet < result.Percentiles[len(result.Percentiles)-1].Net {
+                t.Errorf("Процентили должны лежать между худшим и лучшим результатом: %+v", result)
+        }
+        for i := 1; i < len(result.Percentiles); i++ {
+                if result.Percentiles[i].Net < result.Percentiles[i-1].Net {
+                        t.Errorf("Процентили должны не убывать: %+v", result.Percentiles)
+                }
+        }
+
+        // Категория "3 из 6" (приз 100 ₽) выпадает почти в каждом испытании - доля испытаний близка к расчетной вероятности
+        for _, tier := range result.Tiers {
+                if tier.LotteryID != "6x45" || tier.Probability <= 0 {
+                        t.Errorf("Некорректная категория %+v", tier)
+                }
+                if math.Abs(tier.AtLeastOne-tier.AtLeastOneCalculated) > 0.05 {
+                        t.Errorf("Категория %s: доля испытаний %v далека от расчетной вероятности %v", tier.Category, tier.AtLeastOne, tier.AtLeastOneCalculated)
+                }
+        }
+
+        // Суперприз за тираж выигрывает одна комбинация - расчет точный, для "3 из 6" с 10 билетами в тираже - оценка
+        jackpot, lower := result.Tiers[0], result.Tiers[len(result.Tiers)-1]
+        if p := 1.0 / 8145060; !jackpot.Exact || math.Abs(jackpot.AtLeastOneCalculated-(1-math.Pow(1-10*p, 100)))/jackpot.AtLeastOneCalculated > 1e-9 {
+                t.Errorf("Суперприз: ожидается точная вероятность 1 - (1 - 10p)^100, получено %+v", jackpot)
+        }
+        if lower.Exact {
+                t.Errorf("Категория %s: ожидается оценка, а не точный расчет", lower.Category)
+        }
+
+        other := int64(7)
+        request.Seed = &other
+        if changed, err := simulation.Simulate(ctx, request); err != nil || reflect.DeepEqual(changed.Percentiles, result.Percentiles) && changed.MeanNet == result.MeanNet {
+                t.Errorf("С другим зерном ожидается другой результат, ошибка %v", err)
+        }
+}
+
+// TestSimulateErrors проверяет ошибки лотереи без вероятностей и отмену моделирования
+func TestSimulateErrors(t *testing.T) {
+        simulation := NewSimulationService(newTestLotteries(t), 1)
+
+        plans := []domain.SimulationPlan{{LotteryID: "ruslotto", TicketsPerDraw: 1, Draws: 1}}
+        if _, err := simulation.Simulate(context.Background(), domain.SimulationRequest{Plans: plans}); !errors.Is(err, ErrOddsUnavailable) {
+                t.Errorf("Лотерея без вероятностей: ожидается ErrOddsUnavailable, получено %v", err)
+        }
+        plans = []domain.SimulationPlan{{LotteryID: "6x45", TicketsPerDraw: 1, Draws: 1}, {LotteryID: "unknown", TicketsPerDraw: 1, Draws: 1}}
+        _, err := simulation.Simulate(context.Background(), domain.SimulationRequest{Plans: plans})
+        if !errors.Is(err, ErrLotteryNotFound) || !strings.Contains(err.Error(), "unknown") {
+                t.Errorf("Неизвестная лотерея: ожидается ErrLotteryNotFound с ID лотереи плана, получено %v", err)
+        }
+
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+        plans = []domain.SimulationPlan{{LotteryID: "6x45", TicketsPerDraw: 1, Draws: 10}}
+        if _, err := simulation.Simulate(ctx, domain.SimulationRequest{Plans: plans}); !errors.Is(err, context.Canceled) {
+                t.Errorf("Отмененный запрос: ожидается context.Canceled, получено %v", err)
+        }
+}
+
+// TestBinomialSample проверяет среднее выборки в точной и приближенной ветках
+func TestBinomialSample(t *testing.T) {
+        rng := rand.New(rand.NewSource(1))
+        for _, c := range []struct {
+                n int
+                p float64
+        }{{10, 0.05}, {1000, 0.3}, {200, 0.9}} {
+                sum := 0
+                for i := 0; i < 5000; i++ {
+                        count := binomialSample(rng, c.n, c.p)
+                        if count < 0 || count > c.n {
+                                t.Fatalf("B(%d, %v): значение %d вне диапазона", c.n, c.p, count)
+                        }
+                        sum += count
+                }
+                mean, expected := float64(sum)/5000, float64(c.n)*c.p
+                if math.Abs(mean-expected) > 0.05*expected+0.1 {
+                        t.Errorf("B(%d, %v): среднее %v, ожидается %v", c.n, c.p, mean, expected)
+                }
+        }
+}
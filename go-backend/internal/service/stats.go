@@ -0,0 +1,338 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "sort"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// ErrNoDrawHistory возвращается, если в архиве еще нет тиражей лотереи
+var ErrNoDrawHistory = errors.New("история тиражей еще не собрана")
+
+// StatsOptions задает параметры статистики
+type StatsOptions struct {
+        Windows []int // Окна для горячих/холодных чисел (последние N тиражей)
+        Top     int   // Сколько чисел, пар и троек возвращать
+}
+
+// DefaultStatsOptions возвращает параметры статистики по умолчанию
+func DefaultStatsOptions() StatsOptions {
+        return StatsOptions{
+                Windows: []int{20, 100},
+                Top:     10,
+        }
+}
+
+// StatsService считает статистику выпадения чисел по архиву тиражей
+type StatsService struct {
+        lotteries *StolotoService
+        archive   repository.DrawArchive // nil - статистика недоступна
+}
+
+// NewStatsService создает новый экземпляр StatsService
+func NewStatsService(lotteries *StolotoService, archive repository.DrawArchive) *StatsService {
+        return &StatsService{
+                lotteries: lotteries,
+                archive:   archive,
+        }
+}
+
+// GetNumberStats возвращает статистику чисел лотереи по всем тиражам архива
+func (s *StatsService) GetNumberStats(ctx context.Context, lotteryID string, options StatsOptions) (*domain.NumberStats, error) {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if !hasDraws(*lottery) {
+                return nil, fmt.Errorf("%w: у моментальной лотереи %s нет тиражей", ErrDrawNotFound, lotteryID)
+        }
+        if s.archive == nil {
+                return nil, fmt.Errorf("%w: архив тиражей отключен", ErrNoDrawHistory)
+        }
+
+        draws, _, err := s.archive.Query(ctx, repository.DrawQuery{GameName: lotteryID})
+        if err != nil {
+                return nil, fmt.Errorf("ошибка чтения архива тиражей: %w", err)
+        }
+        if len(draws) == 0 {
+                return nil, fmt.Errorf("%w: %s", ErrNoDrawHistory, lotteryID)
+        }
+
+        stats := ComputeNumberStats(*lottery, draws, options)
+        return &stats, nil
+}
+
+// ComputeNumberStats считает статистику чисел по тиражам (от новых к старым) отдельно для каждого игрового поля
+// Выигрышная комбинация раскладывается по полям правил игры (SplitDrawn), диапазон поля - от 1 до Of;
+// тиражи с комбинацией не по правилам не учитываются. Без правил игры все числа тиража считаются одним полем,
+// а его диапазон - до наибольшего выпавшего числа.
+func ComputeNumberStats(lottery domain.Lottery, draws []repository.Draw, options StatsOptions) domain.NumberStats {
+        defaults := DefaultStatsOptions()
+        if len(options.Windows) == 0 {
+                options.Windows = defaults.Windows
+        }
+        if options.Top <= 0 {
+                options.Top = defaults.Top
+        }
+
+        // Комбинации полей без повторов и по возрастанию; тиражи без чисел не учитываются
+        maxNumbers := []int{0} // Наибольшее число каждого поля
+        if lottery.GameRules != nil {
+                maxNumbers = make([]int, len(lottery.GameRules.Fields))
+                for i, field := range lottery.GameRules.Fields {
+                        maxNumbers[i] = field.Of
+                }
+        }
+        combinations := make([][][]int, len(maxNumbers))
+        numbers := make([]int, 0, len(draws))
+        for _, draw := range draws {
+                drawn, ok := splitStatsDraw(lottery.GameRules, draw.WinningNumbers)
+                if !ok {
+                        continue
+                }
+                for i := range drawn {
+                        combinations[i] = append(combinations[i], normalizeCombination(drawn[i]))
+                }
+                numbers = append(numbers, draw.Number)
+                if lottery.GameRules == nil {
+                        if last := drawn[0][len(drawn[0])-1]; last > maxNumbers[0] {
+                                maxNumbers[0] = last
+                        }
+                }
+        }
+
+        stats := domain.NumberStats{
+                LotteryID:  lottery.ID,
+                TotalDraws: len(numbers),
+                Fields:     []domain.FieldStats{},
+        }
+        if len(numbers) == 0 {
+                return stats
+        }
+        stats.LastDraw = numbers[0]
+        stats.FirstDraw = numbers[len(numbers)-1]
+        for i := range combinations {
+                stats.Fields = append(stats.Fields, computeFieldStats(i+1, combinations[i], numbers, maxNumbers[i], options))
+        }
+        return stats
+}
+
+// splitStatsDraw раскладывает выигрышную комбинацию по полям (без правил игры - одно поле)
+// Возвращает false, если тираж не нужно учитывать
+func splitStatsDraw(rules *domain.GameRules, winningNumbers []int) ([][]int, bool) {
+        if rules == nil {
+                combination := normalizeCombination(winningNumbers)
+                return [][]int{combination}, len(combination) > 0
+        }
+        drawn, err := rules.SplitDrawn(winningNumbers)
+        if err != nil || !drawnInRange(*rules, drawn) {
+                return nil, false
+        }
+        return drawn, true
+}
+
+// computeFieldStats считает статистику чисел поля от 1 до maxNumber
+func computeFieldStats(field int, combinations [][]int, numbers []int, maxNumber int, options StatsOptions) domain.FieldStats {
+        stats := domain.FieldStats{Field: field, MaxNumber: maxNumber}
+        for _, window := range options.Windows {
+                size := window
+                if size <= 0 || size > len(combinations) {
+                        size = len(combinations)
+                }
+                frequencies := countFrequencies(combinations[:size], maxNumber)
+                stats.Windows = append(stats.Windows, domain.HotColdWindow{
+                        Window: window,
+                        Draws:  size,
+                        Hot:    topFrequencies(frequencies, options.Top, true),
+                        Cold:   topFrequencies(frequencies, options.Top, false),
+                })
+        }
+
+        stats.Frequencies = countFrequencies(combinations, maxNumber)
+        stats.Gaps = currentGaps(combinations, numbers, maxNumber)
+        stats.Pairs = topCombinations(combinations, 2, options.Top)
+        stats.Triplets = topCombinations(combinations, 3, options.Top)
+        stats.OddEven = distribution(combinations, func(n int) bool { return n%2 == 1 })
+        stats.LowHigh = distribution(combinations, func(n int) bool { return n <= maxNumber/2 })
+        return stats
+}
+
+// normalizeCombination сортирует числа и убирает повторы
+func normalizeCombination(winningNumbers []int) []int {
+        combination := make([]int, 0, len(winningNumbers))
+        seen := make(map[int]bool, len(winningNumbers))
+        for _, n := range winningNumbers {
+                if n <= 0 || seen[n] {
+                        continue
+                }
+                seen[n] = true
+                combination = append(combination, n)
+        }
+        sort.Ints(combination)
+        return combination
+}
+
+// countFrequencies считает, сколько раз выпало каждое число от 1 до maxNumber
+func countFrequencies(combinations [][]int, maxNumber int) []domain.NumberFrequency {
+        counts := make([]int, maxNumber+1)
+        for _, combination := range combinations {
+                for _, n := range combination {
+                        counts[n]++
+                }
+        }
+
+        frequencies := make([]domain.NumberFrequency, 0, maxNumber)
+        for n := 1; n <= maxNumber; n++ {
+                frequencies = append(frequencies, domain.NumberFrequency{
+                        Number:    n,
+                        Count:     counts[n],
+                        Frequency: float64(counts[n]) / float64(len(combinations)),
+                })
+        }
+        return frequencies
+}
+
+// topFrequencies возвращает top самых частых (hot) или самых редких чисел
+// При равенстве частот меньшее число идет первым
+func topFrequencies(frequencies []domain.NumberFrequency, top int, hot bool) []domain.NumberFrequency {
+        sorted := make([]domain.NumberFrequency, len(frequencies))
+        copy(sorted, frequencies)
+        sort.SliceStable(sorted, func(i, j int) bool {
+                if sorted[i].Count != sorted[j].Count {
+                        if hot {
+                                return sorted[i].Count > sorted[j].Count
+                        }
+                        return sorted[i].Count < sorted[j].Count
+                }
+                return sorted[i].Number < sorted[j].Number
+        })
+        if len(sorted) > top {
+                sorted = sorted[:top]
+        }
+        return sorted
+}
+
+// currentGaps считает, сколько тиражей прошло с последнего выпадения каждого числа
+// Числа, ни разу не выпадавшие в архиве, получают паузу, равную числу тиражей
+func currentGaps(combinations [][]int, numbers []int, maxNumber int) []domain.NumberGap {
+        gaps := make([]domain.NumberGap, maxNumber)
+        found := make([]bool, maxNumber+1)
+        for n := 1; n <= maxNumber; n++ {
+                gaps[n-1] = domain.NumberGap{Number: n, Gap: len(combinations)}
+        }
+
+        for i, combination := range combinations {
+                for _, n := range combination {
+                        if found[n] {
+                                continue
+                        }
+                        found[n] = true
+                        gaps[n-1].Gap = i
+                        gaps[n-1].LastDraw = numbers[i]
+                }
+        }
+
+        sort.SliceStable(gaps, func(i, j int) bool {
+                if gaps[i].Gap != gaps[j].Gap {
+                        return gaps[i].Gap > gaps[j].Gap
+                }
+                return gaps[i].Number < gaps[j].Number
+        })
+        return gaps
+}
+
+// topCombinations возвращает top самых частых сочетаний из size чисел (пар или троек)
+func topCombinations(combinations [][]int, size int, top int) []domain.NumberCombination {
+        counts := make(map[[3]int]int)
+        for _, combination := range combinations {
+                forEachSubset(combination, size, func(key [3]int) {
+                        counts[key]++
+                })
+        }
+
+        result := make([]domain.NumberCombination, 0, len(counts))
+        for key, count := range counts {
+                result = append(result, domain.NumberCombination{
+                        Numbers: append([]int(nil), key[:size]...),
+                        Count:   count,
+                })
+        }
+        sort.Slice(result, func(i, j int) bool {
+                if result[i].Count != result[j].Count {
+                        return result[i].Count > result[j].Count
+                }
+                return lessNumbers(result[i].Numbers, result[j].Numbers)
+        })
+        if len(result) > top {
+                result = result[:top]
+        }
+        return result
+}
+
+// forEachSubset перебирает сочетания из size (2 или 3) чисел отсортированной комбинации
+func forEachSubset(combination []int, size int, visit func(key [3]int)) {
+        for i := 0; i < len(combination); i++ {
+                for j := i + 1; j < len(combination); j++ {
+                        if size == 2 {
+                                visit([3]int{combination[i], combination[j]})
+                                continue
+                        }
+                        for k := j + 1; k < len(combination); k++ {
+                                visit([3]int{combination[i], combination[j], combination[k]})
+                        }
+                }
+        }
+}
+
+// lessNumbers сравнивает наборы чисел лексикографически
+func lessNumbers(a, b []int) bool {
+        for i := 0; i < len(a) && i < len(b); i++ {
+                if a[i] != b[i] {
+                        return a[i] < b[i]
+                }
+        }
+        return len(a) < len(b)
+}
+
+// distribution считает распределение тиражей по числу элементов, удовлетворяющих условию
+// Метка "k/m": k чисел удовлетворяют условию, m - нет
+func distribution(combinations [][]int, matches func(n int) bool) []domain.DistributionBucket {
+        type split struct{ yes, no int }
+        counts := make(map[split]int)
+        for _, combination := range combinations {
+                var s split
+                for _, n := range combination {
+                        if matches(n) {
+                                s.yes++
+                        } else {
+                                s.no++
+                        }
+                }
+                counts[s]++
+        }
+
+        splits := make([]split, 0, len(counts))
+        for s := range counts {
+                splits = append(splits, s)
+        }
+        sort.Slice(splits, func(i, j int) bool {
+                if splits[i].yes != splits[j].yes {
+                        return splits[i].yes > splits[j].yes
+                }
+                return splits[i].no < splits[j].no
+        })
+
+        buckets := make([]domain.DistributionBucket, 0, len(splits))
+        for _, s := range splits {
+                buckets = append(buckets, domain.DistributionBucket{
+                        Label: fmt.Sprintf("%d/%d", s.yes, s.no),
+                        Count: counts[s],
+                        Share: float64(counts[s]) / float64(len(combinations)),
+                })
+        }
+        return buckets
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "reflect"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// statsDraws - тиражи игры "4 из 8" от новых к старым
+var statsDraws = []repository.Draw{
+        {Number: 5, WinningNumbers: []int{1, 2, 3, 4}},
+        {Number: 4, WinningNumbers: []int{1, 2, 5, 6}},
+        {Number: 3, WinningNumbers: []int{2, 1, 7, 7, 8}}, // Повтор 7 не учитывается
+        {Number: 2}, // Тираж без чисел пропускается
+        {Number: 1, WinningNumbers: []int{1, 3, 5, 7}},
+}
+
+// TestComputeNumberStats проверяет частоты, паузы, пары и распределения
+func TestComputeNumberStats(t *testing.T) {
+        result := ComputeNumberStats(domain.Lottery{ID: "4x8"}, statsDraws, StatsOptions{Windows: []int{2, 100}, Top: 3})
+
+        // Без правил игры все числа тиража - одно поле до наибольшего выпавшего числа
+        if result.TotalDraws != 4 || result.FirstDraw != 1 || result.LastDraw != 5 || len(result.Fields) != 1 || result.Fields[0].MaxNumber != 8 {
+                t.Fatalf("Неверная сводка: %+v", result)
+        }
+        stats := result.Fields[0]
+
+        // Окно из 2 тиражей: 1 и 2 выпали дважды; окно больше архива ограничивается архивом
+        if stats.Windows[0].Draws != 2 || stats.Windows[0].Hot[0].Number != 1 || stats.Windows[0].Hot[1].Number != 2 {
+                t.Errorf("Горячие числа окна 2: %+v", stats.Windows[0].Hot)
+        }
+        if stats.Windows[1].Window != 100 || stats.Windows[1].Draws != 4 {
+                t.Errorf("Окно 100 должно содержать 4 тиража: %+v", stats.Windows[1])
+        }
+        if cold := stats.Windows[1].Cold; cold[0].Number != 4 || cold[0].Count != 1 {
+                t.Errorf("Самое холодное число - 4 (1 раз), получено %+v", cold)
+        }
+        if f := stats.Frequencies[0]; f.Number != 1 || f.Count != 4 || f.Frequency != 1 {
+                t.Errorf("Число 1 выпало во всех тиражах: %+v", f)
+        }
+
+        // Паузы: 8 последний раз в тираже 3 (2 тиража назад), 4 - в последнем
+        gaps := make(map[int]domain.NumberGap)
+        for _, gap := range stats.Gaps {
+                gaps[gap.Number] = gap
+        }
+        if gaps[8].Gap != 2 || gaps[8].LastDraw != 3 || gaps[4].Gap != 0 {
+                t.Errorf("Неверные паузы: 8 -> %+v, 4 -> %+v", gaps[8], gaps[4])
+        }
+        if stats.Gaps[0].Number != 7 || stats.Gaps[0].Gap != 2 {
+                t.Errorf("Первой должна идти самая длинная пауза (7 и 8 по 2 тиража, меньшее число первым): %+v", stats.Gaps[0])
+        }
+
+        if !reflect.DeepEqual(stats.Pairs[0], domain.NumberCombination{Numbers: []int{1, 2}, Count: 3}) {
+                t.Errorf("Самая частая пара - 1,2 (3 раза), получено %+v", stats.Pairs[0])
+        }
+        if !reflect.DeepEqual(stats.Triplets[0].Numbers, []int{1, 2, 3}) || stats.Triplets[0].Count != 1 {
+                t.Errorf("Тройки с равной частотой упорядочены по числам, получено %+v", stats.Triplets[0])
+        }
+
+        // Нечетные/четные: {1,2,3,4} 2/2, {1,2,5,6} 2/2, {1,2,7,8} 2/2, {1,3,5,7} 4/0
+        expectedOddEven := []domain.DistributionBucket{
+                {Label: "4/0", Count: 1, Share: 0.25},
+                {Label: "2/2", Count: 3, Share: 0.75},
+        }
+        if !reflect.DeepEqual(stats.OddEven, expectedOddEven) {
+                t.Errorf("Нечетные/четные: ожидается %+v, получено %+v", expectedOddEven, stats.OddEven)
+        }
+        if len(stats.LowHigh) == 0 {
+                t.Error("Распределение малые/большие не посчитано")
+        }
+}
+
+// TestComputeNumberStatsFields проверяет статистику по полям игры с дополнительным полем (5 из 36 + 1 из 4)
+func TestComputeNumberStatsFields(t *testing.T) {
+        lottery := domain.Lottery{ID: "5x36plus"}
+        repository.ApplyGameRules(&lottery)
+        draws := []repository.Draw{
+                {Number: 3, WinningNumbers: []int{1, 2, 3, 4, 36, 2}},
+                {Number: 2, WinningNumbers: []int{1, 2, 3, 4, 5}}, // Нет числа второго поля - тираж пропускается
+                {Number: 1, WinningNumbers: []int{2, 10, 20, 30, 35, 2}},
+        }
+        result := ComputeNumberStats(lottery, draws, StatsOptions{Top: 3})
+
+        if result.TotalDraws != 2 || len(result.Fields) != 2 {
+                t.Fatalf("Ожидается 2 тиража и 2 поля, получено %+v", result)
+        }
+        main, extra := result.Fields[0], result.Fields[1]
+        if main.Field != 1 || main.MaxNumber != 36 || len(main.Frequencies) != 36 {
+                t.Errorf("Первое поле: ожидаются числа от 1 до 36, получено %d чисел до %d", len(main.Frequencies), main.MaxNumber)
+        }
+        if extra.Field != 2 || extra.MaxNumber != 4 || len(extra.Frequencies) != 4 || extra.Frequencies[1].Count != 2 {
+                t.Errorf("Второе поле: ожидаются числа от 1 до 4, число 2 выпало дважды, получено %+v", extra.Frequencies)
+        }
+
+        // Число 2 второго поля не смешивается с числом 2 первого поля
+        if main.Frequencies[1].Count != 2 || main.Frequencies[35].Count != 1 {
+                t.Errorf("Первое поле: 2 выпало дважды, 36 - один раз, получено %+v", main.Frequencies)
+        }
+        if len(extra.Pairs) != 0 || !reflect.DeepEqual(main.Pairs[0], domain.NumberCombination{Numbers: []int{1, 2}, Count: 1}) {
+                t.Errorf("Пары считаются внутри поля: первое %+v, второе %+v", main.Pairs, extra.Pairs)
+        }
+        if !reflect.DeepEqual(main.LowHigh, []domain.DistributionBucket{{Label: "4/1", Count: 1, Share: 0.5}, {Label: "2/3", Count: 1, Share: 0.5}}) {
+                t.Errorf("Малые/большие первого поля считаются до 18: %+v", main.LowHigh)
+        }
+}
+
+// TestStatsServiceErrors проверяет ошибки при отсутствии истории
+func TestStatsServiceErrors(t *testing.T) {
+        ctx := context.Background()
+        _, archive := newTestDrawService(t, &fakeDrawFetcher{latest: 10}, true)
+        lotteries := NewStolotoService(nil, WithProviders(&fakeProvider{name: "catalog", lotteries: []domain.Lottery{
+                {ID: "6x45", Type: domain.LotteryTypeNumbered, IsActive: true},
+        }}))
+        service := NewStatsService(lotteries, archive)
+
+        if _, err := service.GetNumberStats(ctx, "6x45", DefaultStatsOptions()); !errors.Is(err, ErrNoDrawHistory) {
+                t.Errorf("Ожидается ErrNoDrawHistory для пустого архива, получено %v", err)
+        }
+        if _, err := service.GetNumberStats(ctx, "unknown", DefaultStatsOptions()); !errors.Is(err, ErrLotteryNotFound) {
+                t.Errorf("Ожидается ErrLotteryNotFound, получено %v", err)
+        }
+
+        if err := archive.Save(ctx, repository.Draw{Number: 1, GameName: "6x45", WinningNumbers: []int{1, 2, 3, 4, 5, 6}}); err != nil {
+                t.Fatalf("Не удалось заполнить архив: %v", err)
+        }
+        stats, err := service.GetNumberStats(ctx, "6x45", DefaultStatsOptions())
+        if err != nil || stats.TotalDraws != 1 {
+                t.Errorf("Ожидается статистика по 1 тиражу, получено %+v, ошибка %v", stats, err)
+        }
+}
@@ -2,100 +2,216 @@ package service
 
 import (
         "context"
+        "errors"
         "fmt"
         "log"
+        "os"
+        "sync"
+        "time"
 
         "github.com/stoloto-recommendations/backend/internal/domain"
         "github.com/stoloto-recommendations/backend/internal/repository"
 )
 
+// ErrLotteryNotFound возвращается, если лотереи с указанным ID нет в каталоге
+var ErrLotteryNotFound = errors.New("лотерея не найдена")
+
+// NextDrawsCount - сколько ближайших тиражей передается в описании лотереи
+const NextDrawsCount = 5
+
 // StolotoService предоставляет бизнес-логику для работы с лотереями Stoloto
 type StolotoService struct {
-        client *repository.StolotoClient
+        client     *repository.StolotoClient
+        registry   *ProviderRegistry
+        games      *repository.GameCatalog // Каталог игр для резервных лотерей
+        catalogTTL time.Duration
+        cache      *catalogCache
+
+        snapshots    *repository.SnapshotStore
+        snapshotOnce sync.Once
+        snapshot     *catalog // Снимок с диска, читается один раз при первом отказе источников
+
+        now func() time.Time // Текущее время для расчета ближайших тиражей
 }
 
-// NewStolotoService создает новый экземпляр StolotoService
-func NewStolotoService(client *repository.StolotoClient) *StolotoService {
-        return &StolotoService{
-                client: client,
+// StolotoServiceOption настраивает StolotoService
+type StolotoServiceOption func(*StolotoService)
+
+// WithProviders заменяет стандартные источники лотерей на указанные
+// (в порядке убывания приоритета)
+func WithProviders(providers ...LotteryProvider) StolotoServiceOption {
+        return func(s *StolotoService) {
+                s.registry = NewProviderRegistry(providers...)
         }
 }
 
-// GetAllLotteries возвращает список всех доступных лотерей
-// Получает данные из StolotoAPI и конвертирует в domain.Lottery
-// В случае ошибки API ВСЕГДА возвращает моковые данные (НИКОГДА не пустой массив)
-func (s *StolotoService) GetAllLotteries(ctx context.Context) ([]domain.Lottery, error) {
-        // Пытаемся получить данные из StolotoAPI
-        drawsResp, err := s.client.GetAllDraws(ctx)
-        if err != nil {
-                log.Printf("StolotoAPI unavailable, using fallback data: %v", err)
-                return s.getMockLotteries(), nil
+// WithCatalogTTL задает, сколько каталог лотерей считается свежим
+// Устаревший каталог отдается сразу и обновляется в фоне
+func WithCatalogTTL(ttl time.Duration) StolotoServiceOption {
+        return func(s *StolotoService) {
+                s.catalogTTL = ttl
         }
+}
 
-        // Конвертируем данные из API в наши domain модели
-        lotteries := make([]domain.Lottery, 0, len(drawsResp.Games))
-        for _, game := range drawsResp.Games {
-                lottery := s.client.ConvertGameToLottery(game)
-                lotteries = append(lotteries, lottery)
+// WithSnapshotStore включает сохранение последнего успешного каталога на диск
+// Снимок используется, если источники недоступны, а кэш еще пуст (например, сразу после запуска)
+func WithSnapshotStore(store *repository.SnapshotStore) StolotoServiceOption {
+        return func(s *StolotoService) {
+                s.snapshots = store
         }
+}
 
-        // Если API вернул пустой список, используем моковые данные
-        // КРИТИЧЕСКОЕ ПРАВИЛО: GetAllLotteries НИКОГДА не возвращает пустой массив
-        if len(lotteries) == 0 {
-                log.Println("StolotoAPI unavailable, using fallback data: API returned empty list")
-                return s.getMockLotteries(), nil
+// WithGameCatalog задает каталог игр (по умолчанию - встроенный)
+// Из него резервные лотереи получают правила игры, призы и расписание
+func WithGameCatalog(games *repository.GameCatalog) StolotoServiceOption {
+        return func(s *StolotoService) {
+                s.games = games
         }
+}
 
-        log.Printf("Successfully loaded %d lotteries from StolotoAPI", len(lotteries))
-        return lotteries, nil
+// NewStolotoService создает новый экземпляр StolotoService
+// По умолчанию каталог собирается из DefaultProviders(client) и кэшируется на 5 минут
+func NewStolotoService(client *repository.StolotoClient, opts ...StolotoServiceOption) *StolotoService {
+        s := &StolotoService{
+                client:     client,
+                registry:   NewProviderRegistry(DefaultProviders(client)...),
+                games:      repository.DefaultGameCatalog(),
+                catalogTTL: defaultCatalogTTL,
+                now:        time.Now,
+        }
+        for _, opt := range opts {
+                opt(s)
+        }
+        s.cache = newCatalogCache(s.catalogTTL, s.fetchCatalog)
+        return s
 }
 
-// GetLotteryByID возвращает информацию о конкретной лотерее по ID
-// ВАЖНО: Использует GetAllLotteries which NEVER fails (always returns data)
-func (s *StolotoService) GetLotteryByID(ctx context.Context, id string) (*domain.Lottery, error) {
-        // Получаем все лотереи (ВСЕГДА возвращает данные, никогда не падает)
-        lotteries, err := s.GetAllLotteries(ctx)
-        // Defensive: GetAllLotteries не должна возвращать ошибку, но на случай катастрофы
+// fetchCatalog загружает каталог со всех источников реестра
+// Пустой каталог считается ошибкой: его нельзя кэшировать вместо данных
+func (s *StolotoService) fetchCatalog(ctx context.Context) ([]domain.Lottery, error) {
+        lotteries, err := s.registry.FetchAll(ctx)
         if err != nil {
-                log.Printf("CRITICAL: GetAllLotteries returned unexpected error: %v - using fallback", err)
-                lotteries = s.getMockLotteries()
+                return nil, err
+        }
+        if len(lotteries) == 0 {
+                return nil, errors.New("API returned empty list")
         }
 
-        // Ищем лотерею с нужным ID
-        for i := range lotteries {
-                if lotteries[i].ID == id {
-                        return &lotteries[i], nil
+        if s.snapshots != nil {
+                snapshot := repository.CatalogSnapshot{FetchedAt: time.Now(), Lotteries: lotteries}
+                if err := s.snapshots.Save(snapshot); err != nil {
+                        log.Printf("Failed to save catalog snapshot: %v", err)
                 }
         }
+        return lotteries, nil
+}
+
+// loadCatalog возвращает каталог по цепочке: кэш (live) -> снимок с диска -> моковые данные
+// КРИТИЧЕСКОЕ ПРАВИЛО: никогда не возвращает пустой каталог
+func (s *StolotoService) loadCatalog(ctx context.Context) *catalog {
+        current, err := s.cache.get(ctx)
+        if err == nil {
+                return current
+        }
 
-        return nil, fmt.Errorf("лотерея с ID %s не найдена", id)
+        if snapshot := s.loadSnapshot(); snapshot != nil {
+                log.Printf("StolotoAPI unavailable, using snapshot from %s: %v", snapshot.fetchedAt.Format(time.RFC3339), err)
+                return snapshot
+        }
+
+        log.Printf("StolotoAPI unavailable, using fallback data: %v", err)
+        return newCatalog(s.getMockLotteries(), domain.DataSourceMock, time.Time{})
+}
+
+// loadSnapshot читает снимок каталога с диска (один раз за время жизни сервиса)
+// Возвращает nil, если хранилище не настроено или снимка нет
+func (s *StolotoService) loadSnapshot() *catalog {
+        if s.snapshots == nil {
+                return nil
+        }
+
+        s.snapshotOnce.Do(func() {
+                snapshot, err := s.snapshots.Load()
+                if err != nil {
+                        if !errors.Is(err, os.ErrNotExist) {
+                                log.Printf("Failed to load catalog snapshot: %v", err)
+                        }
+                        return
+                }
+                if len(snapshot.Lotteries) == 0 {
+                        return
+                }
+                s.snapshot = newCatalog(snapshot.Lotteries, domain.DataSourceSnapshot, snapshot.FetchedAt)
+        })
+        return s.snapshot
+}
+
+// GetAllLotteries возвращает список всех доступных лотерей
+// Собирает данные со всех источников реестра (StolotoAPI, статический каталог...) через кэш
+// В случае ошибки ВСЕХ источников ВСЕГДА возвращает снимок или моковые данные (НИКОГДА не пустой массив)
+func (s *StolotoService) GetAllLotteries(ctx context.Context) ([]domain.Lottery, error) {
+        lotteries, _, err := s.GetAllLotteriesWithProvenance(ctx)
+        return lotteries, err
+}
+
+// GetAllLotteriesWithProvenance возвращает все лотереи и описание того, откуда они получены
+func (s *StolotoService) GetAllLotteriesWithProvenance(ctx context.Context) ([]domain.Lottery, domain.DataProvenance, error) {
+        current := s.loadCatalog(ctx)
+        lotteries := current.list()
+        fillNextDraws(lotteries, s.now())
+        return lotteries, current.provenance(), nil
+}
+
+// GetLotteryByID возвращает информацию о конкретной лотерее по ID
+// ВАЖНО: использует тот же каталог, что и GetAllLotteries (NEVER fails), поиск по индексу
+func (s *StolotoService) GetLotteryByID(ctx context.Context, id string) (*domain.Lottery, error) {
+        lottery, ok := s.loadCatalog(ctx).find(id)
+        if !ok {
+                return nil, fmt.Errorf("%w: %s", ErrLotteryNotFound, id)
+        }
+        if lottery.Schedule != nil {
+                lottery.NextDraws = lottery.Schedule.NextDraws(s.now(), NextDrawsCount)
+        }
+        return &lottery, nil
 }
 
 // GetActiveLotteries возвращает список активных лотерей
 // ВАЖНО: НИКОГДА не возвращает ошибку - использует fallback при проблемах с API
 func (s *StolotoService) GetActiveLotteries(ctx context.Context) ([]domain.Lottery, error) {
-        // Получаем все лотереи (ВСЕГДА возвращает данные, никогда не падает)
-        allLotteries, err := s.GetAllLotteries(ctx)
-        // Defensive: GetAllLotteries не должна возвращать ошибку, но на случай катастрофы
-        if err != nil {
-                log.Printf("CRITICAL: GetAllLotteries returned unexpected error: %v - using fallback", err)
-                allLotteries = s.getMockLotteries()
-        }
+        lotteries, _, err := s.GetActiveLotteriesWithProvenance(ctx)
+        return lotteries, err
+}
+
+// GetActiveLotteriesWithProvenance возвращает активные лотереи и описание того, откуда они получены
+func (s *StolotoService) GetActiveLotteriesWithProvenance(ctx context.Context) ([]domain.Lottery, domain.DataProvenance, error) {
+        // Каталог есть ВСЕГДА (live, снимок или моки)
+        current := s.loadCatalog(ctx)
 
         // Фильтруем только активные
-        activeLotteries := make([]domain.Lottery, 0, len(allLotteries))
-        for _, lottery := range allLotteries {
+        activeLotteries := make([]domain.Lottery, 0, len(current.lotteries))
+        for _, lottery := range current.lotteries {
                 if lottery.IsActive {
                         activeLotteries = append(activeLotteries, lottery)
                 }
         }
+        fillNextDraws(activeLotteries, s.now())
 
-        return activeLotteries, nil
+        return activeLotteries, current.provenance(), nil
 }
 
-// UpdateLotteryData обновляет данные о лотереях из StolotoAPI
+// UpstreamStatus возвращает состояние circuit breaker StolotoAPI
+// Возвращает nil, если сервис работает без клиента StolotoAPI
+func (s *StolotoService) UpstreamStatus() *repository.BreakerSnapshot {
+        if s.client == nil {
+                return nil
+        }
+        snapshot := s.client.BreakerSnapshot()
+        return &snapshot
+}
+
+// UpdateLotteryData принудительно обновляет кэш каталога из всех источников
 func (s *StolotoService) UpdateLotteryData(ctx context.Context) error {
-        _, err := s.client.GetAllDraws(ctx)
+        _, err := s.cache.refresh(ctx)
         if err != nil {
                 return fmt.Errorf("ошибка обновления данных: %w", err)
         }
@@ -159,8 +275,10 @@ func matchesCriteria(lottery domain.Lottery, criteria domain.FilterCriteria) boo
 // getMockLotteries возвращает моковые данные о лотереях
 // Используется как fallback, когда StolotoAPI недоступен
 // Обновлено: используем float64 для цен и джекпотов (синхронизация с schema.ts)
+// Вероятности и структура призов рассчитываются по правилам игр каталога (GameCatalog.ApplyGameRules)
+// Расписание тиражей берется из того же каталога игр
 func (s *StolotoService) getMockLotteries() []domain.Lottery {
-        return []domain.Lottery{
+        lotteries := []domain.Lottery{
                 {
                         ID:             "6x45",
                         Name:           "Гослото 6 из 45",
@@ -168,16 +286,9 @@ func (s *StolotoService) getMockLotteries() []domain.Lottery {
                         TicketPrice:    100.0,
                         MaxJackpot:     400000000.0,
                         CurrentJackpot: 320000000.0,
-                        WinProbability: 0.00001,
                         DrawFrequency:  domain.DrawFrequencyDaily,
                         Description:    "Самая популярная числовая лотерея России. Угадайте 6 чисел из 45, чтобы выиграть джекпот!",
                         Rules:          "Выберите 6 чисел от 1 до 45. Розыгрыш проходит ежедневно в 20:00 МСК. Совпадение всех 6 чисел - главный приз!",
-                        PrizeStructure: []domain.PrizeCategory{
-                                {Category: "6 из 6", Prize: "Джекпот", Probability: "1:8145060"},
-                                {Category: "5 из 6", Prize: "10000 ₽", Probability: "1:34808"},
-                                {Category: "4 из 6", Prize: "1000 ₽", Probability: "1:733"},
-                                {Category: "3 из 6", Prize: "100 ₽", Probability: "1:45"},
-                        },
                         ImageURL: nil,
                         IsActive: true,
                 },
@@ -188,16 +299,9 @@ func (s *StolotoService) getMockLotteries() []domain.Lottery {
                         TicketPrice:    50.0,
                         MaxJackpot:     200000000.0,
                         CurrentJackpot: 156000000.0,
-                        WinProbability: 0.0001,
                         DrawFrequency:  domain.DrawFrequencyDaily,
                         Description:    "Быстрая числовая лотерея с хорошими шансами на выигрыш!",
                         Rules:          "Выберите 5 чисел от 1 до 36. Розыгрыш проходит ежедневно в 14:00 МСК.",
-                        PrizeStructure: []domain.PrizeCategory{
-                                {Category: "5 из 5", Prize: "Джекпот", Probability: "1:376992"},
-                                {Category: "4 из 5", Prize: "5000 ₽", Probability: "1:2432"},
-                                {Category: "3 из 5", Prize: "500 ₽", Probability: "1:81"},
-                                {Category: "2 из 5", Prize: "50 ₽", Probability: "1:8"},
-                        },
                         ImageURL: nil,
                         IsActive: true,
                 },
@@ -208,15 +312,9 @@ func (s *StolotoService) getMockLotteries() []domain.Lottery {
                         TicketPrice:    60.0,
                         MaxJackpot:     50000000.0,
                         CurrentJackpot: 32000000.0,
-                        WinProbability: 0.001,
                         DrawFrequency:  domain.DrawFrequencySeveralPerWeek,
                         Description:    "Лотерея с высокой вероятностью выигрыша и частыми розыгрышами!",
-                        Rules:          "Выберите 4 числа от 1 до 20. Розыгрыши несколько раз в неделю.",
-                        PrizeStructure: []domain.PrizeCategory{
-                                {Category: "4 из 4", Prize: "Джекпот", Probability: "1:4845"},
-                                {Category: "3 из 4", Prize: "1000 ₽", Probability: "1:75"},
-                                {Category: "2 из 4", Prize: "100 ₽", Probability: "1:6"},
-                        },
+                        Rules:          "Выберите по 4 числа от 1 до 20 в каждом из двух полей. Розыгрыши несколько раз в неделю.",
                         ImageURL: nil,
                         IsActive: true,
                 },
@@ -227,16 +325,9 @@ func (s *StolotoService) getMockLotteries() []domain.Lottery {
                         TicketPrice:    150.0,
                         MaxJackpot:     500000000.0,
                         CurrentJackpot: 412000000.0,
-                        WinProbability: 0.000007,
                         DrawFrequency:  domain.DrawFrequencyWeekly,
                         Description:    "Одна из крупнейших лотерей с джекпотом более 400 миллионов рублей!",
                         Rules:          "Выберите 7 чисел от 1 до 49. Розыгрыш каждую среду и субботу в 21:00 МСК.",
-                        PrizeStructure: []domain.PrizeCategory{
-                                {Category: "7 из 7", Prize: "Джекпот", Probability: "1:85900584"},
-                                {Category: "6 из 7", Prize: "50000 ₽", Probability: "1:1235008"},
-                                {Category: "5 из 7", Prize: "5000 ₽", Probability: "1:24129"},
-                                {Category: "4 из 7", Prize: "500 ₽", Probability: "1:710"},
-                        },
                         ImageURL: nil,
                         IsActive: true,
                 },
@@ -247,18 +338,28 @@ func (s *StolotoService) getMockLotteries() []domain.Lottery {
                         TicketPrice:    100.0,
                         MaxJackpot:     10000000.0,
                         CurrentJackpot: 7500000.0,
-                        WinProbability: 0.01,
                         DrawFrequency:  domain.DrawFrequencyDaily,
                         Description:    "Моментальная лотерея с быстрыми результатами! Розыгрыши каждые 15 минут!",
-                        Rules:          "Выберите числа и ждите результатов. Розыгрыш каждые 15 минут с 09:00 до 23:00 МСК.",
-                        PrizeStructure: []domain.PrizeCategory{
-                                {Category: "Суперприз", Prize: "7.5 млн ₽", Probability: "1:100000"},
-                                {Category: "Главный приз", Prize: "100000 ₽", Probability: "1:10000"},
-                                {Category: "Приз 3", Prize: "10000 ₽", Probability: "1:1000"},
-                                {Category: "Приз 4", Prize: "1000 ₽", Probability: "1:100"},
-                        },
+                        Rules:          "Выберите 8 чисел от 1 до 20 и 1 число от 1 до 4. Розыгрыш каждые 15 минут с 09:00 до 23:00 МСК.",
                         ImageURL: nil,
                         IsActive: true,
                 },
         }
+
+        for i := range lotteries {
+                s.games.ApplyGameRules(&lotteries[i])
+                if entry, ok := s.games.Lookup(lotteries[i].ID); ok {
+                        lotteries[i].Schedule = entry.Schedule
+                }
+        }
+        return lotteries
+}
+
+// fillNextDraws заполняет ближайшие тиражи лотерей с известным расписанием
+func fillNextDraws(lotteries []domain.Lottery, now time.Time) {
+        for i := range lotteries {
+                if lotteries[i].Schedule != nil {
+                        lotteries[i].NextDraws = lotteries[i].Schedule.NextDraws(now, NextDrawsCount)
+                }
+        }
 }
@@ -2,6 +2,8 @@ package service
 
 import (
         "context"
+        "errors"
+        "path/filepath"
         "testing"
 
         "github.com/stoloto-recommendations/backend/internal/domain"
@@ -153,3 +155,69 @@ func TestGetLotteryByIDWithFallback(t *testing.T) {
 
         t.Logf("✅ GetLotteryByID correctly handles both valid and invalid IDs with fallback data")
 }
+
+// TestFallbackChainProvenance проверяет цепочку live -> снимок -> моки и происхождение данных
+func TestFallbackChainProvenance(t *testing.T) {
+        ctx := context.Background()
+        store := repository.NewSnapshotStore(filepath.Join(t.TempDir(), "snapshot.json"))
+
+        // Без снимка при недоступных источниках отдаются моки
+        down := &fakeProvider{name: "down", err: errors.New("upstream down")}
+        service := NewStolotoService(nil, WithProviders(down), WithSnapshotStore(store))
+        _, provenance, _ := service.GetAllLotteriesWithProvenance(ctx)
+        if provenance.Source != domain.DataSourceMock || provenance.FetchedAt != nil {
+                t.Errorf("Ожидается mock без времени получения, получено %+v", provenance)
+        }
+
+        // Успешная загрузка отдает live и сохраняет снимок
+        live := &fakeProvider{name: "live", lotteries: []domain.Lottery{{ID: "bingo75", IsActive: true}}}
+        service = NewStolotoService(nil, WithProviders(live), WithSnapshotStore(store))
+        lotteries, provenance, _ := service.GetActiveLotteriesWithProvenance(ctx)
+        if provenance.Source != domain.DataSourceLive || provenance.FetchedAt == nil {
+                t.Errorf("Ожидается live с временем получения, получено %+v", provenance)
+        }
+        if len(lotteries) != 1 || lotteries[0].ID != "bingo75" {
+                t.Fatalf("Ожидается лотерея bingo75, получено %+v", lotteries)
+        }
+
+        // После перезапуска при недоступных источниках отдается снимок, а не моки
+        service = NewStolotoService(nil, WithProviders(down), WithSnapshotStore(store))
+        lotteries, provenance, _ = service.GetAllLotteriesWithProvenance(ctx)
+        if provenance.Source != domain.DataSourceSnapshot || provenance.FetchedAt == nil {
+                t.Errorf("Ожидается snapshot с временем получения, получено %+v", provenance)
+        }
+        if len(lotteries) != 1 || lotteries[0].ID != "bingo75" {
+                t.Errorf("Ожидается лотерея bingo75 из снимка, получено %+v", lotteries)
+        }
+}
+
+// TestMockLotteriesUseGameCatalog проверяет, что резервные лотереи берут правила и расписание из заданного каталога игр
+func TestMockLotteriesUseGameCatalog(t *testing.T) {
+        games, err := repository.ParseGameCatalog([]byte(`{"version": 1, "games": {"6x45": {
+                "displayName": "Гослото 6 из 45", "type": "числовая", "drawFrequency": "ежедневно",
+                "schedule": {"times": ["21:30"]},
+                "fields": [{"pick": 6, "of": 45}],
+                "tiers": [{"name": "6 из 6", "matches": [[6]], "payout": {"kind": "jackpot"}}]
+        }}}`))
+        if err != nil {
+                t.Fatalf("ParseGameCatalog: %v", err)
+        }
+
+        down := &fakeProvider{name: "down", err: errors.New("upstream down")}
+        service := NewStolotoService(nil, WithProviders(down), WithGameCatalog(games))
+        lottery, err := service.GetLotteryByID(context.Background(), "6x45")
+        if err != nil {
+                t.Fatalf("GetLotteryByID: %v", err)
+        }
+        if lottery.GameRules == nil || len(lottery.GameRules.Tiers) != 1 || len(lottery.PrizeStructure) != 1 {
+                t.Errorf("Ожидаются правила с одной категорией из заданного каталога, получено %+v", lottery.GameRules)
+        }
+        if lottery.Schedule == nil || len(lottery.Schedule.Times) != 1 || lottery.Schedule.Times[0] != "21:30" {
+                t.Errorf("Ожидается расписание 21:30 из заданного каталога, получено %+v", lottery.Schedule)
+        }
+
+        // Игры, которых нет в заданном каталоге, остаются без правил
+        if lottery, err := service.GetLotteryByID(context.Background(), "5x36"); err != nil || lottery.GameRules != nil {
+                t.Errorf("5x36 нет в каталоге: ожидается лотерея без правил, получено %+v, ошибка %v", lottery, err)
+        }
+}
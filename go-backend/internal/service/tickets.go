@@ -0,0 +1,123 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+var (
+        // ErrRulesUnavailable возвращается, если правила игры лотереи неизвестны и билет нельзя проверить
+        ErrRulesUnavailable = errors.New("правила игры лотереи неизвестны")
+        // ErrInvalidTicket возвращается, если комбинация билета не соответствует правилам игры
+        ErrInvalidTicket = errors.New("некорректная комбинация билета")
+        // ErrDrawPending возвращается, если у тиража еще нет выигрышной комбинации
+        ErrDrawPending = errors.New("результаты тиража еще не известны")
+)
+
+// CheckTicket проверяет комбинации билета по результатам тиража
+// Тираж берется так же, как в GetDraw: из архива или StolotoAPI
+func (s *DrawService) CheckTicket(ctx context.Context, request domain.TicketCheckRequest) (*domain.TicketCheckResult, domain.DataProvenance, error) {
+        lottery, err := s.lotteries.GetLotteryByID(ctx, request.LotteryID)
+        if err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        // Комбинации проверяются до запроса тиража, чтобы не тратить лимит запросов к StolotoAPI
+        if err := validateCombinations(*lottery, request.Combinations); err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+
+        draw, provenance, err := s.GetDraw(ctx, request.LotteryID, request.DrawNumber)
+        if err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+        result, err := matchTicket(*lottery, *draw, request.Combinations)
+        if err != nil {
+                return nil, domain.DataProvenance{}, err
+        }
+        return result, provenance, nil
+}
+
+// validateCombinations проверяет комбинации билета по правилам игры лотереи
+func validateCombinations(lottery domain.Lottery, combinations []domain.TicketCombination) error {
+        if lottery.GameRules == nil {
+                return fmt.Errorf("%w: %s", ErrRulesUnavailable, lottery.ID)
+        }
+        for i, combination := range combinations {
+                if err := lottery.GameRules.ValidatePicks(combination); err != nil {
+                        return fmt.Errorf("%w: комбинация %d: %v", ErrInvalidTicket, i+1, err)
+                }
+        }
+        return nil
+}
+
+// matchTicket сравнивает комбинации (проверенные ValidatePicks) с выигрышной комбинацией тиража
+func matchTicket(lottery domain.Lottery, draw domain.DrawResult, combinations []domain.TicketCombination) (*domain.TicketCheckResult, error) {
+        if lottery.GameRules == nil {
+                return nil, fmt.Errorf("%w: %s", ErrRulesUnavailable, lottery.ID)
+        }
+        rules := *lottery.GameRules
+
+        if len(draw.WinningNumbers) == 0 {
+                return nil, fmt.Errorf("%w: тираж %d лотереи %s", ErrDrawPending, draw.Number, lottery.ID)
+        }
+        drawn, err := rules.SplitDrawn(draw.WinningNumbers)
+        if err != nil {
+                return nil, fmt.Errorf("тираж %d лотереи %s: %w", draw.Number, lottery.ID, err)
+        }
+
+        result := &domain.TicketCheckResult{
+                LotteryID:      lottery.ID,
+                DrawNumber:     draw.Number,
+                DrawDate:       draw.DrawDate,
+                WinningNumbers: drawn,
+                Combinations:   make([]domain.CombinationCheck, 0, len(combinations)),
+        }
+        for _, combination := range combinations {
+                matched, tier := rules.MatchPicks(combination, drawn)
+                check := domain.CombinationCheck{Numbers: combination, Matched: matched}
+                if tier >= 0 {
+                        check.Won = true
+                        check.Category = rules.Tiers[tier].Name
+                        check.Prize = tierPrize(lottery, draw, tier)
+                        if check.Prize != nil {
+                                result.TotalPrize += *check.Prize
+                        }
+                }
+                result.Combinations = append(result.Combinations, check)
+        }
+        return result, nil
+}
+
+// tierPrize возвращает выигрыш на билет в категории tier тиража (nil - размер неизвестен)
+// Приоритет у фактической выплаты из победителей тиража; без нее используется приз из правил:
+// фиксированный приз или суперприз тиража. Долю призового фонда без данных тиража не оценить.
+func tierPrize(lottery domain.Lottery, draw domain.DrawResult, tier int) *float64 {
+        if key := tierKey(lottery, tier); key != "" {
+                for _, winners := range draw.Winners {
+                        if winners.Tier == key && winners.Prize > 0 {
+                                prize := winners.Prize
+                                return &prize
+                        }
+                }
+        }
+
+        if tier >= len(lottery.PrizeStructure) || lottery.PrizeStructure[tier].Payout == nil {
+                return nil
+        }
+        payout := lottery.PrizeStructure[tier].Payout
+        switch payout.Kind {
+        case domain.PayoutFixed:
+                prize := payout.Amount
+                return &prize
+        case domain.PayoutJackpot:
+                if draw.Jackpot > 0 {
+                        prize := draw.Jackpot
+                        return &prize
+                }
+        }
+        return nil
+}
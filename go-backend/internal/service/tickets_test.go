@@ -0,0 +1,152 @@
+package service
+
+import (
+        "context"
+        "encoding/json"
+        "errors"
+        "reflect"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// newTestTicketService создает DrawService с лотереями newTestLotteries и архивом с тиражами
+func newTestTicketService(t *testing.T, draws ...repository.Draw) *DrawService {
+        t.Helper()
+
+        archive, err := repository.NewFileDrawArchive(t.TempDir())
+        if err != nil {
+                t.Fatalf("Не удалось создать архив: %v", err)
+        }
+        if err := archive.Save(context.Background(), draws...); err != nil {
+                t.Fatalf("Не удалось сохранить тиражи: %v", err)
+        }
+        return NewDrawService(newTestLotteries(t), &fakeDrawFetcher{latest: 100}, archive)
+}
+
+// newTestLotteries создает каталог из лотерей с правилами игры 6x45 (100 ₽) и 5x36plus (80 ₽)
+// и лотереи без правил ruslotto
+func newTestLotteries(t *testing.T) *StolotoService {
+        t.Helper()
+
+        lotteries := []domain.Lottery{
+                {ID: "6x45", TicketPrice: 100, CurrentJackpot: 300000000, IsActive: true},
+                {ID: "5x36plus", TicketPrice: 80, CurrentJackpot: 10000000, IsActive: true},
+                {ID: "ruslotto", IsActive: true},
+        }
+        for i := range lotteries {
+                repository.ApplyGameRules(&lotteries[i])
+        }
+        return NewStolotoService(nil, WithProviders(&fakeProvider{name: "catalog", lotteries: lotteries}))
+}
+
+// TestCheckTicket проверяет совпадения, категории и выигрыши комбинаций
+func TestCheckTicket(t *testing.T) {
+        service := newTestTicketService(t,
+                repository.Draw{
+                        Number: 10, GameName: "6x45", WinningNumbers: []int{3, 7, 15, 22, 31, 40}, Jackpot: 30000000000,
+                        Winners: repository.WinnerTiers{{Tier: "5", Winners: 2, Prize: 1500000}},
+                },
+                repository.Draw{Number: 20, GameName: "5x36plus", WinningNumbers: []int{1, 2, 3, 4, 5, 3}},
+        )
+        ctx := context.Background()
+
+        var request domain.TicketCheckRequest
+        body := `{"lotteryId": "6x45", "drawNumber": 10, "combinations": [
+                [3, 7, 15, 22, 31, 40],
+                [[3, 7, 15, 22, 31, 41]],
+                [3, 7, 15, 1, 2, 4],
+                [1, 2, 4, 5, 6, 8]
+        ]}`
+        if err := json.Unmarshal([]byte(body), &request); err != nil {
+                t.Fatalf("Не удалось разобрать запрос: %v", err)
+        }
+
+        result, provenance, err := service.CheckTicket(ctx, request)
+        if err != nil {
+                t.Fatalf("CheckTicket: %v", err)
+        }
+        if provenance.Source != domain.DataSourceArchive {
+                t.Errorf("Ожидается тираж из архива, получено %s", provenance.Source)
+        }
+        if !reflect.DeepEqual(result.WinningNumbers, [][]int{{3, 7, 15, 22, 31, 40}}) {
+                t.Errorf("Некорректная выигрышная комбинация: %v", result.WinningNumbers)
+        }
+
+        expected := []struct {
+                matched  int
+                category string
+                prize    float64
+        }{
+                {6, "6 из 6", 300000000}, // Без данных о победителях - суперприз тиража
+                {5, "5 из 6", 15000},     // Фактическая выплата тиража
+                {3, "3 из 6", 100},       // Фиксированный приз по правилам
+                {0, "", 0},
+        }
+        for i, want := range expected {
+                check := result.Combinations[i]
+                if len(check.Matched[0]) != want.matched || check.Category != want.category || check.Won != (want.category != "") {
+                        t.Errorf("Комбинация %d: ожидается %d совпадений (%q), получено %+v", i+1, want.matched, want.category, check)
+                        continue
+                }
+                if want.category == "" {
+                        if check.Prize != nil {
+                                t.Errorf("Комбинация %d: без выигрыша приз не передается", i+1)
+                        }
+                        continue
+                }
+                if check.Prize == nil || *check.Prize != want.prize {
+                        t.Errorf("Комбинация %d: ожидается приз %v, получено %v", i+1, want.prize, check.Prize)
+                }
+        }
+        if result.TotalPrize != 300000000+15000+100 {
+                t.Errorf("Некорректная сумма выигрышей: %v", result.TotalPrize)
+        }
+
+        // Два поля: 5 из 36 и 1 из 4; доля призового фонда без данных тиража неизвестна
+        result, _, err = service.CheckTicket(ctx, domain.TicketCheckRequest{
+                LotteryID: "5x36plus", DrawNumber: 20,
+                Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5}, {1}}, {{1, 2, 3, 4, 6}, {3}}},
+        })
+        if err != nil {
+                t.Fatalf("CheckTicket: %v", err)
+        }
+        if first := result.Combinations[0]; first.Category != "5" || first.Prize != nil || len(first.Matched[1]) != 0 {
+                t.Errorf("5 из 5 без второго поля: получено %+v", first)
+        }
+        // Во втором поле совпадение, но категория "4" не зависит от второго поля
+        second := result.Combinations[1]
+        if second.Category != "4" || !reflect.DeepEqual(second.Matched, [][]int{{1, 2, 3, 4}, {3}}) || second.Prize == nil || *second.Prize != 5000 {
+                t.Errorf("4 из 5: получено %+v", second)
+        }
+}
+
+// TestCheckTicketErrors проверяет ошибки проверки билета
+func TestCheckTicketErrors(t *testing.T) {
+        service := newTestTicketService(t, repository.Draw{Number: 5, GameName: "6x45"})
+        ctx := context.Background()
+
+        cases := map[string]struct {
+                request  domain.TicketCheckRequest
+                expected error
+        }{
+                "неизвестная лотерея": {domain.TicketCheckRequest{LotteryID: "unknown", DrawNumber: 1}, ErrLotteryNotFound},
+                "нет правил":          {domain.TicketCheckRequest{LotteryID: "ruslotto", DrawNumber: 1}, ErrRulesUnavailable},
+                "мало чисел": {domain.TicketCheckRequest{LotteryID: "6x45", DrawNumber: 1,
+                        Combinations: []domain.TicketCombination{{{1, 2, 3}}}}, ErrInvalidTicket},
+                "вне диапазона": {domain.TicketCheckRequest{LotteryID: "6x45", DrawNumber: 1,
+                        Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5, 46}}}}, ErrInvalidTicket},
+                "повтор": {domain.TicketCheckRequest{LotteryID: "6x45", DrawNumber: 1,
+                        Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5, 5}}}}, ErrInvalidTicket},
+                "лишнее поле": {domain.TicketCheckRequest{LotteryID: "6x45", DrawNumber: 1,
+                        Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5, 6}, {1}}}}, ErrInvalidTicket},
+                "тираж не разыгран": {domain.TicketCheckRequest{LotteryID: "6x45", DrawNumber: 5,
+                        Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5, 6}}}}, ErrDrawPending},
+        }
+        for name, c := range cases {
+                if _, _, err := service.CheckTicket(ctx, c.request); !errors.Is(err, c.expected) {
+                        t.Errorf("%s: ожидается %v, получено %v", name, c.expected, err)
+                }
+        }
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+        "context"
+        "crypto/rand"
+        "encoding/hex"
+        "errors"
+        "fmt"
+        "log"
+        "sort"
+        "sync"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+const (
+        defaultWalletPageSize = 20
+        maxWalletPageSize     = 100
+
+        // DefaultWalletCheckInterval - пауза между проверками билетов кошельков
+        DefaultWalletCheckInterval = 15 * time.Minute
+        // walletMissingDrawPasses - сколько проходов подряд разыгранный тираж может не находиться,
+        // прежде чем билеты перестанут его ждать
+        walletMissingDrawPasses = 3
+)
+
+// WalletService хранит билеты пользователей и проверяет их по результатам тиражей
+type WalletService struct {
+        store repository.WalletStore
+        draws *DrawService
+        now   func() time.Time
+
+        mu      sync.Mutex
+        missing map[drawKey]int // Сколько проходов подряд тираж не найден
+}
+
+// NewWalletService создает новый экземпляр WalletService
+func NewWalletService(store repository.WalletStore, draws *DrawService) *WalletService {
+        return &WalletService{
+                store:   store,
+                draws:   draws,
+                now:     time.Now,
+                missing: make(map[drawKey]int),
+        }
+}
+
+// AddTicket добавляет билет в кошелек пользователя
+// Комбинации проверяются по правилам игры; результаты тиражей заполняет CheckPending
+func (s *WalletService) AddTicket(ctx context.Context, userID string, request domain.WalletTicketRequest) (*domain.WalletTicket, error) {
+        lottery, err := s.draws.lotteries.GetLotteryByID(ctx, request.LotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if err := validateCombinations(*lottery, request.Combinations); err != nil {
+                return nil, err
+        }
+
+        purchasedAt := s.now()
+        if request.PurchasedAt != nil {
+                purchasedAt = *request.PurchasedAt
+        }
+        ticket := domain.WalletTicket{
+                ID:           newTicketID(),
+                LotteryID:    request.LotteryID,
+                DrawNumbers:  uniqueSorted(request.DrawNumbers),
+                Combinations: request.Combinations,
+                AmountPaid:   request.AmountPaid,
+                PurchasedAt:  purchasedAt,
+                Status:       domain.TicketStatusPending,
+        }
+        if err := s.store.Add(ctx, userID, ticket); err != nil {
+                return nil, err
+        }
+        return &ticket, nil
+}
+
+// GetTicket возвращает билет пользователя
+func (s *WalletService) GetTicket(ctx context.Context, userID, ticketID string) (*domain.WalletTicket, error) {
+        return s.store.Get(ctx, userID, ticketID)
+}
+
+// DeleteTicket удаляет билет из кошелька пользователя
+func (s *WalletService) DeleteTicket(ctx context.Context, userID, ticketID string) error {
+        return s.store.Delete(ctx, userID, ticketID)
+}
+
+// ListTickets возвращает страницу билетов пользователя от новых к старым
+func (s *WalletService) ListTickets(ctx context.Context, userID string, filter domain.WalletFilter) (*domain.WalletPage, error) {
+        tickets, err := s.store.List(ctx, userID)
+        if err != nil {
+                return nil, err
+        }
+
+        if filter.Offset < 0 {
+                filter.Offset = 0
+        }
+        if filter.Limit <= 0 {
+                filter.Limit = defaultWalletPageSize
+        }
+        if filter.Limit > maxWalletPageSize {
+                filter.Limit = maxWalletPageSize
+        }
+
+        matched := make([]domain.WalletTicket, 0, len(tickets))
+        for _, ticket := range tickets {
+                if walletFilterMatches(filter, ticket) {
+                        matched = append(matched, ticket)
+                }
+        }
+        sort.SliceStable(matched, func(i, j int) bool {
+                return matched[i].PurchasedAt.After(matched[j].PurchasedAt)
+        })
+
+        page := &domain.WalletPage{Tickets: []domain.WalletTicket{}, Total: len(matched), Offset: filter.Offset, Limit: filter.Limit}
+        if filter.Offset < len(matched) {
+                matched = matched[filter.Offset:]
+                if len(matched) > filter.Limit {
+                        matched = matched[:filter.Limit]
+                }
+                page.Tickets = matched
+        }
+        return page, nil
+}
+
+// walletFilterMatches проверяет, попадает ли билет в выборку
+func walletFilterMatches(filter domain.WalletFilter, ticket domain.WalletTicket) bool {
+        if filter.LotteryID != "" && ticket.LotteryID != filter.LotteryID {
+                return false
+        }
+        if filter.Status != "" && ticket.Status != filter.Status {
+                return false
+        }
+        if !filter.From.IsZero() && ticket.PurchasedAt.Before(filter.From) {
+                return false
+        }
+        if !filter.To.IsZero() && !ticket.PurchasedAt.Before(filter.To) {
+                return false
+        }
+        return true
+}
+
+// Run периодически выполняет CheckPending до отмены контекста
+func (s *WalletService) Run(ctx context.Context, interval time.Duration) {
+        if interval <= 0 {
+                interval = DefaultWalletCheckInterval
+        }
+        for {
+                if updated, err := s.CheckPending(ctx); err != nil {
+                        log.Printf("[Wallet] Check interrupted after %d tickets: %v", updated, err)
+                } else if updated > 0 {
+                        log.Printf("[Wallet] Checked %d tickets", updated)
+                }
+
+                select {
+                case <-ctx.Done():
+                        return
+                case <-time.After(interval):
+                }
+        }
+}
+
+// drawKey - тираж лотереи
+type drawKey struct {
+        lotteryID string
+        number    int
+}
+
+// walletCheck - состояние одного прохода проверки: тиражи и лотереи запрашиваются один раз на проход
+type walletCheck struct {
+        service   *WalletService
+        latest    map[string]int // Номер последнего разыгранного тиража лотереи (0 - неизвестен)
+        draws     map[drawKey]*domain.DrawResult
+        missing   map[drawKey]bool // Разыгранные тиражи, которых нет уже walletMissingDrawPasses проходов
+        lotteries map[string]*domain.Lottery
+}
+
+// CheckPending проверяет непроверенные тиражи билетов по разыгранным тиражам и возвращает число обновленных билетов
+// Тиражи новее последнего разыгранного не запрашиваются. Если StolotoAPI недоступен, проход прекращается.
+// Разыгранный тираж, которого нет walletMissingDrawPasses проходов подряд, отмечается у билета отсутствующим
+// и больше не запрашивается.
+func (s *WalletService) CheckPending(ctx context.Context) (int, error) {
+        users, err := s.store.Users(ctx)
+        if err != nil {
+                return 0, fmt.Errorf("ошибка получения списка кошельков: %w", err)
+        }
+
+        check := &walletCheck{
+                service:   s,
+                latest:    make(map[string]int),
+                draws:     make(map[drawKey]*domain.DrawResult),
+                missing:   make(map[drawKey]bool),
+                lotteries: make(map[string]*domain.Lottery),
+        }
+        updated := 0
+        for _, userID := range users {
+                tickets, err := s.store.List(ctx, userID)
+                if err != nil {
+                        log.Printf("[Wallet] Failed to read wallet %s: %v", userID, err)
+                        continue
+                }
+
+                for _, ticket := range tickets {
+                        // Выигравший билет проверяется дальше, пока не получены результаты всех его тиражей
+                        if ticket.Complete() {
+                                continue
+                        }
+                        results, missing, err := check.ticketResults(ctx, ticket)
+                        if len(results) > 0 || len(missing) > 0 {
+                                if updateErr := s.saveResults(ctx, userID, ticket.ID, results, missing); updateErr != nil {
+                                        log.Printf("[Wallet] Failed to save results of ticket %s: %v", ticket.ID, updateErr)
+                                } else {
+                                        updated++
+                                }
+                        }
+                        if err != nil {
+                                return updated, err
+                        }
+                }
+        }
+        return updated, nil
+}
+
+// ticketResults проверяет еще не проверенные разыгранные тиражи билета
+// и возвращает их результаты и тиражи, которые пора отметить отсутствующими
+// Ошибка возвращается только при недоступности результатов тиражей (проход нужно прекратить)
+func (c *walletCheck) ticketResults(ctx context.Context, ticket domain.WalletTicket) ([]domain.TicketCheckResult, []int, error) {
+        lottery, err := c.lottery(ctx, ticket.LotteryID)
+        if err != nil {
+                log.Printf("[Wallet] Ticket %s: %v", ticket.ID, err)
+                return nil, nil, nil
+        }
+
+        var results []domain.TicketCheckResult
+        var missing []int
+        for _, number := range ticket.DrawNumbers {
+                if ticket.Checked(number) {
+                        continue
+                }
+
+                draw, err := c.draw(ctx, ticket.LotteryID, number)
+                if err != nil {
+                        return results, missing, err
+                }
+                if draw == nil {
+                        if c.missing[drawKey{ticket.LotteryID, number}] {
+                                missing = append(missing, number)
+                        }
+                        continue // Тираж еще не разыгран или не найден
+                }
+
+                result, err := matchTicket(*lottery, *draw, ticket.Combinations)
+                if err != nil {
+                        if !errors.Is(err, ErrDrawPending) {
+                                log.Printf("[Wallet] Ticket %s, draw %d: %v", ticket.ID, number, err)
+                        }
+                        continue
+                }
+                results = append(results, *result)
+        }
+        return results, missing, nil
+}
+
+// lottery возвращает лотерею из каталога (один раз за проход)
+func (c *walletCheck) lottery(ctx context.Context, lotteryID string) (*domain.Lottery, error) {
+        if lottery, ok := c.lotteries[lotteryID]; ok {
+                return lottery, nil
+        }
+        lottery, err := c.service.draws.lotteries.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        c.lotteries[lotteryID] = lottery
+        return lottery, nil
+}
+
+// draw возвращает разыгранный тираж (один раз за проход) или nil, если тираж еще не разыгран или не найден
+func (c *walletCheck) draw(ctx context.Context, lotteryID string, number int) (*domain.DrawResult, error) {
+        latest, ok := c.latest[lotteryID]
+        if !ok {
+                draw, _, err := c.service.draws.GetLatestDraw(ctx, lotteryID)
+                switch {
+                case errors.Is(err, ErrDrawNotFound):
+                        latest = 0
+                case err != nil:
+                        return nil, err
+                default:
+                        latest = draw.Number
+                        c.draws[drawKey{lotteryID, draw.Number}] = draw
+                }
+                c.latest[lotteryID] = latest
+        }
+        if number > latest {
+                return nil, nil
+        }
+
+        key := drawKey{lotteryID, number}
+        if draw, ok := c.draws[key]; ok {
+                return draw, nil
+        }
+        draw, _, err := c.service.draws.GetDraw(ctx, lotteryID, number)
+        if errors.Is(err, ErrDrawNotFound) {
+                c.draws[key] = nil
+                c.missing[key] = c.service.drawMissed(key)
+                return nil, nil
+        }
+        if err != nil {
+                return nil, err
+        }
+        c.service.drawFound(key)
+        c.draws[key] = draw
+        return draw, nil
+}
+
+// drawMissed учитывает проход, в котором разыгранный тираж не найден,
+// и сообщает, что тираж не находится уже walletMissingDrawPasses проходов подряд
+func (s *WalletService) drawMissed(key drawKey) bool {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        s.missing[key]++
+        return s.missing[key] >= walletMissingDrawPasses
+}
+
+// drawFound сбрасывает счетчик проходов без тиража
+func (s *WalletService) drawFound(key drawKey) {
+        s.mu.Lock()
+        defer s.mu.Unlock()
+        delete(s.missing, key)
+}
+
+// saveResults добавляет к билету результаты и отсутствующие тиражи и пересчитывает его статус
+func (s *WalletService) saveResults(ctx context.Context, userID, ticketID string, results []domain.TicketCheckResult, missing []int) error {
+        checkedAt := s.now()
+        return s.store.Update(ctx, userID, ticketID, func(ticket *domain.WalletTicket) error {
+                // Results общий с кэшем хранилища и копиями из List: меняем только свою копию
+                ticket.Results = append([]domain.TicketCheckResult(nil), ticket.Results...)
+                for _, result := range results {
+                        if !ticket.Checked(result.DrawNumber) {
+                                ticket.Results = append(ticket.Results, result)
+                        }
+                }
+                sort.Slice(ticket.Results, func(i, j int) bool {
+                        return ticket.Results[i].DrawNumber < ticket.Results[j].DrawNumber
+                })
+                if len(missing) > 0 {
+                        ticket.MissingDraws = append([]int(nil), ticket.MissingDraws...)
+                        for _, number := range missing {
+                                if !ticket.Checked(number) {
+                                        ticket.MissingDraws = append(ticket.MissingDraws, number)
+                                }
+                        }
+                        sort.Ints(ticket.MissingDraws)
+                }
+                ticket.UpdateStatus()
+                ticket.CheckedAt = &checkedAt
+                return nil
+        })
+}
+
+// newTicketID генерирует случайный идентификатор билета
+func newTicketID() string {
+        var id [8]byte
+        if _, err := rand.Read(id[:]); err != nil {
+                return fmt.Sprintf("%x", time.Now().UnixNano())
+        }
+        return hex.EncodeToString(id[:])
+}
+
+// uniqueSorted возвращает числа по возрастанию без повторов
+func uniqueSorted(numbers []int) []int {
+        sorted := append([]int(nil), numbers...)
+        sort.Ints(sorted)
+        unique := sorted[:0]
+        for i, number := range sorted {
+                if i == 0 || number != sorted[i-1] {
+                        unique = append(unique, number)
+                }
+        }
+        return unique
+}
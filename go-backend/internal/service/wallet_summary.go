@@ -0,0 +1,191 @@
+package service
+
+import (
+        "context"
+        "log"
+        "sort"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// GetSummary возвращает статистику выигрышей и проигрышей по всем билетам кошелька пользователя
+// Если лотерея билета недоступна в каталоге, билет учитывается только в суммах (без категорий и EV)
+func (s *WalletService) GetSummary(ctx context.Context, userID string) (*domain.WalletSummary, error) {
+        tickets, err := s.store.List(ctx, userID)
+        if err != nil {
+                return nil, err
+        }
+
+        lotteries := make(map[string]domain.Lottery)
+        for _, ticket := range tickets {
+                if _, ok := lotteries[ticket.LotteryID]; ok {
+                        continue
+                }
+                lottery, err := s.draws.lotteries.GetLotteryByID(ctx, ticket.LotteryID)
+                if err != nil {
+                        log.Printf("[Wallet] Summary without rules of %s: %v", ticket.LotteryID, err)
+                        continue
+                }
+                lotteries[ticket.LotteryID] = *lottery
+        }
+
+        summary := SummarizeWallet(tickets, lotteries)
+        return &summary, nil
+}
+
+// lotteryTotals накапливает итоги кошелька по одной лотерее
+type lotteryTotals struct {
+        summary      domain.WalletLotterySummary
+        checkedStake float64                  // Затраты на проверенные тиражи
+        expectation  domain.WalletExpectation // Только если известен EV лотереи
+}
+
+// SummarizeWallet считает статистику кошелька по билетам и лотереям из каталога
+// Затраты на билет делятся между его тиражами поровну: с ожидаемым выигрышем сравниваются только проверенные тиражи.
+// Ожидаемый выигрыш комбинации в тираже - EV билета лотереи до НДФЛ (с текущим джекпотом).
+func SummarizeWallet(tickets []domain.WalletTicket, lotteries map[string]domain.Lottery) domain.WalletSummary {
+        summary := domain.WalletSummary{
+                ByLottery: []domain.WalletLotterySummary{},
+                ByMonth:   []domain.WalletMonthSummary{},
+        }
+        byLottery := make(map[string]*lotteryTotals)
+        byMonth := make(map[string]*domain.WalletTotals)
+
+        for _, ticket := range tickets {
+                lottery, known := lotteries[ticket.LotteryID]
+                totals, ok := byLottery[ticket.LotteryID]
+                if !ok {
+                        totals = &lotteryTotals{summary: domain.WalletLotterySummary{LotteryID: ticket.LotteryID}}
+                        if known && lottery.GameRules != nil {
+                                totals.summary.Tiers = newTierStats(lottery)
+                        }
+                        byLottery[ticket.LotteryID] = totals
+                }
+                month := ticket.PurchasedAt.In(domain.MoscowTime).Format("2006-01")
+                if byMonth[month] == nil {
+                        byMonth[month] = &domain.WalletTotals{}
+                }
+
+                addTicketTotals(&summary.Totals, ticket)
+                addTicketTotals(&totals.summary.Totals, ticket)
+                addTicketTotals(byMonth[month], ticket)
+
+                if len(ticket.Results) == 0 || len(ticket.DrawNumbers) == 0 {
+                        continue
+                }
+                stake := ticket.AmountPaid * float64(len(ticket.Results)) / float64(len(ticket.DrawNumbers))
+                plays := len(ticket.Results) * len(ticket.Combinations)
+                totals.checkedStake += stake
+                totals.summary.Plays += plays
+                addTierHits(totals.summary.Tiers, ticket)
+
+                if known && lottery.ExpectedValue != nil {
+                        totals.expectation.Stake += stake
+                        totals.expectation.Won += ticket.Prize
+                        totals.expectation.ExpectedWon += lottery.ExpectedValue.Gross * float64(plays)
+                }
+        }
+
+        var expectation domain.WalletExpectation
+        for _, totals := range byLottery {
+                lotterySummary := totals.summary
+                finishTotals(&lotterySummary.Totals)
+                for i := range lotterySummary.Tiers {
+                        tier := &lotterySummary.Tiers[i]
+                        if lotterySummary.Plays > 0 {
+                                tier.HitRate = float64(tier.Hits) / float64(lotterySummary.Plays)
+                        }
+                        if totals.checkedStake > 0 {
+                                tier.ROI = tier.Won / totals.checkedStake
+                        }
+                }
+                lotterySummary.Expectation = finishExpectation(totals.expectation)
+                summary.ByLottery = append(summary.ByLottery, lotterySummary)
+
+                expectation.Stake += totals.expectation.Stake
+                expectation.Won += totals.expectation.Won
+                expectation.ExpectedWon += totals.expectation.ExpectedWon
+        }
+        sort.Slice(summary.ByLottery, func(i, j int) bool {
+                a, b := summary.ByLottery[i], summary.ByLottery[j]
+                if a.Totals.Spent != b.Totals.Spent {
+                        return a.Totals.Spent > b.Totals.Spent
+                }
+                return a.LotteryID < b.LotteryID
+        })
+
+        for month, totals := range byMonth {
+                finishTotals(totals)
+                summary.ByMonth = append(summary.ByMonth, domain.WalletMonthSummary{Month: month, Totals: *totals})
+        }
+        sort.Slice(summary.ByMonth, func(i, j int) bool {
+                return summary.ByMonth[i].Month < summary.ByMonth[j].Month
+        })
+
+        finishTotals(&summary.Totals)
+        summary.Expectation = finishExpectation(expectation)
+        return summary
+}
+
+// newTierStats создает пустую статистику категорий лотереи с вероятностями по правилам игры
+func newTierStats(lottery domain.Lottery) []domain.WalletTierStats {
+        tiers := make([]domain.WalletTierStats, len(lottery.GameRules.Tiers))
+        for i, tier := range lottery.GameRules.Tiers {
+                tiers[i].Category = tier.Name
+                if i < len(lottery.PrizeStructure) && lottery.PrizeStructure[i].Odds != nil {
+                        probability := lottery.PrizeStructure[i].Odds.Float64()
+                        tiers[i].ExpectedHitRate = &probability
+                }
+        }
+        return tiers
+}
+
+// addTierHits добавляет выигрыши комбинаций билета к статистике категорий
+func addTierHits(tiers []domain.WalletTierStats, ticket domain.WalletTicket) {
+        for _, result := range ticket.Results {
+                for _, check := range result.Combinations {
+                        if !check.Won {
+                                continue
+                        }
+                        for i := range tiers {
+                                if tiers[i].Category != check.Category {
+                                        continue
+                                }
+                                tiers[i].Hits++
+                                if check.Prize != nil {
+                                        tiers[i].Won += *check.Prize
+                                }
+                                break
+                        }
+                }
+        }
+}
+
+// addTicketTotals добавляет билет к итогам
+func addTicketTotals(totals *domain.WalletTotals, ticket domain.WalletTicket) {
+        totals.Tickets++
+        if ticket.Status == domain.TicketStatusPending {
+                totals.Pending++
+        }
+        totals.Spent += ticket.AmountPaid
+        totals.Won += ticket.Prize
+}
+
+// finishTotals считает результат и ROI по накопленным суммам
+func finishTotals(totals *domain.WalletTotals) {
+        totals.Net = totals.Won - totals.Spent
+        if totals.Spent > 0 {
+                roi := totals.Net / totals.Spent
+                totals.ROI = &roi
+        }
+}
+
+// finishExpectation считает фактический и ожидаемый возврат (nil - нет затрат на проверенные тиражи)
+func finishExpectation(expectation domain.WalletExpectation) *domain.WalletExpectation {
+        if expectation.Stake <= 0 {
+                return nil
+        }
+        expectation.Return = expectation.Won / expectation.Stake
+        expectation.ExpectedReturn = expectation.ExpectedWon / expectation.Stake
+        return &expectation
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+        "math"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// TestSummarizeWallet проверяет итоги, частоту категорий и сравнение с ожидаемым выигрышем
+func TestSummarizeWallet(t *testing.T) {
+        lottery := domain.Lottery{ID: "6x45", TicketPrice: 100, CurrentJackpot: 300000000}
+        if !repository.ApplyGameRules(&lottery) || lottery.ExpectedValue == nil {
+                t.Fatal("Для 6x45 должны быть известны правила и EV")
+        }
+
+        prize := 100.0
+        won := domain.TicketCheckResult{DrawNumber: 10, Combinations: []domain.CombinationCheck{
+                {Won: true, Category: "3 из 6", Prize: &prize},
+        }, TotalPrize: prize}
+        lost := domain.TicketCheckResult{DrawNumber: 10, Combinations: []domain.CombinationCheck{{}, {}}}
+        tickets := []domain.WalletTicket{
+                {
+                        LotteryID: "6x45", DrawNumbers: []int{10, 11}, Combinations: make([]domain.TicketCombination, 1), AmountPaid: 200,
+                        PurchasedAt: time.Date(2026, 10, 10, 12, 0, 0, 0, time.UTC), Status: domain.TicketStatusWon, Prize: prize,
+                        Results: []domain.TicketCheckResult{won},
+                },
+                {
+                        // 1 ноября по МСК
+                        LotteryID: "6x45", DrawNumbers: []int{10}, Combinations: make([]domain.TicketCombination, 2), AmountPaid: 200,
+                        PurchasedAt: time.Date(2026, 10, 31, 22, 0, 0, 0, time.UTC), Status: domain.TicketStatusLost,
+                        Results: []domain.TicketCheckResult{lost},
+                },
+                {
+                        LotteryID: "unknown", DrawNumbers: []int{5}, AmountPaid: 50,
+                        PurchasedAt: time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC), Status: domain.TicketStatusPending,
+                },
+        }
+
+        summary := SummarizeWallet(tickets, map[string]domain.Lottery{"6x45": lottery})
+
+        totals := summary.Totals
+        if totals.Tickets != 3 || totals.Pending != 1 || totals.Spent != 450 || totals.Won != 100 || totals.Net != -350 {
+                t.Errorf("Некорректные итоги: %+v", totals)
+        }
+        if totals.ROI == nil || math.Abs(*totals.ROI+350.0/450) > 1e-9 {
+                t.Errorf("ROI: ожидается %.4f, получено %v", -350.0/450, totals.ROI)
+        }
+
+        if len(summary.ByLottery) != 2 || summary.ByLottery[0].LotteryID != "6x45" || summary.ByLottery[1].LotteryID != "unknown" {
+                t.Fatalf("Лотереи должны идти по убыванию затрат, получено %+v", summary.ByLottery)
+        }
+        known := summary.ByLottery[0]
+        if known.Plays != 3 || len(known.Tiers) != len(lottery.GameRules.Tiers) {
+                t.Fatalf("6x45: ожидается 3 проверенные комбинации и все категории, получено %+v", known)
+        }
+        for _, tier := range known.Tiers {
+                if tier.ExpectedHitRate == nil {
+                        t.Errorf("Категория %s: нет вероятности по правилам", tier.Category)
+                }
+                if tier.Category != "3 из 6" {
+                        if tier.Hits != 0 {
+                                t.Errorf("Категория %s: лишние выигрыши %+v", tier.Category, tier)
+                        }
+                        continue
+                }
+                if tier.Hits != 1 || math.Abs(tier.HitRate-1.0/3) > 1e-9 || tier.Won != 100 || math.Abs(tier.ROI-100.0/300) > 1e-9 {
+                        t.Errorf("Категория 3 из 6: ожидается 1 выигрыш из 3 и возврат 100 из 300, получено %+v", tier)
+                }
+        }
+
+        // Затраты на проверенные тиражи: половина первого билета (1 из 2 тиражей) и второй билет целиком
+        expectation := known.Expectation
+        if expectation == nil || expectation.Stake != 300 || expectation.Won != 100 {
+                t.Fatalf("6x45: ожидается сравнение с EV на 300 ₽ затрат, получено %+v", expectation)
+        }
+        if math.Abs(expectation.ExpectedWon-3*lottery.ExpectedValue.Gross) > 1e-9 || math.Abs(expectation.ExpectedReturn-lottery.ExpectedValue.GrossReturn) > 1e-9 {
+                t.Errorf("Ожидаемый выигрыш: %+v, EV билета %+v", expectation, lottery.ExpectedValue)
+        }
+        if summary.Expectation == nil || *summary.Expectation != *expectation {
+                t.Errorf("Общее сравнение должно совпадать с 6x45, получено %+v", summary.Expectation)
+        }
+        if unknown := summary.ByLottery[1]; unknown.Tiers != nil || unknown.Expectation != nil || unknown.Totals.ROI == nil || *unknown.Totals.ROI != -1 {
+                t.Errorf("Лотерея без правил учитывается только в суммах, получено %+v", unknown)
+        }
+
+        if len(summary.ByMonth) != 2 || summary.ByMonth[0].Month != "2026-10" || summary.ByMonth[1].Month != "2026-11" {
+                t.Fatalf("Ожидаются месяцы 2026-10 и 2026-11 (МСК), получено %+v", summary.ByMonth)
+        }
+        if october := summary.ByMonth[0].Totals; october.Tickets != 2 || october.Spent != 250 || october.Net != -150 {
+                t.Errorf("Октябрь: ожидается 2 билета на 250 ₽ с результатом -150, получено %+v", october)
+        }
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "testing"
+        "time"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+        "github.com/stoloto-recommendations/backend/internal/repository"
+)
+
+// newTestWalletService создает WalletService поверх newTestTicketService
+// Последний разыгранный тираж - 100, тираж 10 лотереи 6x45 лежит в архиве
+func newTestWalletService(t *testing.T) *WalletService {
+        t.Helper()
+
+        draws := newTestTicketService(t, repository.Draw{
+                Number: 10, GameName: "6x45", WinningNumbers: []int{3, 7, 15, 22, 31, 40},
+                Winners: repository.WinnerTiers{{Tier: "3", Winners: 1000, Prize: 10000}},
+        })
+        store, err := repository.NewFileWalletStore(t.TempDir())
+        if err != nil {
+                t.Fatalf("Не удалось создать хранилище кошельков: %v", err)
+        }
+        service := NewWalletService(store, draws)
+        service.now = func() time.Time { return time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC) }
+        return service
+}
+
+// addTestTicket добавляет билет 6x45 в кошелек
+func addTestTicket(t *testing.T, service *WalletService, userID string, purchasedAt time.Time, draws []int, combinations ...domain.TicketCombination) *domain.WalletTicket {
+        t.Helper()
+
+        ticket, err := service.AddTicket(context.Background(), userID, domain.WalletTicketRequest{
+                LotteryID:    "6x45",
+                DrawNumbers:  draws,
+                Combinations: combinations,
+                AmountPaid:   100,
+                PurchasedAt:  &purchasedAt,
+        })
+        if err != nil {
+                t.Fatalf("AddTicket: %v", err)
+        }
+        return ticket
+}
+
+// TestWalletAddTicketValidation проверяет отклонение билетов, которые нельзя проверить
+func TestWalletAddTicketValidation(t *testing.T) {
+        service := newTestWalletService(t)
+        ctx := context.Background()
+
+        tests := []struct {
+                name    string
+                request domain.WalletTicketRequest
+                want    error
+        }{
+                {"неизвестная лотерея", domain.WalletTicketRequest{LotteryID: "unknown", DrawNumbers: []int{1}}, ErrLotteryNotFound},
+                {"нет правил игры", domain.WalletTicketRequest{LotteryID: "ruslotto", DrawNumbers: []int{1}}, ErrRulesUnavailable},
+                {"лишнее число", domain.WalletTicketRequest{
+                        LotteryID: "6x45", DrawNumbers: []int{1}, Combinations: []domain.TicketCombination{{{1, 2, 3, 4, 5, 6, 7}}},
+                }, ErrInvalidTicket},
+        }
+        for _, tt := range tests {
+                if _, err := service.AddTicket(ctx, "user_1", tt.request); !errors.Is(err, tt.want) {
+                        t.Errorf("%s: ожидается %v, получено %v", tt.name, tt.want, err)
+                }
+        }
+
+        ticket := addTestTicket(t, service, "user_1", service.now(), []int{12, 10, 12}, domain.TicketCombination{{1, 2, 3, 4, 5, 6}})
+        if ticket.ID == "" || ticket.Status != domain.TicketStatusPending {
+                t.Errorf("Новый билет должен получить ID и статус pending, получено %+v", ticket)
+        }
+        if len(ticket.DrawNumbers) != 2 || ticket.DrawNumbers[0] != 10 || ticket.DrawNumbers[1] != 12 {
+                t.Errorf("Тиражи должны быть отсортированы без повторов, получено %v", ticket.DrawNumbers)
+        }
+}
+
+// TestWalletListTickets проверяет фильтры и постраничный вывод
+func TestWalletListTickets(t *testing.T) {
+        service := newTestWalletService(t)
+        ctx := context.Background()
+
+        base := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+        for day := 0; day < 5; day++ {
+                addTestTicket(t, service, "user_1", base.AddDate(0, 0, day), []int{10}, domain.TicketCombination{{1, 2, 3, 4, 5, 6}})
+        }
+
+        page, err := service.ListTickets(ctx, "user_1", domain.WalletFilter{Offset: 1, Limit: 2})
+        if err != nil {
+                t.Fatalf("ListTickets: %v", err)
+        }
+        if page.Total != 5 || len(page.Tickets) != 2 || !page.Tickets[0].PurchasedAt.Equal(base.AddDate(0, 0, 3)) {
+                t.Errorf("Ожидается вторая и третья покупки с конца из 5, получено %+v", page)
+        }
+
+        page, err = service.ListTickets(ctx, "user_1", domain.WalletFilter{From: base.AddDate(0, 0, 1), To: base.AddDate(0, 0, 3)})
+        if err != nil {
+                t.Fatalf("ListTickets: %v", err)
+        }
+        if page.Total != 2 || page.Limit != defaultWalletPageSize {
+                t.Errorf("Ожидается 2 билета за период и размер страницы по умолчанию, получено %+v", page)
+        }
+
+        page, err = service.ListTickets(ctx, "user_1", domain.WalletFilter{Status: domain.TicketStatusWon, Offset: 10})
+        if err != nil {
+                t.Fatalf("ListTickets: %v", err)
+        }
+        if page.Total != 0 || page.Tickets == nil {
+                t.Errorf("Ожидается пустая (не nil) страница, получено %+v", page)
+        }
+}
+
+// TestWalletCheckPending проверяет обновление статусов билетов по разыгранным тиражам
+func TestWalletCheckPending(t *testing.T) {
+        service := newTestWalletService(t)
+        ctx := context.Background()
+        now := service.now()
+
+        winning := domain.TicketCombination{{3, 7, 15, 1, 2, 4}}
+        losing := domain.TicketCombination{{8, 9, 10, 11, 12, 13}}
+        won := addTestTicket(t, service, "user_1", now, []int{10, 150}, winning)
+        lost := addTestTicket(t, service, "user_1", now, []int{10}, losing)
+        future := addTestTicket(t, service, "user_2", now, []int{150}, winning)
+
+        updated, err := service.CheckPending(ctx)
+        if err != nil {
+                t.Fatalf("CheckPending: %v", err)
+        }
+        if updated != 2 {
+                t.Errorf("Ожидается 2 обновленных билета, получено %d", updated)
+        }
+
+        ticket, _ := service.GetTicket(ctx, "user_1", won.ID)
+        if ticket.Status != domain.TicketStatusWon || ticket.Prize != 100 || len(ticket.Results) != 1 || ticket.CheckedAt == nil {
+                t.Errorf("Выигравший билет: ожидается won с призом 100 и одним результатом, получено %+v", ticket)
+        }
+        ticket, _ = service.GetTicket(ctx, "user_1", lost.ID)
+        if ticket.Status != domain.TicketStatusLost || ticket.Prize != 0 {
+                t.Errorf("Проигравший билет: ожидается lost, получено %+v", ticket)
+        }
+        ticket, _ = service.GetTicket(ctx, "user_2", future.ID)
+        if ticket.Status != domain.TicketStatusPending || len(ticket.Results) != 0 {
+                t.Errorf("Билет на будущий тираж должен остаться pending, получено %+v", ticket)
+        }
+        if updated, err := service.CheckPending(ctx); err != nil || updated != 0 {
+                t.Errorf("Тираж 150 еще не разыгран: ожидается 0 обновлений, получено %d, %v", updated, err)
+        }
+
+        // После тиража 150 выигравший билет получает результат второго тиража (5 из 6 - приз 10000)
+        service.draws.fetcher.(*fakeDrawFetcher).latest = 150
+        updated, err = service.CheckPending(ctx)
+        if err != nil || updated != 2 {
+                t.Errorf("Тираж 150: ожидается 2 обновленных билета, получено %d, %v", updated, err)
+        }
+        ticket, _ = service.GetTicket(ctx, "user_1", won.ID)
+        if ticket.Status != domain.TicketStatusWon || ticket.Prize != 10100 || len(ticket.Results) != 2 {
+                t.Errorf("Выигравший билет: ожидается won с призом 10100 и двумя результатами, получено %+v", ticket)
+        }
+        ticket, _ = service.GetTicket(ctx, "user_2", future.ID)
+        if ticket.Status != domain.TicketStatusWon || len(ticket.Results) != 1 {
+                t.Errorf("Билет на тираж 150: ожидается won, получено %+v", ticket)
+        }
+
+        // Билеты со всеми проверенными тиражами повторно не проверяются
+        if updated, err := service.CheckPending(ctx); err != nil || updated != 0 {
+                t.Errorf("Повторная проверка: ожидается 0 обновлений, получено %d, %v", updated, err)
+        }
+}
+
+// TestWalletSaveResultsKeepsListedTickets проверяет, что добавление результатов не меняет ранее выданные копии билета
+func TestWalletSaveResultsKeepsListedTickets(t *testing.T) {
+        service := newTestWalletService(t)
+        ctx := context.Background()
+
+        ticket := addTestTicket(t, service, "user_1", service.now(), []int{5, 20, 30, 40}, domain.TicketCombination{{1, 2, 3, 4, 5, 6}})
+        results := []domain.TicketCheckResult{{LotteryID: "6x45", DrawNumber: 20}, {LotteryID: "6x45", DrawNumber: 30}, {LotteryID: "6x45", DrawNumber: 40}}
+        if err := service.saveResults(ctx, "user_1", ticket.ID, results, nil); err != nil {
+                t.Fatalf("saveResults: %v", err)
+        }
+
+        listed, err := service.store.List(ctx, "user_1")
+        if err != nil {
+                t.Fatalf("List: %v", err)
+        }
+        // Пропущенный ранее тираж 5 приходит после более поздних, а у Results есть запас емкости
+        if err := service.saveResults(ctx, "user_1", ticket.ID, []domain.TicketCheckResult{{LotteryID: "6x45", DrawNumber: 5}}, nil); err != nil {
+                t.Fatalf("saveResults: %v", err)
+        }
+        if got := listed[0].Results; len(got) != 3 || got[0].DrawNumber != 20 {
+                t.Errorf("Выданная копия билета изменилась: ожидаются результаты тиражей 20, 30 и 40, получено %+v", got)
+        }
+
+        saved, _ := service.GetTicket(ctx, "user_1", ticket.ID)
+        if len(saved.Results) != 4 || saved.Results[0].DrawNumber != 5 || saved.Results[3].DrawNumber != 40 {
+                t.Errorf("Ожидаются результаты тиражей 5, 20, 30 и 40 по порядку, получено %+v", saved.Results)
+        }
+}
+
+// TestWalletCheckPendingMissingDraw проверяет, что разыгранный, но отсутствующий тираж перестает запрашиваться
+func TestWalletCheckPendingMissingDraw(t *testing.T) {
+        service := newTestWalletService(t)
+        ctx := context.Background()
+        fetcher := service.draws.fetcher.(*fakeDrawFetcher)
+        fetcher.missing = map[int]bool{50: true}
+
+        ticket := addTestTicket(t, service, "user_1", service.now(), []int{10, 50}, domain.TicketCombination{{8, 9, 10, 11, 12, 13}})
+        for pass := 1; pass < walletMissingDrawPasses; pass++ {
+                if _, err := service.CheckPending(ctx); err != nil {
+                        t.Fatalf("CheckPending: %v", err)
+                }
+                saved, _ := service.GetTicket(ctx, "user_1", ticket.ID)
+                if saved.Status != domain.TicketStatusPending || len(saved.MissingDraws) != 0 {
+                        t.Errorf("Проход %d: билет должен ждать тираж 50, получено %+v", pass, saved)
+                }
+        }
+
+        updated, err := service.CheckPending(ctx)
+        if err != nil || updated != 1 {
+                t.Fatalf("Ожидается 1 обновленный билет, получено %d, %v", updated, err)
+        }
+        saved, _ := service.GetTicket(ctx, "user_1", ticket.ID)
+        if saved.Status != domain.TicketStatusLost || len(saved.Results) != 1 || len(saved.MissingDraws) != 1 || saved.MissingDraws[0] != 50 {
+                t.Errorf("Ожидается lost с результатом тиража 10 и отсутствующим тиражом 50, получено %+v", saved)
+        }
+
+        requests := fetcher.requests
+        if updated, err := service.CheckPending(ctx); err != nil || updated != 0 {
+                t.Errorf("Повторная проверка: ожидается 0 обновлений, получено %d, %v", updated, err)
+        }
+        if fetcher.requests != requests {
+                t.Errorf("Отсутствующий тираж не должен запрашиваться снова, запросов: %d", fetcher.requests-requests)
+        }
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "fmt"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// ErrInvalidWheel возвращается, если систему нельзя построить из переданного пула или с такой гарантией
+var ErrInvalidWheel = errors.New("некорректная система")
+
+// BuildWheel строит полную или сокращенную систему из пула чисел и считает ее стоимость по цене билета
+func (s *StolotoService) BuildWheel(ctx context.Context, lotteryID string, request domain.WheelRequest) (*domain.Wheel, error) {
+        lottery, err := s.GetLotteryByID(ctx, lotteryID)
+        if err != nil {
+                return nil, err
+        }
+        if lottery.GameRules == nil {
+                return nil, fmt.Errorf("%w: %s", ErrRulesUnavailable, lotteryID)
+        }
+
+        wheel, err := lottery.GameRules.BuildWheel(request.Pool, request.Guarantee)
+        if err != nil {
+                return nil, fmt.Errorf("%w: %v", ErrInvalidWheel, err)
+        }
+        wheel.LotteryID = lotteryID
+        wheel.TicketPrice = lottery.TicketPrice
+        wheel.Cost = float64(wheel.Lines) * lottery.TicketPrice
+        wheel.FullCost = float64(wheel.FullLines) * lottery.TicketPrice
+        return wheel, nil
+}
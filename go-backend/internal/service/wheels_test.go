@@ -0,0 +1,31 @@
+package service
+
+import (
+        "context"
+        "errors"
+        "testing"
+
+        "github.com/stoloto-recommendations/backend/internal/domain"
+)
+
+// TestBuildWheelCost проверяет стоимость системы по цене билета лотереи и ошибки
+func TestBuildWheelCost(t *testing.T) {
+        lotteries := newTestLotteries(t)
+        ctx := context.Background()
+
+        pool := domain.TicketCombination{{1, 5, 9, 13, 17, 21, 25, 29, 33}}
+        wheel, err := lotteries.BuildWheel(ctx, "6x45", domain.WheelRequest{Pool: pool, Guarantee: &domain.WheelGuarantee{Match: 3, If: 3}})
+        if err != nil {
+                t.Fatalf("BuildWheel: %v", err)
+        }
+        if wheel.LotteryID != "6x45" || wheel.TicketPrice != 100 || wheel.Cost != float64(wheel.Lines)*100 || wheel.FullCost != 84*100 {
+                t.Errorf("Ожидается стоимость по 100 ₽ за комбинацию (полная система 84 комбинации), получено %+v", wheel)
+        }
+
+        if _, err := lotteries.BuildWheel(ctx, "6x45", domain.WheelRequest{Pool: domain.TicketCombination{{1, 2, 3}}}); !errors.Is(err, ErrInvalidWheel) {
+                t.Errorf("Мало чисел: ожидается ErrInvalidWheel, получено %v", err)
+        }
+        if _, err := lotteries.BuildWheel(ctx, "ruslotto", domain.WheelRequest{Pool: pool}); !errors.Is(err, ErrRulesUnavailable) {
+                t.Errorf("Лотерея без правил: ожидается ErrRulesUnavailable, получено %v", err)
+        }
+}